@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/uesteibar/ccstats/internal/config"
+	"github.com/uesteibar/ccstats/internal/display"
+	"github.com/uesteibar/ccstats/internal/pricing"
+	"github.com/uesteibar/ccstats/internal/quota"
+	"github.com/uesteibar/ccstats/internal/transcript"
+)
+
+// breakdownJSON is the document printed by `ccstats breakdown --json`.
+type breakdownJSON struct {
+	Window      string             `json:"window"`
+	Utilization float64            `json:"utilization"`
+	ResetAt     time.Time          `json:"reset_at"`
+	Since       time.Time          `json:"since"`
+	Projects    []transcript.Share `json:"projects"`
+}
+
+// runBreakdown estimates which projects consumed a Claude window by
+// combining its live utilization with local transcript token counts.
+func runBreakdown(w io.Writer, cfg config.Config, args []string) error {
+	fs := flag.NewFlagSet("breakdown", flag.ContinueOnError)
+	windowKey := fs.String("window", "5h", "window to break down: 5h, 7d or 7d-sonnet")
+	asJSON := fs.Bool("json", false, "print the breakdown as JSON")
+	dir := fs.String("dir", active.transcriptDir(), "directory holding Claude Code transcripts")
+	displayOpts := addDisplayFlags(fs, cfg.Display)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	opts, err := displayOpts.options()
+	if err != nil {
+		return err
+	}
+
+	table, err := pricing.Load(cfg.Cost.PricingPath())
+	if err != nil {
+		return err
+	}
+
+	snap, errs := fetchSnapshot(context.Background())
+	if errs.Claude != nil {
+		return errs.Claude
+	}
+
+	windows := quota.FilterWindows(quota.Windows(snap.Claude, nil), []string{*windowKey})
+	if len(windows) == 0 {
+		return fmt.Errorf("unknown window %q (want 5h, 7d or 7d-sonnet)", *windowKey)
+	}
+	window := windows[0]
+
+	now := time.Now()
+	since := window.Start(now)
+	sonnetOnly := window.Key == "7d-sonnet"
+
+	agg := transcript.NewAggregator(transcript.GroupByProject, time.Local)
+	err = transcript.Scan(*dir, transcript.Filter{Since: since}, func(e transcript.Entry) error {
+		if sonnetOnly && !strings.Contains(e.Model, "sonnet") {
+			return nil
+		}
+		agg.Add(e)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	shares := transcript.Attribute(agg.Rows(), window.Metric.Utilization, table)
+	if *asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(breakdownJSON{
+			Window:      window.Key,
+			Utilization: window.Metric.Utilization,
+			ResetAt:     window.Metric.ResetAt,
+			Since:       since,
+			Projects:    shares,
+		})
+	}
+
+	display.DisplayBreakdown(w, window, shares, now, opts)
+	return nil
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/uesteibar/ccstats/internal/config"
+	"github.com/uesteibar/ccstats/internal/display"
+	"github.com/uesteibar/ccstats/internal/history"
+	"github.com/uesteibar/ccstats/internal/quota"
+)
+
+// historyLead is how much history before a range is read so the first
+// points can continue the last earlier sample.
+const historyLead = time.Hour
+
+// runChart draws the recorded utilization of one window as a line chart.
+func runChart(w io.Writer, cfg config.Config, args []string) error {
+	fs := flag.NewFlagSet("chart", flag.ContinueOnError)
+	window := fs.String("window", "7d", "window to chart, e.g. 5h, 7d or codex-5h")
+	sinceArg := fs.String("since", "7d", "start of the chart: a date, an RFC 3339 timestamp, or an age such as 14d")
+	untilArg := fs.String("until", "", "end of the chart (default: now)")
+	width := fs.Int("width", 0, "chart width in columns (default: terminal width, or 80 when piped)")
+	height := fs.Int("height", 10, "plot height in rows")
+	ascii := fs.Bool("ascii", cfg.Display.BarStyle == string(display.BarASCII), "draw with plain ASCII instead of braille dots")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	since, err := parseTimeBound(*sinceArg, now, false)
+	if err != nil {
+		return fmt.Errorf("invalid --since: %w", err)
+	}
+	until := now
+	if *untilArg != "" {
+		if until, err = parseTimeBound(*untilArg, now, true); err != nil {
+			return fmt.Errorf("invalid --until: %w", err)
+		}
+	}
+	if !until.After(since) {
+		return fmt.Errorf("--since must be before --until")
+	}
+
+	timeCfg, err := display.ParseTimeConfig(string(display.TimeAbsolute), cfg.Display.Timezone, cfg.Display.Clock)
+	if err != nil {
+		return err
+	}
+	opts := display.ChartOptions{Width: *width, Height: *height, ASCII: *ascii, Time: timeCfg}
+	if opts.Width <= 0 {
+		opts.Width = display.StdoutWidth()
+	}
+
+	samples, err := readHistory(context.Background(), since.Add(-historyLead))
+	if err != nil {
+		return err
+	}
+	series := history.Series(samples, windowProvider(*window), *window)
+	if len(series) == 0 {
+		return fmt.Errorf("no history recorded for the %s window; run `ccstats daemon` to record it", *window)
+	}
+
+	values := history.Resample(series, since, until, display.ChartPoints(opts))
+	resets := history.Resets(series, since, until)
+	display.Chart(w, fmt.Sprintf("%s window utilization", *window), values, since, until, resets, opts)
+	return nil
+}
+
+// usageTrends returns the recent utilization of windows over their own
+// length, for sparklines. Windows without history are left out.
+func usageTrends(ctx context.Context, windows []quota.Window, now time.Time) map[string][]float64 {
+	longest := time.Duration(0)
+	for _, w := range windows {
+		longest = max(longest, w.Duration)
+	}
+	if longest == 0 {
+		return nil
+	}
+
+	samples, err := readHistory(ctx, now.Add(-longest-historyLead))
+	if err != nil || len(samples) == 0 {
+		return nil
+	}
+
+	trends := make(map[string][]float64)
+	for _, w := range windows {
+		series := history.Series(samples, w.Provider, w.Key)
+		if w.Duration == 0 || len(series) == 0 {
+			continue
+		}
+		trends[w.Key] = history.Resample(series, now.Add(-w.Duration), now, display.SparklineWidth)
+	}
+	return trends
+}
+
+// windowProvider returns the provider of a window key.
+func windowProvider(key string) string {
+	if strings.HasPrefix(key, quota.ProviderCodex+"-") {
+		return quota.ProviderCodex
+	}
+	return quota.ProviderClaude
+}
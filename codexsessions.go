@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"io"
+	"time"
+
+	"github.com/uesteibar/ccstats/internal/codex"
+	"github.com/uesteibar/ccstats/internal/config"
+	"github.com/uesteibar/ccstats/internal/display"
+)
+
+// codexSessionsJSON is the document printed by `ccstats codex sessions --json`.
+type codexSessionsJSON struct {
+	Rows  []codex.SessionRow `json:"rows"`
+	Total codex.SessionRow   `json:"total"`
+}
+
+// runCodexSessions aggregates activity from local Codex session logs.
+func runCodexSessions(w io.Writer, cfg config.Config, args []string) error {
+	fs := flag.NewFlagSet("codex sessions", flag.ContinueOnError)
+	by := fs.String("by", "day", "group by day, workspace or model")
+	since := fs.String("since", "", "only count activity from this date, timestamp or age (e.g. 2026-01-01, 7d)")
+	until := fs.String("until", "", "only count activity up to this date, timestamp or age")
+	asJSON := fs.Bool("json", false, "print the rows as JSON")
+	dir := fs.String("dir", active.codexSource().SessionsDir(), "directory holding Codex session logs")
+	timeOpts := addTimeFlags(fs, cfg.Display)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	opts, err := timeOpts.options()
+	if err != nil {
+		return err
+	}
+
+	groupBy, err := codex.ParseSessionGroupBy(*by)
+	if err != nil {
+		return err
+	}
+
+	filter, err := parseFilter(*since, *until, time.Now().In(opts.Time.Zone()))
+	if err != nil {
+		return err
+	}
+
+	agg := codex.NewSessionAggregator(groupBy, opts.Time.Zone())
+	err = codex.ScanSessions(*dir, filter.Since, filter.Until, func(e codex.SessionEvent) error {
+		agg.Add(e)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	rows := agg.Rows()
+	if *asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(codexSessionsJSON{Rows: rows, Total: agg.Total()})
+	}
+
+	display.DisplayCodexSessions(w, rows, agg.Total(), groupBy, opts)
+	return nil
+}
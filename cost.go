@@ -0,0 +1,150 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/uesteibar/ccstats/internal/codex"
+	"github.com/uesteibar/ccstats/internal/config"
+	"github.com/uesteibar/ccstats/internal/display"
+	"github.com/uesteibar/ccstats/internal/pricing"
+	"github.com/uesteibar/ccstats/internal/transcript"
+)
+
+// costJSON is the document printed by `ccstats cost --json`.
+type costJSON struct {
+	Currency    string           `json:"currency"`
+	PricesAsOf  string           `json:"prices_as_of"`
+	Rows        []pricing.Row    `json:"rows"`
+	Total       pricing.Row      `json:"total"`
+	Unpriced    map[string]int64 `json:"unpriced_models,omitempty"`
+	TotalAmount float64          `json:"total_amount"`
+}
+
+// runCost prices locally recorded Claude and Codex token usage at API rates.
+func runCost(w io.Writer, cfg config.Config, args []string) error {
+	fs := flag.NewFlagSet("cost", flag.ContinueOnError)
+	by := fs.String("by", "day", "group by day, project or model")
+	since := fs.String("since", "", "only count usage from this date, timestamp or age (e.g. 2026-01-01, 30d)")
+	until := fs.String("until", "", "only count usage up to this date, timestamp or age")
+	source := fs.String("source", "all", "usage to price: all, claude or codex")
+	asJSON := fs.Bool("json", false, "print the rows as JSON")
+	claudeDir := fs.String("claude-dir", active.transcriptDir(), "directory holding Claude Code transcripts")
+	codexDir := fs.String("codex-dir", active.codexSource().SessionsDir(), "directory holding Codex session logs")
+	timeOpts := addTimeFlags(fs, cfg.Display)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	opts, err := timeOpts.options()
+	if err != nil {
+		return err
+	}
+
+	groupBy, err := pricing.ParseGroupBy(*by)
+	if err != nil {
+		return err
+	}
+
+	filter, err := parseFilter(*since, *until, time.Now().In(opts.Time.Zone()))
+	if err != nil {
+		return err
+	}
+
+	table, err := pricing.Load(cfg.Cost.PricingPath())
+	if err != nil {
+		return err
+	}
+
+	var withClaude, withCodex bool
+	switch *source {
+	case "all":
+		withClaude, withCodex = true, true
+	case "claude":
+		withClaude = true
+	case "codex":
+		withCodex = true
+	default:
+		return fmt.Errorf("unknown source %q (want all, claude or codex)", *source)
+	}
+
+	agg := pricing.NewAggregator(table, groupBy, opts.Time.Zone())
+	found := false
+
+	if withClaude {
+		err := transcript.Scan(*claudeDir, filter, func(e transcript.Entry) error {
+			agg.Add(pricing.Usage{
+				Time:     e.Time,
+				Provider: pricing.ProviderClaude,
+				Project:  e.Project,
+				Model:    e.Model,
+				Tokens:   e.Usage.Tokens(),
+			})
+			return nil
+		})
+		// Only one of the providers needs local data.
+		if err == nil {
+			found = true
+		} else if *source != "all" || !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+
+	if withCodex {
+		err := codex.ScanSessions(*codexDir, filter.Since, filter.Until, func(e codex.SessionEvent) error {
+			if e.Usage.Total() <= 0 {
+				return nil
+			}
+			agg.Add(pricing.Usage{
+				Time:     e.Time,
+				Provider: pricing.ProviderCodex,
+				Project:  e.Workspace,
+				Model:    e.Model,
+				Tokens:   codexTokens(e.Usage),
+			})
+			return nil
+		})
+		if err == nil {
+			found = true
+		} else if *source != "all" || !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("no Claude Code transcripts in %s or Codex sessions in %s", *claudeDir, *codexDir)
+	}
+
+	rows := agg.Rows()
+	if *asJSON {
+		total := pricing.Total(rows)
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(costJSON{
+			Currency:    table.Currency,
+			PricesAsOf:  table.LastUpdated,
+			Rows:        rows,
+			Total:       total,
+			Unpriced:    agg.Unpriced(),
+			TotalAmount: total.Total(),
+		})
+	}
+
+	display.DisplayCost(w, rows, groupBy, table, agg.Unpriced(), opts)
+	return nil
+}
+
+// codexTokens converts Codex usage to priced tokens. Codex counts cached
+// input as part of input, and reasoning as part of output.
+func codexTokens(u codex.TokenUsage) pricing.Tokens {
+	return pricing.Tokens{
+		Input:     u.Input - u.CachedInput,
+		Output:    u.Output,
+		CacheRead: u.CachedInput,
+	}
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/uesteibar/ccstats/internal/config"
+	"github.com/uesteibar/ccstats/internal/daemon"
+	"github.com/uesteibar/ccstats/internal/history"
+	"github.com/uesteibar/ccstats/internal/notify"
+	"github.com/uesteibar/ccstats/internal/quota"
+	"github.com/uesteibar/ccstats/internal/snapshot"
+)
+
+// historyHeartbeat is the longest the daemon goes without recording a
+// window whose utilization has not changed.
+const historyHeartbeat = 15 * time.Minute
+
+// daemonSocketPath returns where the active profile's daemon listens.
+func daemonSocketPath() string {
+	return daemon.SocketPath(cacheDir(), active.Name)
+}
+
+// historyStore returns the utilization history file.
+func historyStore() *history.Store {
+	return &history.Store{Path: history.DefaultPath(cacheDir())}
+}
+
+// runDaemon runs the background poller, or controls a running one.
+func runDaemon(w io.Writer, cfg config.Config, args []string) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case "status":
+			return runDaemonStatus(w)
+		case "stop":
+			return runDaemonStop(w)
+		}
+	}
+
+	fs := flag.NewFlagSet("daemon", flag.ContinueOnError)
+	interval := fs.Duration("interval", cfg.Daemon.Interval.Duration, "how often to poll both providers")
+	withNotify := fs.Bool("notify", cfg.Daemon.Notify, "send threshold and reset notifications after every poll")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *interval <= 0 {
+		return errors.New("--interval must be positive")
+	}
+
+	var notifier *notify.Notifier
+	if *withNotify {
+		var err error
+		if notifier, err = newNotifier(w, cfg.Notify, nil); err != nil {
+			return err
+		}
+	}
+
+	poller := active.codexSource().NewPoller()
+	defer poller.Close()
+
+	fetcher := newFetcher()
+	fetcher.Codex = poller.FetchUsage
+
+	store := historyStore()
+	recorder := &history.Recorder{Store: store, Heartbeat: historyHeartbeat}
+	retention := cfg.Daemon.HistoryRetention.Duration
+	var lastPruned time.Time
+
+	d := &daemon.Daemon{
+		Fetch:      fetcher.Fetch,
+		Interval:   *interval,
+		SocketPath: daemonSocketPath(),
+		PidPath:    daemon.PidPath(cacheDir()),
+		History:    store,
+		OnPoll: func(ctx context.Context, snap *snapshot.Snapshot, errs snapshot.Errors) {
+			// A provider that failed is served from the cache, which is
+			// not history.
+			if err := recorder.Record(history.SamplesFrom(freshWindows(snap, errs), time.Now())); err != nil {
+				fmt.Fprintln(os.Stderr, "Error:", err)
+			}
+			if retention > 0 && time.Since(lastPruned) > 24*time.Hour {
+				lastPruned = time.Now()
+				if err := store.Prune(lastPruned.Add(-retention)); err != nil {
+					fmt.Fprintln(os.Stderr, "Error:", err)
+				}
+			}
+
+			if notifier != nil && (snap.Claude != nil || snap.Codex != nil) {
+				if err := notifySnapshot(ctx, notifier, snap, cfg.Notify.Windows); err != nil {
+					fmt.Fprintln(os.Stderr, "Error:", err)
+				}
+			}
+		},
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Fprintf(os.Stderr, "ccstats daemon listening on %s (pid %d)\n", d.SocketPath, os.Getpid())
+	return d.Run(ctx)
+}
+
+// freshWindows returns the windows of the providers fetched successfully.
+func freshWindows(snap *snapshot.Snapshot, errs snapshot.Errors) []quota.Window {
+	claudeUsage, codexUsage := snap.Claude, snap.Codex
+	if errs.Claude != nil {
+		claudeUsage = nil
+	}
+	if errs.Codex != nil {
+		codexUsage = nil
+	}
+	return quota.Windows(claudeUsage, codexUsage)
+}
+
+// runDaemonStatus reports whether a daemon is running.
+func runDaemonStatus(w io.Writer) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	status, err := daemon.QueryStatus(ctx, daemonSocketPath())
+	if errors.Is(err, daemon.ErrNotRunning) {
+		fmt.Fprintln(w, "ccstats daemon is not running")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	fmt.Fprintf(w, "ccstats daemon is running (pid %d)\n", status.PID)
+	fmt.Fprintf(w, "  Socket:    %s\n", status.Socket)
+	fmt.Fprintf(w, "  Started:   %s ago\n", formatAge(now.Sub(status.Started)))
+	fmt.Fprintf(w, "  Interval:  %s\n", status.Interval)
+	if !status.LastPoll.IsZero() {
+		fmt.Fprintf(w, "  Last poll: %s ago\n", formatAge(now.Sub(status.LastPoll)))
+	}
+	for _, provider := range []string{"claude", "codex"} {
+		if msg := status.Errors[provider]; msg != "" {
+			fmt.Fprintf(w, "  %s error: %s\n", provider, msg)
+		}
+	}
+	return nil
+}
+
+// runDaemonStop asks a running daemon to shut down.
+func runDaemonStop(w io.Writer) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	err := daemon.Stop(ctx, daemonSocketPath(), daemon.PidPath(cacheDir()))
+	if errors.Is(err, daemon.ErrNotRunning) {
+		fmt.Fprintln(w, "ccstats daemon is not running")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(w, "ccstats daemon stopped")
+	return nil
+}
+
+// formatAge renders a duration rounded to the second.
+func formatAge(d time.Duration) string {
+	return d.Round(time.Second).String()
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/uesteibar/ccstats/internal/config"
+	"github.com/uesteibar/ccstats/internal/history"
+	"github.com/uesteibar/ccstats/internal/quota"
+	"github.com/uesteibar/ccstats/internal/snapshot"
+	"github.com/uesteibar/ccstats/internal/wait"
+)
+
+// measureTimeout bounds the usage fetch after the command exits.
+const measureTimeout = 30 * time.Second
+
+// runExec runs a command when a window has room, passing usage to it in
+// the environment, and records the quota it consumed.
+func runExec(cfg config.Config, args []string) error {
+	fs := flag.NewFlagSet("exec", flag.ContinueOnError)
+	window := fs.String("window", "5h", "window to check, e.g. 5h, 7d or codex-5h")
+	maxPct := fs.Float64("max", 100, "refuse to run when utilization is at or above this percentage")
+	waitForRoom := fs.Bool("wait", false, "wait for the window to drop below --max instead of refusing")
+	interval := fs.Duration("interval", 5*time.Minute, "longest time between checks with --wait")
+	timeout := fs.Duration("timeout", 0, "give up waiting after this long (default: no limit)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: ccstats exec [flags] -- command [args...]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	command := fs.Args()
+	if len(command) == 0 {
+		fs.Usage()
+		return fmt.Errorf("no command given")
+	}
+	if *maxPct <= 0 || *maxPct > 100 {
+		return fmt.Errorf("--max must be between 0 and 100")
+	}
+
+	snap, before, err := checkRoom(*window, *maxPct, *waitForRoom, *interval, *timeout)
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	cmd.Env = append(os.Environ(), usageEnv(quota.Windows(snap.Claude, snap.Codex))...)
+
+	start := time.Now()
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	runErr := forwardSignals(cmd)
+	end := time.Now()
+
+	recordRun(history.Run{
+		Start:    start,
+		End:      end,
+		Command:  command,
+		ExitCode: cmd.ProcessState.ExitCode(),
+		Provider: before.Provider,
+		Window:   before.Key,
+		Before:   before.Metric.Utilization,
+	}, before)
+	return commandError(runErr)
+}
+
+// checkRoom returns the current usage and the reading of window once it
+// is below maxPct, waiting for it when waitForRoom is set.
+func checkRoom(window string, maxPct float64, waitForRoom bool, interval, timeout time.Duration) (*snapshot.Snapshot, quota.Window, error) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	snap, errs := fetchSnapshot(ctx)
+	current, err := windowFrom(snap, errs, window)
+	if err != nil || current.Metric.Utilization*100 < maxPct {
+		return snap, current, err
+	}
+	if !waitForRoom {
+		return nil, current, fmt.Errorf("%s window at %.0f%%, at or above --max %.0f%% (use --wait to wait for room)",
+			current.Label, current.Metric.Utilization*100, maxPct)
+	}
+	if interval <= 0 {
+		return nil, current, fmt.Errorf("--interval must be positive")
+	}
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	waiter := &wait.Waiter{
+		Read: func(ctx context.Context) (quota.Window, error) {
+			snap, errs = fetchSnapshot(ctx)
+			return windowFrom(snap, errs, window)
+		},
+		Below:    maxPct / 100,
+		Interval: interval,
+	}
+	current, err = waitForWindow(ctx, waiter, window, timeout)
+	return snap, current, err
+}
+
+// forwardSignals waits for cmd, passing on interrupts and terminations so
+// ccstats outlives the command and can measure what it consumed.
+func forwardSignals(cmd *exec.Cmd) error {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+	for {
+		select {
+		case sig := <-signals:
+			cmd.Process.Signal(sig)
+		case err := <-done:
+			return err
+		}
+	}
+}
+
+// recordRun measures the window again and logs what the run consumed.
+// Failures are reported but do not change the exit status.
+func recordRun(run history.Run, before quota.Window) {
+	ctx, cancel := context.WithTimeout(context.Background(), measureTimeout)
+	defer cancel()
+
+	// Fetch directly: a daemon's snapshot may predate the run's end.
+	snap, errs := newFetcher().Fetch(ctx)
+	fresh := freshWindows(snap, errs)
+	after := quota.FilterWindows(fresh, []string{run.Window})
+	if len(after) == 0 {
+		fmt.Fprintf(os.Stderr, "ccstats: could not measure the %s window after the run\n", run.Window)
+		return
+	}
+
+	run.After = after[0].Metric.Utilization
+	run.Consumed, run.Reset = history.Consumption(before, after[0], time.Now())
+
+	if err := historyStore().Append(history.SamplesFrom(fresh, run.End)...); err != nil {
+		fmt.Fprintln(os.Stderr, "ccstats:", err)
+	}
+	if err := runLog().Append(run); err != nil {
+		fmt.Fprintln(os.Stderr, "ccstats:", err)
+	}
+
+	note := ""
+	if run.Reset {
+		note = " since the reset"
+	}
+	fmt.Fprintf(os.Stderr, "ccstats: %s window %.0f%% -> %.0f%% (+%.1f%%%s)\n",
+		after[0].Label, run.Before*100, run.After*100, run.Consumed*100, note)
+}
+
+// runLog returns the log of runs recorded by exec.
+func runLog() *history.RunLog {
+	return &history.RunLog{Path: history.RunsPath(cacheDir())}
+}
+
+// usageEnv returns the environment variables describing windows, such as
+// CCSTATS_5H_UTIL=42 and CCSTATS_5H_RESET_AT=2025-11-20T17:00:00Z.
+func usageEnv(windows []quota.Window) []string {
+	var env []string
+	for _, w := range windows {
+		prefix := "CCSTATS_" + strings.ToUpper(strings.ReplaceAll(w.Key, "-", "_"))
+		env = append(env, fmt.Sprintf("%s_UTIL=%.0f", prefix, w.Metric.Utilization*100))
+		if !w.Metric.ResetAt.IsZero() {
+			env = append(env, prefix+"_RESET_AT="+w.Metric.ResetAt.UTC().Format(time.RFC3339))
+		}
+	}
+	return env
+}
+
+// exitStatus is the non-zero exit status of exec's command, which exec
+// passes on as its own.
+type exitStatus int
+
+func (s exitStatus) Error() string {
+	return fmt.Sprintf("command exited with status %d", int(s))
+}
+
+// commandError converts a command's exit error to its exit status as a
+// shell reports it.
+func commandError(err error) error {
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return err
+	}
+	if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		return exitStatus(128 + int(status.Signal()))
+	}
+	return exitStatus(exitErr.ExitCode())
+}
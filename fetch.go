@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+
+	"github.com/uesteibar/ccstats/internal/api"
+	"github.com/uesteibar/ccstats/internal/keychain"
+	"github.com/uesteibar/ccstats/internal/snapshot"
+)
+
+// newFetcher returns a fetcher for both providers of the active profile
+// backed by its usage cache.
+func newFetcher() *snapshot.Fetcher {
+	return active.fetcher()
+}
+
+// fetchSnapshot returns the active profile's usage from its daemon when
+// one is running and fetches it directly otherwise.
+func fetchSnapshot(ctx context.Context) (*snapshot.Snapshot, snapshot.Errors) {
+	return active.fetchSnapshot(ctx)
+}
+
+// claudeFetcher returns a Claude fetch func using client and the login
+// source points at. Credentials are read on every call since Claude Code
+// refreshes them.
+func claudeFetcher(client *api.Client, source keychain.Source) func(context.Context) (*api.UsageResponse, error) {
+	return func(ctx context.Context) (*api.UsageResponse, error) {
+		return fetchClaudeUsage(ctx, client, source)
+	}
+}
+
+func fetchClaudeUsage(ctx context.Context, client *api.Client, source keychain.Source) (*api.UsageResponse, error) {
+	creds, err := source.GetCredentials()
+	if err != nil {
+		return nil, err
+	}
+
+	usage, err := client.FetchUsage(ctx, creds.AccessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	// The credentials usually carry the subscription; the profile endpoint
+	// is only asked when they do not.
+	usage.Plan = api.PlanFromSubscription(creds.SubscriptionType, creds.RateLimitTier)
+	usage.PlanSource = "credentials"
+	if usage.Plan == api.PlanUnknown {
+		if plan, err := client.FetchPlan(ctx, creds.AccessToken); err == nil && plan != api.PlanUnknown {
+			usage.Plan, usage.PlanSource = plan, "profile"
+		} else {
+			usage.PlanSource = ""
+		}
+	}
+	return usage, nil
+}
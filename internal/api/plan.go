@@ -0,0 +1,110 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Plan represents a Claude subscription plan.
+type Plan string
+
+const (
+	PlanUnknown    Plan = "unknown"
+	PlanFree       Plan = "free"
+	PlanPro        Plan = "pro"
+	PlanMax        Plan = "max"
+	PlanMax5x      Plan = "max_5x"
+	PlanMax20x     Plan = "max_20x"
+	PlanTeam       Plan = "team"
+	PlanEnterprise Plan = "enterprise"
+)
+
+// String returns the plan name as shown to users, e.g. "Max 5x".
+func (p Plan) String() string {
+	switch p {
+	case PlanFree:
+		return "Free"
+	case PlanPro:
+		return "Pro"
+	case PlanMax:
+		return "Max"
+	case PlanMax5x:
+		return "Max 5x"
+	case PlanMax20x:
+		return "Max 20x"
+	case PlanTeam:
+		return "Team"
+	case PlanEnterprise:
+		return "Enterprise"
+	default:
+		return "Unknown"
+	}
+}
+
+// PlanFromSubscription derives the plan from the subscription type and rate
+// limit tier that Claude Code stores alongside its OAuth credentials, e.g.
+// "max" and "default_claude_max_20x".
+func PlanFromSubscription(subscriptionType, rateLimitTier string) Plan {
+	tier := strings.ToLower(rateLimitTier)
+	switch {
+	case strings.Contains(tier, "max_20x"):
+		return PlanMax20x
+	case strings.Contains(tier, "max_5x"):
+		return PlanMax5x
+	}
+
+	switch strings.TrimPrefix(strings.ToLower(strings.TrimSpace(subscriptionType)), "claude_") {
+	case "free":
+		return PlanFree
+	case "pro":
+		return PlanPro
+	case "max":
+		return PlanMax
+	case "team":
+		return PlanTeam
+	case "enterprise":
+		return PlanEnterprise
+	default:
+		return PlanUnknown
+	}
+}
+
+// profileAPIResponse represents the parts of the OAuth profile response
+// that describe the subscription.
+type profileAPIResponse struct {
+	Account struct {
+		HasClaudeMax bool `json:"has_claude_max"`
+		HasClaudePro bool `json:"has_claude_pro"`
+	} `json:"account"`
+	Organization struct {
+		OrganizationType string `json:"organization_type"`
+		RateLimitTier    string `json:"rate_limit_tier"`
+	} `json:"organization"`
+}
+
+// FetchPlan retrieves the subscription plan from the OAuth profile endpoint.
+func (c *Client) FetchPlan(ctx context.Context, accessToken string) (Plan, error) {
+	body, err := c.get(ctx, profilePath, accessToken)
+	if err != nil {
+		return PlanUnknown, err
+	}
+
+	var profile profileAPIResponse
+	if err := json.Unmarshal(body, &profile); err != nil {
+		return PlanUnknown, fmt.Errorf("failed to parse profile: %w", err)
+	}
+
+	plan := PlanFromSubscription(profile.Organization.OrganizationType, profile.Organization.RateLimitTier)
+	switch {
+	case plan != PlanUnknown:
+		return plan, nil
+	case profile.Account.HasClaudeMax:
+		return PlanMax, nil
+	case profile.Account.HasClaudePro:
+		return PlanPro, nil
+	default:
+		return PlanUnknown, nil
+	}
+}
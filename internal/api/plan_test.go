@@ -0,0 +1,92 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPlanFromSubscription(t *testing.T) {
+	tests := []struct {
+		subscription string
+		tier         string
+		want         Plan
+	}{
+		{"max", "default_claude_max_20x", PlanMax20x},
+		{"max", "default_claude_max_5x", PlanMax5x},
+		{"max", "", PlanMax},
+		{"pro", "default_claude_ai", PlanPro},
+		{"claude_team", "", PlanTeam},
+		{"enterprise", "", PlanEnterprise},
+		{"", "", PlanUnknown},
+		{"something_new", "", PlanUnknown},
+	}
+
+	for _, tt := range tests {
+		if got := PlanFromSubscription(tt.subscription, tt.tier); got != tt.want {
+			t.Errorf("PlanFromSubscription(%q, %q) = %q, want %q", tt.subscription, tt.tier, got, tt.want)
+		}
+	}
+}
+
+func TestPlan_String(t *testing.T) {
+	if got := PlanMax5x.String(); got != "Max 5x" {
+		t.Errorf("expected %q, got %q", "Max 5x", got)
+	}
+	if got := Plan("").String(); got != "Unknown" {
+		t.Errorf("expected %q, got %q", "Unknown", got)
+	}
+}
+
+func TestFetchPlan_Success(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/oauth/profile" {
+			t.Errorf("expected profile path, got %q", r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			t.Errorf("expected Authorization header 'Bearer test-token', got '%s'", r.Header.Get("Authorization"))
+		}
+		w.Write([]byte(`{
+			"account": {"has_claude_max": true, "has_claude_pro": false},
+			"organization": {"organization_type": "claude_max", "rate_limit_tier": "default_claude_max_20x"}
+		}`))
+	}))
+	defer server.Close()
+
+	client := NewClient()
+	client.baseURL = server.URL
+
+	plan, err := client.FetchPlan(context.Background(), "test-token")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if plan != PlanMax20x {
+		t.Errorf("expected plan %q, got %q", PlanMax20x, plan)
+	}
+}
+
+func TestFetchPlan_AccountFlagsFallback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"account": {"has_claude_pro": true}, "organization": {}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient()
+	client.baseURL = server.URL
+
+	plan, err := client.FetchPlan(context.Background(), "test-token")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if plan != PlanPro {
+		t.Errorf("expected plan %q, got %q", PlanPro, plan)
+	}
+}
+
+func TestNewClient_BaseURLFromEnv(t *testing.T) {
+	t.Setenv(BaseURLEnv, "http://127.0.0.1:9999/")
+	if got := NewClient().baseURL; got != "http://127.0.0.1:9999" {
+		t.Errorf("expected base URL from env, got %q", got)
+	}
+}
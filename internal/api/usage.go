@@ -9,6 +9,8 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"os"
+	"strings"
 	"time"
 )
 
@@ -16,11 +18,17 @@ import (
 var ErrSessionExpired = errors.New("Your session has expired. Please run `claude` to re-authenticate.")
 
 const (
-	usageEndpoint  = "https://api.anthropic.com/api/oauth/usage"
+	defaultBaseURL = "https://api.anthropic.com"
+	usagePath      = "/api/oauth/usage"
+	profilePath    = "/api/oauth/profile"
 	anthropicBeta  = "oauth-2025-04-20"
 	defaultTimeout = 30 * time.Second
 )
 
+// BaseURLEnv overrides the API base URL, e.g. to point ccstats at a local
+// stand-in server.
+const BaseURLEnv = "CCSTATS_ANTHROPIC_BASE_URL"
+
 // UsageMetric represents a single usage metric with its utilization and reset time.
 type UsageMetric struct {
 	Utilization float64   `json:"utilization"`
@@ -32,6 +40,10 @@ type UsageResponse struct {
 	FiveHour       UsageMetric `json:"five_hour"`
 	SevenDay       UsageMetric `json:"seven_day"`
 	SevenDaySonnet UsageMetric `json:"seven_day_sonnet"`
+	// Plan is the subscription plan, if known, and PlanSource where it was
+	// detected from ("credentials" or "profile").
+	Plan       Plan   `json:"plan,omitempty"`
+	PlanSource string `json:"plan_source,omitempty"`
 }
 
 // usageAPIResponse represents the raw API response with ISO timestamp strings.
@@ -53,7 +65,8 @@ type Client struct {
 	baseURL    string
 }
 
-// NewClient creates a new API client.
+// NewClient creates a new API client. The base URL can be overridden with
+// $CCSTATS_ANTHROPIC_BASE_URL.
 func NewClient() *Client {
 	// Force IPv4 to avoid IPv6 connectivity issues on some networks
 	transport := &http.Transport{
@@ -71,14 +84,30 @@ func NewClient() *Client {
 			Timeout:   defaultTimeout,
 			Transport: transport,
 		},
-		baseURL: usageEndpoint,
+		baseURL: baseURLFromEnv(),
 	}
 }
 
 // FetchUsage retrieves usage statistics from the Anthropic API.
 // It requires a valid OAuth access token.
-func (c *Client) FetchUsage(accessToken string) (*UsageResponse, error) {
-	req, err := http.NewRequest(http.MethodGet, c.baseURL, nil)
+func (c *Client) FetchUsage(ctx context.Context, accessToken string) (*UsageResponse, error) {
+	body, err := c.get(ctx, usagePath, accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	var apiResp usageAPIResponse
+	if err := json.Unmarshal(body, &apiResp); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return parseUsageResponse(&apiResp)
+}
+
+// get performs an authenticated GET request against the API and returns the
+// response body.
+func (c *Client) get(ctx context.Context, path, accessToken string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -107,13 +136,14 @@ func (c *Client) FetchUsage(accessToken string) (*UsageResponse, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
+	return body, nil
+}
 
-	var apiResp usageAPIResponse
-	if err := json.Unmarshal(body, &apiResp); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
+func baseURLFromEnv() string {
+	if url := os.Getenv(BaseURLEnv); url != "" {
+		return strings.TrimRight(url, "/")
 	}
-
-	return parseUsageResponse(&apiResp)
+	return defaultBaseURL
 }
 
 // parseResetAt parses a reset time string, returning zero time for empty strings.
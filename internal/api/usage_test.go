@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"net/http/httptest"
@@ -37,7 +38,7 @@ func TestFetchUsage_Success(t *testing.T) {
 	client := NewClient()
 	client.baseURL = server.URL
 
-	resp, err := client.FetchUsage("test-token")
+	resp, err := client.FetchUsage(context.Background(), "test-token")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -68,7 +69,7 @@ func TestFetchUsage_Unauthorized(t *testing.T) {
 	client := NewClient()
 	client.baseURL = server.URL
 
-	_, err := client.FetchUsage("invalid-token")
+	_, err := client.FetchUsage(context.Background(), "invalid-token")
 	if err == nil {
 		t.Fatal("expected error, got nil")
 	}
@@ -88,7 +89,7 @@ func TestFetchUsage_ServerError(t *testing.T) {
 	client := NewClient()
 	client.baseURL = server.URL
 
-	_, err := client.FetchUsage("test-token")
+	_, err := client.FetchUsage(context.Background(), "test-token")
 	if err == nil {
 		t.Fatal("expected error, got nil")
 	}
@@ -108,7 +109,7 @@ func TestFetchUsage_InvalidJSON(t *testing.T) {
 	client := NewClient()
 	client.baseURL = server.URL
 
-	_, err := client.FetchUsage("test-token")
+	_, err := client.FetchUsage(context.Background(), "test-token")
 	if err == nil {
 		t.Fatal("expected error, got nil")
 	}
@@ -128,7 +129,7 @@ func TestFetchUsage_InvalidTimestamp(t *testing.T) {
 	client := NewClient()
 	client.baseURL = server.URL
 
-	_, err := client.FetchUsage("test-token")
+	_, err := client.FetchUsage(context.Background(), "test-token")
 	if err == nil {
 		t.Fatal("expected error for invalid timestamp, got nil")
 	}
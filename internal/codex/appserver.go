@@ -7,12 +7,16 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"os"
 	"os/exec"
 	"sync"
 	"time"
 )
 
-var errNotInitialized = errors.New("codex app-server not initialized")
+var (
+	errNotInitialized  = errors.New("codex app-server not initialized")
+	errAppServerExited = errors.New("codex app-server exited")
+)
 
 const (
 	appServerInitTimeout    = 3 * time.Second
@@ -26,6 +30,8 @@ type appServerClient struct {
 	reader *bufio.Reader
 	mu     sync.Mutex
 	ch     chan rpcMessage
+	// done is closed when the app-server's output ends.
+	done chan struct{}
 }
 
 type rpcMessage struct {
@@ -41,8 +47,14 @@ type rpcError struct {
 	Message string `json:"message"`
 }
 
-func newAppServerClient(ctx context.Context) (*appServerClient, error) {
-	cmd := exec.CommandContext(ctx, "codex", "app-server")
+// newAppServerClient starts and initializes an app-server, using home as
+// CODEX_HOME when set. ctx bounds the initialization only; the process runs
+// until Close.
+func newAppServerClient(ctx context.Context, home string) (*appServerClient, error) {
+	cmd := exec.Command("codex", "app-server")
+	if home != "" {
+		cmd.Env = append(os.Environ(), "CODEX_HOME="+home)
+	}
 	stdin, err := cmd.StdinPipe()
 	if err != nil {
 		return nil, fmt.Errorf("codex app-server stdin: %w", err)
@@ -62,6 +74,7 @@ func newAppServerClient(ctx context.Context) (*appServerClient, error) {
 		stdout: stdout,
 		reader: bufio.NewReader(stdout),
 		ch:     make(chan rpcMessage, 16),
+		done:   make(chan struct{}),
 	}
 
 	go client.readLoop()
@@ -75,6 +88,7 @@ func newAppServerClient(ctx context.Context) (*appServerClient, error) {
 }
 
 func (c *appServerClient) readLoop() {
+	defer close(c.done)
 	for {
 		line, err := c.reader.ReadBytes('\n')
 		if err != nil {
@@ -91,6 +105,15 @@ func (c *appServerClient) readLoop() {
 			continue
 		}
 
+		// Notifications arrive unasked; a long-lived client that is not
+		// waiting on a request drops them rather than stall the reader.
+		if len(msg.ID) == 0 {
+			select {
+			case c.ch <- msg:
+			default:
+			}
+			continue
+		}
 		c.ch <- msg
 	}
 }
@@ -153,6 +176,8 @@ func (c *appServerClient) sendRequest(ctx context.Context, id int, method string
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
+		case <-c.done:
+			return errAppServerExited
 		case msg := <-c.ch:
 			if msg.Error != nil {
 				if msg.Error.Message == "Not initialized" {
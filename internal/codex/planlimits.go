@@ -0,0 +1,197 @@
+package codex
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// PlanLimitsVersion is the newest plan-limits file format this build reads.
+const PlanLimitsVersion = 1
+
+// lastUpdatedLayout is the date format of the last_updated field.
+const lastUpdatedLayout = "2006-01-02"
+
+//go:embed planlimits.json
+var embeddedPlanLimits []byte
+
+// PlanLimitsData is a versioned set of Codex plan limits, either built in or
+// read from a user override file.
+type PlanLimitsData struct {
+	Version     int          `json:"version"`
+	LastUpdated string       `json:"last_updated"`
+	Source      string       `json:"source,omitempty"`
+	Plans       []PlanLimits `json:"plans"`
+
+	// Path is the file the data was read from. It is empty for the
+	// built-in data.
+	Path string `json:"-"`
+}
+
+// Updated returns the last_updated date. It is zero if the data was not
+// validated.
+func (d *PlanLimitsData) Updated() time.Time {
+	updated, err := time.Parse(lastUpdatedLayout, d.LastUpdated)
+	if err != nil {
+		return time.Time{}
+	}
+	return updated
+}
+
+// Origin describes where the data came from: "built-in" or the file path.
+func (d *PlanLimitsData) Origin() string {
+	if d.Path == "" {
+		return "built-in"
+	}
+	return d.Path
+}
+
+// Stale reports whether the data is older than maxAge at now. A maxAge of
+// zero or less never reports stale data.
+func (d *PlanLimitsData) Stale(now time.Time, maxAge time.Duration) bool {
+	if maxAge <= 0 {
+		return false
+	}
+	return now.Sub(d.Updated()) > maxAge
+}
+
+// ParsePlanLimits decodes and validates plan-limits data.
+func ParsePlanLimits(data []byte) (*PlanLimitsData, error) {
+	var parsed PlanLimitsData
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return nil, err
+	}
+	if err := parsed.validate(); err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
+
+// LoadPlanLimits reads plan-limits data from path, returning the built-in
+// data if path is empty or does not exist.
+func LoadPlanLimits(path string) (*PlanLimitsData, error) {
+	if path == "" {
+		return EmbeddedPlanLimits(), nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return EmbeddedPlanLimits(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plan limits: %w", err)
+	}
+
+	parsed, err := ParsePlanLimits(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid plan limits %s: %w", path, err)
+	}
+	parsed.Path = path
+	return parsed, nil
+}
+
+// EmbeddedPlanLimits returns the plan limits compiled into this build.
+func EmbeddedPlanLimits() *PlanLimitsData {
+	parsed, err := ParsePlanLimits(embeddedPlanLimits)
+	if err != nil {
+		panic(fmt.Sprintf("codex: invalid embedded plan limits: %v", err))
+	}
+	return parsed
+}
+
+var activePlanLimits = EmbeddedPlanLimits()
+
+// SetPlanLimits replaces the plan limits returned by PlanLimitsFor and
+// AllPlanLimits. It is meant to be called once at startup.
+func SetPlanLimits(data *PlanLimitsData) {
+	activePlanLimits = data
+}
+
+// CurrentPlanLimits returns the plan limits in use.
+func CurrentPlanLimits() *PlanLimitsData {
+	return activePlanLimits
+}
+
+// PlanLimitsFor returns Codex usage limits based on the plan.
+func PlanLimitsFor(plan Plan) (PlanLimits, bool) {
+	for _, limits := range activePlanLimits.Plans {
+		if limits.Plan == plan {
+			return limits, true
+		}
+	}
+	return PlanLimits{Plan: plan}, false
+}
+
+// AllPlanLimits returns the known plan limits in display order.
+func AllPlanLimits() []PlanLimits {
+	return activePlanLimits.Plans
+}
+
+func (d *PlanLimitsData) validate() error {
+	if d.Version < 1 {
+		return errors.New("missing version")
+	}
+	if d.Version > PlanLimitsVersion {
+		return fmt.Errorf("unsupported version %d (this build reads up to %d)", d.Version, PlanLimitsVersion)
+	}
+	if _, err := time.Parse(lastUpdatedLayout, d.LastUpdated); err != nil {
+		return fmt.Errorf("last_updated must be a date like 2025-11-20, got %q", d.LastUpdated)
+	}
+	if len(d.Plans) == 0 {
+		return errors.New("no plans")
+	}
+
+	seen := make(map[Plan]bool, len(d.Plans))
+	for _, limits := range d.Plans {
+		if normalizePlan(string(limits.Plan)) != limits.Plan || limits.Plan == PlanUnknown {
+			return fmt.Errorf("unknown plan %q", string(limits.Plan))
+		}
+		if seen[limits.Plan] {
+			return fmt.Errorf("duplicate plan %q", string(limits.Plan))
+		}
+		seen[limits.Plan] = true
+
+		ranges := []struct {
+			name string
+			r    LimitRange
+		}{
+			{"local_messages_5h", limits.LocalMessages5h},
+			{"cloud_tasks_5h", limits.CloudTasks5h},
+			{"code_reviews_week", limits.CodeReviewsWeek},
+		}
+		for _, rng := range ranges {
+			if err := rng.r.validate(); err != nil {
+				return fmt.Errorf("plan %q %s: %w", string(limits.Plan), rng.name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (r LimitRange) validate() error {
+	flags := 0
+	for _, set := range []bool{r.Unlimited, r.UsageBased, r.NotAvailable} {
+		if set {
+			flags++
+		}
+	}
+	if flags > 1 {
+		return errors.New("only one of unlimited, usage_based and not_available may be set")
+	}
+	if flags == 1 && (r.Min != 0 || r.Max != 0) {
+		return errors.New("min and max cannot be combined with unlimited, usage_based or not_available")
+	}
+	if r.Min < 0 || r.Max < 0 {
+		return errors.New("min and max must not be negative")
+	}
+	if (r.Min == 0) != (r.Max == 0) {
+		return errors.New("min and max must be set together")
+	}
+	if r.Min > r.Max {
+		return fmt.Errorf("min %d is greater than max %d", r.Min, r.Max)
+	}
+	return nil
+}
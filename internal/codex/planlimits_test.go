@@ -0,0 +1,126 @@
+package codex
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEmbeddedPlanLimits(t *testing.T) {
+	data := EmbeddedPlanLimits()
+
+	if data.Version != PlanLimitsVersion {
+		t.Errorf("expected version %d, got %d", PlanLimitsVersion, data.Version)
+	}
+	if data.Updated().IsZero() {
+		t.Errorf("expected a last_updated date, got %q", data.LastUpdated)
+	}
+	if data.Origin() != "built-in" {
+		t.Errorf("expected built-in origin, got %q", data.Origin())
+	}
+
+	for _, plan := range []Plan{PlanFree, PlanGo, PlanPlus, PlanPro, PlanTeam, PlanBusiness, PlanEnterprise, PlanEdu, PlanAPIKey} {
+		if _, ok := PlanLimitsFor(plan); !ok {
+			t.Errorf("expected limits for %q", plan)
+		}
+	}
+}
+
+func TestParsePlanLimits_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"malformed", `{"version":`, "unexpected end"},
+		{"missing version", `{"last_updated":"2025-11-20","plans":[{"plan":"plus"}]}`, "missing version"},
+		{"future version", `{"version":99,"last_updated":"2025-11-20","plans":[{"plan":"plus"}]}`, "unsupported version 99"},
+		{"bad date", `{"version":1,"last_updated":"last week","plans":[{"plan":"plus"}]}`, "last_updated"},
+		{"no plans", `{"version":1,"last_updated":"2025-11-20","plans":[]}`, "no plans"},
+		{"unknown plan", `{"version":1,"last_updated":"2025-11-20","plans":[{"plan":"ultra"}]}`, `unknown plan "ultra"`},
+		{"duplicate plan", `{"version":1,"last_updated":"2025-11-20","plans":[{"plan":"plus"},{"plan":"plus"}]}`, `duplicate plan "plus"`},
+		{"min above max", `{"version":1,"last_updated":"2025-11-20","plans":[{"plan":"plus","cloud_tasks_5h":{"min":60,"max":10}}]}`, "cloud_tasks_5h: min 60 is greater than max 10"},
+		{"half range", `{"version":1,"last_updated":"2025-11-20","plans":[{"plan":"plus","local_messages_5h":{"min":5}}]}`, "set together"},
+		{"conflicting flags", `{"version":1,"last_updated":"2025-11-20","plans":[{"plan":"pro","local_messages_5h":{"unlimited":true,"usage_based":true}}]}`, "only one of"},
+		{"flag with numbers", `{"version":1,"last_updated":"2025-11-20","plans":[{"plan":"pro","local_messages_5h":{"unlimited":true,"min":1,"max":2}}]}`, "cannot be combined"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParsePlanLimits([]byte(tt.data))
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %q", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestLoadPlanLimits_Override(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "codex-plan-limits.json")
+	content := `{"version":1,"last_updated":"2026-03-01","plans":[{"plan":"plus","local_messages_5h":{"min":50,"max":250}}]}`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write override: %v", err)
+	}
+
+	data, err := LoadPlanLimits(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if data.Origin() != path {
+		t.Errorf("expected origin %q, got %q", path, data.Origin())
+	}
+
+	previous := CurrentPlanLimits()
+	SetPlanLimits(data)
+	t.Cleanup(func() { SetPlanLimits(previous) })
+
+	limits, ok := PlanLimitsFor(PlanPlus)
+	if !ok || limits.LocalMessages5h.Max != 250 {
+		t.Errorf("expected override limits for Plus, got %+v", limits)
+	}
+	if _, ok := PlanLimitsFor(PlanPro); ok {
+		t.Error("expected override to replace the built-in plans")
+	}
+}
+
+func TestLoadPlanLimits_MissingFileUsesEmbedded(t *testing.T) {
+	data, err := LoadPlanLimits(filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if data.Origin() != "built-in" {
+		t.Errorf("expected built-in data, got %q", data.Origin())
+	}
+}
+
+func TestLoadPlanLimits_InvalidOverride(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "codex-plan-limits.json")
+	if err := os.WriteFile(path, []byte(`{"version":1,"plans":[]}`), 0o600); err != nil {
+		t.Fatalf("failed to write override: %v", err)
+	}
+
+	_, err := LoadPlanLimits(path)
+	if err == nil || !strings.Contains(err.Error(), path) {
+		t.Fatalf("expected error naming the file, got %v", err)
+	}
+}
+
+func TestPlanLimitsData_Stale(t *testing.T) {
+	data := &PlanLimitsData{LastUpdated: "2026-01-01"}
+	maxAge := 90 * 24 * time.Hour
+
+	if data.Stale(time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC), maxAge) {
+		t.Error("expected data from a month ago to be fresh")
+	}
+	if !data.Stale(time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC), maxAge) {
+		t.Error("expected data from five months ago to be stale")
+	}
+	if data.Stale(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC), 0) {
+		t.Error("expected a zero max age to disable the warning")
+	}
+}
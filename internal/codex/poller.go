@@ -0,0 +1,80 @@
+package codex
+
+import (
+	"context"
+	"os"
+	"sync"
+)
+
+// Poller fetches Codex usage repeatedly through one long-lived app-server
+// instead of starting a new one for every fetch. It is safe for concurrent
+// use.
+type Poller struct {
+	source Source
+
+	mu     sync.Mutex
+	client *appServerClient
+	nextID int
+}
+
+// NewPoller returns a poller. The app-server is started on the first fetch.
+func NewPoller() *Poller {
+	return Source{}.NewPoller()
+}
+
+// NewPoller returns a poller for the login s points at.
+func (s Source) NewPoller() *Poller {
+	return &Poller{source: s}
+}
+
+// FetchUsage reads the plan from local credentials and the rate limits from
+// the app-server, restarting it if it has exited.
+func (p *Poller) FetchUsage(ctx context.Context) (*Usage, error) {
+	usage, err := usageFromAuth(p.source.AuthFilePath(), os.Getenv("OPENAI_API_KEY"))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.readRateLimits(ctx, usage); err != nil {
+		usage.RateSource = "unavailable"
+	}
+	return usage, nil
+}
+
+func (p *Poller) readRateLimits(ctx context.Context, usage *Usage) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.client == nil {
+		initCtx, cancel := context.WithTimeout(ctx, appServerInitTimeout)
+		client, err := newAppServerClient(initCtx, p.source.Home)
+		cancel()
+		if err != nil {
+			return err
+		}
+		// ID 1 was used by initialize.
+		p.client, p.nextID = client, 2
+	}
+
+	id := p.nextID
+	p.nextID++
+	if err := readRateLimits(ctx, p.client, id, usage); err != nil {
+		// Start afresh next time rather than reuse a server in an unknown
+		// state.
+		p.client.Close()
+		p.client = nil
+		return err
+	}
+	return nil
+}
+
+// Close stops the app-server.
+func (p *Poller) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.client != nil {
+		p.client.Close()
+		p.client = nil
+	}
+}
@@ -0,0 +1,49 @@
+package codex
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// fakeAppServer is a codex stand-in whose app-server answers every rate
+// limit request with a used percentage counting the requests it has seen,
+// so a test can tell whether one process served several fetches.
+const fakeAppServer = `#!/bin/sh
+read -r line
+echo '{"id":1,"result":{}}'
+read -r line
+n=0
+while read -r line; do
+	n=$((n + 1))
+	id=$(echo "$line" | sed 's/.*"id":\([0-9]*\).*/\1/')
+	echo "{\"id\":$id,\"result\":{\"rateLimits\":{\"primary\":{\"usedPercent\":$n,\"windowDurationMins\":300,\"resetsAt\":0}}}}"
+done
+`
+
+func TestPoller_ReusesAppServer(t *testing.T) {
+	bin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, "codex"), []byte(fakeAppServer), 0o755); err != nil {
+		t.Fatalf("failed to write fake codex: %v", err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("CODEX_HOME", t.TempDir())
+	t.Setenv("OPENAI_API_KEY", "sk-test")
+
+	poller := NewPoller()
+	defer poller.Close()
+
+	for want := 1; want <= 2; want++ {
+		usage, err := poller.FetchUsage(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if usage.RateSource != "codex app-server" || usage.Primary == nil {
+			t.Fatalf("expected rate limits from the app-server, got %+v", usage)
+		}
+		if got := usage.Primary.Utilization; got != float64(want)/100 {
+			t.Errorf("fetch %d: expected request count %d from the same server, got %v", want, want, got)
+		}
+	}
+}
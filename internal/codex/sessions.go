@@ -0,0 +1,329 @@
+package codex
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxRolloutLineSize bounds a single rollout line. Lines carrying large
+// tool output can be several megabytes.
+const maxRolloutLineSize = 64 << 20
+
+// TokenUsage is a set of Codex token counts. Cached input is part of Input
+// and reasoning output is part of Output.
+type TokenUsage struct {
+	Input           int64 `json:"input_tokens"`
+	CachedInput     int64 `json:"cached_input_tokens"`
+	Output          int64 `json:"output_tokens"`
+	ReasoningOutput int64 `json:"reasoning_output_tokens"`
+}
+
+// Total returns input plus output tokens.
+func (u TokenUsage) Total() int64 {
+	return u.Input + u.Output
+}
+
+// Add returns the sum of u and other.
+func (u TokenUsage) Add(other TokenUsage) TokenUsage {
+	return TokenUsage{
+		Input:           u.Input + other.Input,
+		CachedInput:     u.CachedInput + other.CachedInput,
+		Output:          u.Output + other.Output,
+		ReasoningOutput: u.ReasoningOutput + other.ReasoningOutput,
+	}
+}
+
+func (u TokenUsage) sub(other TokenUsage) TokenUsage {
+	return TokenUsage{
+		Input:           u.Input - other.Input,
+		CachedInput:     u.CachedInput - other.CachedInput,
+		Output:          u.Output - other.Output,
+		ReasoningOutput: u.ReasoningOutput - other.ReasoningOutput,
+	}
+}
+
+// SessionEvent is a user turn or a token count from a Codex session log.
+type SessionEvent struct {
+	Time      time.Time
+	SessionID string
+	// Workspace is the working directory the session ran in.
+	Workspace string
+	Model     string
+	// Turn is set when the event is a user message starting a turn.
+	Turn bool
+	// Usage holds the tokens consumed since the previous token count.
+	Usage TokenUsage
+}
+
+// SessionsDir returns the directory Codex writes session logs to.
+func SessionsDir() string {
+	return Source{}.SessionsDir()
+}
+
+// SessionsDir returns the session log directory of the login s points at.
+func (s Source) SessionsDir() string {
+	home := s.home()
+	if home == "" {
+		return ""
+	}
+	return filepath.Join(home, "sessions")
+}
+
+type rolloutLine struct {
+	Timestamp time.Time       `json:"timestamp"`
+	Type      string          `json:"type"`
+	Payload   json.RawMessage `json:"payload"`
+}
+
+type rolloutPayload struct {
+	Type  string `json:"type"`
+	ID    string `json:"id"`
+	Cwd   string `json:"cwd"`
+	Model string `json:"model"`
+	Info  *struct {
+		TotalTokenUsage *TokenUsage `json:"total_token_usage"`
+	} `json:"info"`
+}
+
+// ScanSessions streams the Codex session logs under dir, calling fn for
+// every user turn and token count between since and until (zero times are
+// unbounded). Token counts are reported as the difference to the previous
+// count, so repeated counts are not double counted.
+func ScanSessions(dir string, since, until time.Time, fn func(SessionEvent) error) error {
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == dir {
+				return err
+			}
+			return nil
+		}
+		if d.IsDir() || filepath.Ext(path) != ".jsonl" {
+			return nil
+		}
+
+		// Logs are append-only, so one last written before the range
+		// starts cannot contain matching events.
+		if info, err := d.Info(); err == nil && !since.IsZero() && info.ModTime().Before(since) {
+			return nil
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return nil
+		}
+		defer f.Close()
+
+		id := strings.TrimSuffix(filepath.Base(path), ".jsonl")
+		return scanSession(f, id, since, until, fn)
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("no Codex sessions found in %s: %w", dir, fs.ErrNotExist)
+	}
+	return err
+}
+
+func scanSession(r io.Reader, id string, since, until time.Time, fn func(SessionEvent) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxRolloutLineSize)
+
+	session := SessionEvent{SessionID: id}
+	var total TokenUsage
+
+	for scanner.Scan() {
+		var line rolloutLine
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil || len(line.Payload) == 0 {
+			continue
+		}
+
+		var payload rolloutPayload
+		if err := json.Unmarshal(line.Payload, &payload); err != nil {
+			continue
+		}
+
+		event := session
+		event.Time = line.Timestamp
+
+		switch {
+		case line.Type == "session_meta":
+			if payload.ID != "" {
+				session.SessionID = payload.ID
+			}
+			if payload.Cwd != "" {
+				session.Workspace = payload.Cwd
+			}
+			continue
+		case line.Type == "turn_context":
+			if payload.Cwd != "" {
+				session.Workspace = payload.Cwd
+			}
+			if payload.Model != "" {
+				session.Model = payload.Model
+			}
+			continue
+		case line.Type == "event_msg" && payload.Type == "user_message":
+			event.Turn = true
+		case line.Type == "event_msg" && payload.Type == "token_count":
+			if payload.Info == nil || payload.Info.TotalTokenUsage == nil {
+				continue
+			}
+			current := *payload.Info.TotalTokenUsage
+			event.Usage = current.sub(total)
+			if current.Total() <= total.Total() {
+				continue
+			}
+			total = current
+		default:
+			continue
+		}
+
+		if !since.IsZero() && event.Time.Before(since) {
+			continue
+		}
+		if !until.IsZero() && !event.Time.Before(until) {
+			continue
+		}
+		if err := fn(event); err != nil {
+			return err
+		}
+	}
+
+	if err := scanner.Err(); err != nil && !errors.Is(err, bufio.ErrTooLong) {
+		return err
+	}
+	return nil
+}
+
+// SessionGroupBy selects how session events are aggregated.
+type SessionGroupBy string
+
+const (
+	SessionsByDay       SessionGroupBy = "day"
+	SessionsByWorkspace SessionGroupBy = "workspace"
+	SessionsByModel     SessionGroupBy = "model"
+)
+
+// ParseSessionGroupBy parses a grouping name.
+func ParseSessionGroupBy(s string) (SessionGroupBy, error) {
+	switch SessionGroupBy(strings.ToLower(strings.TrimSpace(s))) {
+	case SessionsByDay:
+		return SessionsByDay, nil
+	case SessionsByWorkspace:
+		return SessionsByWorkspace, nil
+	case SessionsByModel:
+		return SessionsByModel, nil
+	default:
+		return "", fmt.Errorf("unknown grouping %q (want day, workspace or model)", s)
+	}
+}
+
+// SessionRow is the aggregated activity of one group.
+type SessionRow struct {
+	Key      string                `json:"key"`
+	Sessions int                   `json:"sessions"`
+	Turns    int                   `json:"turns"`
+	Usage    TokenUsage            `json:"usage"`
+	Models   map[string]TokenUsage `json:"models"`
+}
+
+// SessionAggregator sums session events into rows.
+type SessionAggregator struct {
+	by       SessionGroupBy
+	location *time.Location
+	rows     map[string]*SessionRow
+	sessions map[string]map[string]bool
+}
+
+// NewSessionAggregator returns an aggregator grouping by by. Days are split
+// at midnight in loc.
+func NewSessionAggregator(by SessionGroupBy, loc *time.Location) *SessionAggregator {
+	if loc == nil {
+		loc = time.Local
+	}
+	return &SessionAggregator{
+		by:       by,
+		location: loc,
+		rows:     make(map[string]*SessionRow),
+		sessions: make(map[string]map[string]bool),
+	}
+}
+
+// Add counts an event.
+func (a *SessionAggregator) Add(e SessionEvent) {
+	var key string
+	switch a.by {
+	case SessionsByWorkspace:
+		key = e.Workspace
+	case SessionsByModel:
+		key = e.Model
+	default:
+		key = e.Time.In(a.location).Format("2006-01-02")
+	}
+
+	row, ok := a.rows[key]
+	if !ok {
+		row = &SessionRow{Key: key, Models: make(map[string]TokenUsage)}
+		a.rows[key] = row
+		a.sessions[key] = make(map[string]bool)
+	}
+
+	if !a.sessions[key][e.SessionID] {
+		a.sessions[key][e.SessionID] = true
+		row.Sessions++
+	}
+	if e.Turn {
+		row.Turns++
+	}
+	if e.Usage.Total() > 0 {
+		row.Usage = row.Usage.Add(e.Usage)
+		row.Models[e.Model] = row.Models[e.Model].Add(e.Usage)
+	}
+}
+
+// Rows returns the aggregated rows. Days are in chronological order; other
+// groupings put the largest total first.
+func (a *SessionAggregator) Rows() []SessionRow {
+	rows := make([]SessionRow, 0, len(a.rows))
+	for _, row := range a.rows {
+		rows = append(rows, *row)
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		if a.by == SessionsByDay {
+			return rows[i].Key < rows[j].Key
+		}
+		if ti, tj := rows[i].Usage.Total(), rows[j].Usage.Total(); ti != tj {
+			return ti > tj
+		}
+		return rows[i].Key < rows[j].Key
+	})
+	return rows
+}
+
+// Total sums every row, counting each session once.
+func (a *SessionAggregator) Total() SessionRow {
+	total := SessionRow{Key: "Total", Models: make(map[string]TokenUsage)}
+	seen := make(map[string]bool)
+	for key, row := range a.rows {
+		for id := range a.sessions[key] {
+			if !seen[id] {
+				seen[id] = true
+				total.Sessions++
+			}
+		}
+		total.Turns += row.Turns
+		total.Usage = total.Usage.Add(row.Usage)
+		for model, usage := range row.Models {
+			total.Models[model] = total.Models[model].Add(usage)
+		}
+	}
+	return total
+}
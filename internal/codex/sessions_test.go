@@ -0,0 +1,145 @@
+package codex
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const rolloutFixture = `{"timestamp":"2026-01-15T10:00:00Z","type":"session_meta","payload":{"id":"sess-1","cwd":"/home/me/api"}}
+{"timestamp":"2026-01-15T10:00:01Z","type":"turn_context","payload":{"cwd":"/home/me/api","model":"gpt-5-codex"}}
+{"timestamp":"2026-01-15T10:00:01Z","type":"event_msg","payload":{"type":"user_message","message":"fix it"}}
+{"timestamp":"2026-01-15T10:00:05Z","type":"event_msg","payload":{"type":"token_count","info":{"total_token_usage":{"input_tokens":1000,"cached_input_tokens":200,"output_tokens":300,"reasoning_output_tokens":100}}}}
+{"timestamp":"2026-01-15T10:00:06Z","type":"event_msg","payload":{"type":"token_count","info":{"total_token_usage":{"input_tokens":1000,"cached_input_tokens":200,"output_tokens":300,"reasoning_output_tokens":100}}}}
+{"timestamp":"2026-01-15T10:00:07Z","type":"event_msg","payload":{"type":"token_count","info":null}}
+{"timestamp":"2026-01-15T10:00:08Z","type":"response_item","payload":{"type":"message","role":"assistant"}}
+{"timestamp":"2026-01-16T09:00:00Z","type":"turn_context","payload":{"cwd":"/home/me/api","model":"gpt-5"}}
+{"timestamp":"2026-01-16T09:00:00Z","type":"event_msg","payload":{"type":"user_message","message":"more"}}
+{"timestamp":"2026-01-16T09:00:09Z","type":"event_msg","payload":{"type":"token_count","info":{"total_token_usage":{"input_tokens":3000,"cached_input_tokens":1200,"output_tokens":500,"reasoning_output_tokens":150}}}}
+`
+
+func writeRollout(t *testing.T, dir, name, content string) {
+	t.Helper()
+	path := filepath.Join(dir, "2026", "01", "15", name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write rollout: %v", err)
+	}
+}
+
+func scanAll(t *testing.T, dir string, since, until time.Time) []SessionEvent {
+	t.Helper()
+	var events []SessionEvent
+	err := ScanSessions(dir, since, until, func(e SessionEvent) error {
+		events = append(events, e)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return events
+}
+
+func TestScanSessions(t *testing.T) {
+	dir := t.TempDir()
+	writeRollout(t, dir, "rollout-2026-01-15T10-00-00-abc.jsonl", rolloutFixture)
+
+	events := scanAll(t, dir, time.Time{}, time.Time{})
+	if len(events) != 4 {
+		t.Fatalf("expected 2 turns and 2 token counts, got %d: %+v", len(events), events)
+	}
+
+	first := events[1]
+	if first.SessionID != "sess-1" || first.Workspace != "/home/me/api" || first.Model != "gpt-5-codex" {
+		t.Errorf("unexpected token event: %+v", first)
+	}
+	if first.Usage.Total() != 1300 {
+		t.Errorf("expected 1300 tokens, got %d", first.Usage.Total())
+	}
+
+	second := events[3]
+	if second.Model != "gpt-5" || second.Usage.Input != 2000 || second.Usage.CachedInput != 1000 {
+		t.Errorf("expected the delta since the previous count, got %+v", second)
+	}
+}
+
+func TestScanSessions_SinceKeepsRunningTotals(t *testing.T) {
+	dir := t.TempDir()
+	writeRollout(t, dir, "rollout.jsonl", rolloutFixture)
+
+	events := scanAll(t, dir, time.Date(2026, 1, 16, 0, 0, 0, 0, time.UTC), time.Time{})
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events on the second day, got %+v", events)
+	}
+	if events[1].Usage.Total() != 2200 {
+		t.Errorf("expected only the second day's tokens, got %d", events[1].Usage.Total())
+	}
+}
+
+func TestScanSessions_IDFromFileName(t *testing.T) {
+	dir := t.TempDir()
+	content := strings.SplitAfter(rolloutFixture, "\n")
+	writeRollout(t, dir, "rollout-xyz.jsonl", strings.Join(content[1:4], ""))
+
+	events := scanAll(t, dir, time.Time{}, time.Time{})
+	if len(events) == 0 || events[0].SessionID != "rollout-xyz" {
+		t.Errorf("expected session ID from file name, got %+v", events)
+	}
+}
+
+func TestScanSessions_MissingDir(t *testing.T) {
+	err := ScanSessions(filepath.Join(t.TempDir(), "missing"), time.Time{}, time.Time{}, func(SessionEvent) error { return nil })
+	if !errors.Is(err, fs.ErrNotExist) || !strings.Contains(err.Error(), "no Codex sessions") {
+		t.Errorf("expected missing sessions error, got %v", err)
+	}
+}
+
+func TestSessionAggregator(t *testing.T) {
+	day1 := time.Date(2026, 1, 15, 10, 0, 0, 0, time.UTC)
+	day2 := time.Date(2026, 1, 16, 10, 0, 0, 0, time.UTC)
+	events := []SessionEvent{
+		{Time: day1, SessionID: "a", Workspace: "/api", Model: "gpt-5-codex", Turn: true},
+		{Time: day1, SessionID: "a", Workspace: "/api", Model: "gpt-5-codex", Usage: TokenUsage{Input: 100, Output: 10}},
+		{Time: day2, SessionID: "a", Workspace: "/api", Model: "gpt-5", Usage: TokenUsage{Input: 50}},
+		{Time: day2, SessionID: "b", Workspace: "/web", Model: "gpt-5", Turn: true},
+		{Time: day2, SessionID: "b", Workspace: "/web", Model: "gpt-5", Usage: TokenUsage{Input: 500}},
+	}
+
+	byDay := NewSessionAggregator(SessionsByDay, time.UTC)
+	byWorkspace := NewSessionAggregator(SessionsByWorkspace, time.UTC)
+	for _, e := range events {
+		byDay.Add(e)
+		byWorkspace.Add(e)
+	}
+
+	days := byDay.Rows()
+	if len(days) != 2 || days[1].Key != "2026-01-16" || days[1].Sessions != 2 || days[1].Turns != 1 {
+		t.Errorf("unexpected day rows: %+v", days)
+	}
+
+	workspaces := byWorkspace.Rows()
+	if workspaces[0].Key != "/web" || workspaces[1].Usage.Total() != 160 {
+		t.Errorf("expected /web (500) before /api (160), got %+v", workspaces)
+	}
+	if len(workspaces[1].Models) != 2 {
+		t.Errorf("expected two models for /api, got %v", workspaces[1].Models)
+	}
+
+	total := byDay.Total()
+	if total.Sessions != 2 || total.Turns != 2 || total.Usage.Total() != 660 {
+		t.Errorf("expected sessions counted once in the total, got %+v", total)
+	}
+}
+
+func TestHome_CodexHomeEnv(t *testing.T) {
+	t.Setenv("CODEX_HOME", "/tmp/codex-work")
+	if got := SessionsDir(); got != "/tmp/codex-work/sessions" {
+		t.Errorf("expected sessions under CODEX_HOME, got %q", got)
+	}
+}
@@ -32,13 +32,39 @@ const (
 	PlanAPIKey     Plan = "api_key"
 )
 
+// String returns the plan name as shown to users, e.g. "API key".
+func (p Plan) String() string {
+	switch p {
+	case PlanPlus:
+		return "Plus"
+	case PlanPro:
+		return "Pro"
+	case PlanGo:
+		return "Go"
+	case PlanTeam:
+		return "Team"
+	case PlanBusiness:
+		return "Business"
+	case PlanEnterprise:
+		return "Enterprise"
+	case PlanEdu:
+		return "Edu"
+	case PlanAPIKey:
+		return "API key"
+	case PlanFree:
+		return "Free"
+	default:
+		return "Unknown"
+	}
+}
+
 // LimitRange represents a min-max usage limit.
 type LimitRange struct {
-	Min          int
-	Max          int
-	Unlimited    bool
-	UsageBased   bool
-	NotAvailable bool
+	Min          int  `json:"min,omitempty"`
+	Max          int  `json:"max,omitempty"`
+	Unlimited    bool `json:"unlimited,omitempty"`
+	UsageBased   bool `json:"usage_based,omitempty"`
+	NotAvailable bool `json:"not_available,omitempty"`
 }
 
 // String formats the limit range for display.
@@ -60,30 +86,49 @@ func (r LimitRange) String() string {
 	}
 }
 
+// Used scales the range by a window's utilization (0.0-1.0), estimating how
+// much of it has been consumed. It reports false for ranges without fixed
+// numbers.
+func (r LimitRange) Used(utilization float64) (LimitRange, bool) {
+	if r.Unlimited || r.UsageBased || r.NotAvailable || r.Min <= 0 || r.Max <= 0 {
+		return LimitRange{}, false
+	}
+	if utilization < 0 {
+		utilization = 0
+	}
+	if utilization > 1 {
+		utilization = 1
+	}
+	return LimitRange{
+		Min: int(float64(r.Min) * utilization),
+		Max: int(float64(r.Max) * utilization),
+	}, true
+}
+
 // PlanLimits describes usage limits for a plan.
 type PlanLimits struct {
-	Plan            Plan
-	LocalMessages5h LimitRange
-	CloudTasks5h    LimitRange
-	CodeReviewsWeek LimitRange
-	Notes           []string
+	Plan            Plan       `json:"plan"`
+	LocalMessages5h LimitRange `json:"local_messages_5h"`
+	CloudTasks5h    LimitRange `json:"cloud_tasks_5h"`
+	CodeReviewsWeek LimitRange `json:"code_reviews_week"`
+	Notes           []string   `json:"notes,omitempty"`
 }
 
 // Usage represents Codex usage info derived from local auth.
 type Usage struct {
-	Plan       Plan
-	PlanSource string
-	AuthMode   string
-	Primary    *UsageWindow
-	Secondary  *UsageWindow
-	RateSource string
+	Plan       Plan         `json:"plan"`
+	PlanSource string       `json:"plan_source"`
+	AuthMode   string       `json:"auth_mode,omitempty"`
+	Primary    *UsageWindow `json:"primary,omitempty"`
+	Secondary  *UsageWindow `json:"secondary,omitempty"`
+	RateSource string       `json:"rate_source,omitempty"`
 }
 
 // UsageWindow represents a Codex rate limit window.
 type UsageWindow struct {
-	WindowDurationMins int64
-	Utilization        float64
-	ResetAt            time.Time
+	WindowDurationMins int64     `json:"window_duration_mins"`
+	Utilization        float64   `json:"utilization"`
+	ResetAt            time.Time `json:"reset_at"`
 }
 
 type authFile struct {
@@ -101,17 +146,51 @@ type authClaims struct {
 	} `json:"https://api.openai.com/auth"`
 }
 
+// Source locates a Codex login. The zero value uses Home.
+type Source struct {
+	// Home is the Codex home directory; empty uses Home.
+	Home string
+}
+
+func (s Source) home() string {
+	if s.Home != "" {
+		return s.Home
+	}
+	return Home()
+}
+
+// AuthFilePath returns the auth file of the login s points at, or "" when
+// no Codex home is known.
+func (s Source) AuthFilePath() string {
+	home := s.home()
+	if home == "" {
+		return ""
+	}
+	return filepath.Join(home, "auth.json")
+}
+
 // FetchUsage reads the Codex auth file and derives plan/limits.
 func FetchUsage() (*Usage, error) {
-	return fetchUsageFromPath(authFilePath(), os.Getenv("OPENAI_API_KEY"))
+	return Source{}.FetchUsage()
+}
+
+// FetchUsage reads the auth file of the login s points at and derives
+// plan/limits.
+func (s Source) FetchUsage() (*Usage, error) {
+	return fetchUsageFromPath(s.AuthFilePath(), s.Home, os.Getenv("OPENAI_API_KEY"))
 }
 
 // HasCredentials checks if Codex credentials are available.
 func HasCredentials() bool {
+	return Source{}.HasCredentials()
+}
+
+// HasCredentials checks if the login s points at has credentials.
+func (s Source) HasCredentials() bool {
 	if strings.TrimSpace(os.Getenv("OPENAI_API_KEY")) != "" {
 		return true
 	}
-	path := authFilePath()
+	path := s.AuthFilePath()
 	if path == "" {
 		return false
 	}
@@ -119,15 +198,49 @@ func HasCredentials() bool {
 	return err == nil
 }
 
-func authFilePath() string {
+// Home returns the Codex home directory. It honors $CODEX_HOME and defaults
+// to ~/.codex.
+func Home() string {
+	if dir := os.Getenv("CODEX_HOME"); dir != "" {
+		return dir
+	}
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return ""
 	}
-	return filepath.Join(home, ".codex", "auth.json")
+	return filepath.Join(home, ".codex")
+}
+
+// DetectPlan reads the Codex plan from local credentials without starting
+// the app-server. The result has no rate-limit windows.
+func DetectPlan() (*Usage, error) {
+	return Source{}.DetectPlan()
+}
+
+// DetectPlan reads the plan of the login s points at.
+func (s Source) DetectPlan() (*Usage, error) {
+	return usageFromAuth(s.AuthFilePath(), os.Getenv("OPENAI_API_KEY"))
+}
+
+// fetchUsageFromPath derives the plan from the auth file at path and reads
+// the rate limits from an app-server using home, when set, as CODEX_HOME.
+func fetchUsageFromPath(path, home string, envAPIKey string) (*Usage, error) {
+	usage, err := usageFromAuth(path, envAPIKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := populateRateLimits(home, usage); err != nil {
+		usage.RateSource = "unavailable"
+		return usage, nil
+	}
+
+	return usage, nil
 }
 
-func fetchUsageFromPath(path string, envAPIKey string) (*Usage, error) {
+// usageFromAuth derives the plan from the auth file at path, falling back
+// to the API key from the environment.
+func usageFromAuth(path string, envAPIKey string) (*Usage, error) {
 	if path == "" {
 		return usageFromAPIKey(envAPIKey)
 	}
@@ -157,18 +270,11 @@ func fetchUsageFromPath(path string, envAPIKey string) (*Usage, error) {
 		plan = planFromTokens(auth.Tokens.IDToken, auth.Tokens.AccessToken)
 	}
 
-	usage := &Usage{
+	return &Usage{
 		Plan:       plan,
 		PlanSource: planSource,
 		AuthMode:   auth.AuthMode,
-	}
-
-	if err := populateRateLimits(usage); err != nil {
-		usage.RateSource = "unavailable"
-		return usage, nil
-	}
-
-	return usage, nil
+	}, nil
 }
 
 func usageFromAPIKey(envAPIKey string) (*Usage, error) {
@@ -176,18 +282,11 @@ func usageFromAPIKey(envAPIKey string) (*Usage, error) {
 		return nil, ErrAuthNotFound
 	}
 
-	usage := &Usage{
+	return &Usage{
 		Plan:       PlanAPIKey,
 		PlanSource: "api key",
 		AuthMode:   "api_key",
-	}
-
-	if err := populateRateLimits(usage); err != nil {
-		usage.RateSource = "unavailable"
-		return usage, nil
-	}
-
-	return usage, nil
+	}, nil
 }
 
 type rateLimitsResponse struct {
@@ -208,25 +307,31 @@ type rateLimitWindow struct {
 
 var rateLimitsFetcher = fetchRateLimitsFromAppServer
 
-func populateRateLimits(usage *Usage) error {
-	return rateLimitsFetcher(usage)
+func populateRateLimits(home string, usage *Usage) error {
+	return rateLimitsFetcher(home, usage)
 }
 
-func fetchRateLimitsFromAppServer(usage *Usage) error {
+func fetchRateLimitsFromAppServer(home string, usage *Usage) error {
 	ctx, cancel := context.WithTimeout(context.Background(), appServerRequestTimeout)
 	defer cancel()
 
-	client, err := newAppServerClient(ctx)
+	client, err := newAppServerClient(ctx, home)
 	if err != nil {
 		return err
 	}
 	defer client.Close()
 
+	return readRateLimits(ctx, client, 2, usage)
+}
+
+// readRateLimits asks a running app-server for the rate limits and fills
+// them into usage.
+func readRateLimits(ctx context.Context, client *appServerClient, id int, usage *Usage) error {
 	var response rateLimitsResponse
 	reqCtx, reqCancel := context.WithTimeout(ctx, appServerRequestTimeout)
 	defer reqCancel()
 
-	if err := client.sendRequest(reqCtx, 2, "account/rateLimits/read", nil, &response); err != nil {
+	if err := client.sendRequest(reqCtx, id, "account/rateLimits/read", nil, &response); err != nil {
 		return err
 	}
 
@@ -322,93 +427,3 @@ func normalizePlan(plan string) Plan {
 		return PlanUnknown
 	}
 }
-
-// PlanLimitsFor returns Codex usage limits based on the plan.
-func PlanLimitsFor(plan Plan) (PlanLimits, bool) {
-	limits, ok := planLimitsByPlan[plan]
-	if !ok {
-		return PlanLimits{Plan: plan}, false
-	}
-	return limits, true
-}
-
-// AllPlanLimits returns the known plan limits in display order.
-func AllPlanLimits() []PlanLimits {
-	return []PlanLimits{
-		planLimitsByPlan[PlanPlus],
-		planLimitsByPlan[PlanPro],
-		planLimitsByPlan[PlanBusiness],
-		planLimitsByPlan[PlanEnterprise],
-		planLimitsByPlan[PlanEdu],
-		planLimitsByPlan[PlanAPIKey],
-	}
-}
-
-var planLimitsByPlan = map[Plan]PlanLimits{
-	PlanPlus: {
-		Plan:            PlanPlus,
-		LocalMessages5h: LimitRange{Min: 45, Max: 225},
-		CloudTasks5h:    LimitRange{Min: 10, Max: 60},
-		CodeReviewsWeek: LimitRange{Min: 10, Max: 25},
-		Notes: []string{
-			"Limits depend on task size, complexity, and model.",
-			"Local and cloud share a five-hour window.",
-			"Additional weekly limits may apply.",
-			"GPT-5.1-Codex-Mini can provide up to 4x more local messages.",
-		},
-	},
-	PlanPro: {
-		Plan:            PlanPro,
-		LocalMessages5h: LimitRange{Min: 300, Max: 1500},
-		CloudTasks5h:    LimitRange{Min: 50, Max: 400},
-		CodeReviewsWeek: LimitRange{Min: 100, Max: 250},
-		Notes: []string{
-			"Limits depend on task size, complexity, and model.",
-			"Local and cloud share a five-hour window.",
-			"Additional weekly limits may apply.",
-			"GPT-5.1-Codex-Mini can provide up to 4x more local messages.",
-		},
-	},
-	PlanBusiness: {
-		Plan:            PlanBusiness,
-		LocalMessages5h: LimitRange{Min: 45, Max: 225},
-		CloudTasks5h:    LimitRange{Min: 10, Max: 60},
-		CodeReviewsWeek: LimitRange{Min: 10, Max: 25},
-		Notes: []string{
-			"Limits depend on task size, complexity, and model.",
-			"Local and cloud share a five-hour window.",
-			"Additional weekly limits may apply.",
-			"GPT-5.1-Codex-Mini can provide up to 4x more local messages.",
-			"Cloud features may require flexible pricing.",
-		},
-	},
-	PlanEnterprise: {
-		Plan:            PlanEnterprise,
-		LocalMessages5h: LimitRange{Unlimited: true},
-		CloudTasks5h:    LimitRange{Unlimited: true},
-		CodeReviewsWeek: LimitRange{Unlimited: true},
-		Notes: []string{
-			"No fixed limits; usage scales with credits.",
-			"Non-flexible plans may follow Plus limits.",
-		},
-	},
-	PlanEdu: {
-		Plan:            PlanEdu,
-		LocalMessages5h: LimitRange{Unlimited: true},
-		CloudTasks5h:    LimitRange{Unlimited: true},
-		CodeReviewsWeek: LimitRange{Unlimited: true},
-		Notes: []string{
-			"No fixed limits; usage scales with credits.",
-			"Non-flexible plans may follow Plus limits.",
-		},
-	},
-	PlanAPIKey: {
-		Plan:            PlanAPIKey,
-		LocalMessages5h: LimitRange{UsageBased: true},
-		CloudTasks5h:    LimitRange{NotAvailable: true},
-		CodeReviewsWeek: LimitRange{NotAvailable: true},
-		Notes: []string{
-			"Usage billed at standard API rates.",
-		},
-	},
-}
@@ -11,7 +11,7 @@ import (
 func stubRateLimits(t *testing.T) {
 	t.Helper()
 	prev := rateLimitsFetcher
-	rateLimitsFetcher = func(*Usage) error { return nil }
+	rateLimitsFetcher = func(string, *Usage) error { return nil }
 	t.Cleanup(func() { rateLimitsFetcher = prev })
 }
 
@@ -41,6 +41,15 @@ func TestPlanFromToken(t *testing.T) {
 	}
 }
 
+func TestPlan_String(t *testing.T) {
+	if got := PlanAPIKey.String(); got != "API key" {
+		t.Errorf("expected %q, got %q", "API key", got)
+	}
+	if got := Plan("").String(); got != "Unknown" {
+		t.Errorf("expected %q, got %q", "Unknown", got)
+	}
+}
+
 func TestFetchUsageFromPath_ChatGPTAuth(t *testing.T) {
 	stubRateLimits(t)
 	payload := map[string]any{
@@ -62,7 +71,7 @@ func TestFetchUsageFromPath_ChatGPTAuth(t *testing.T) {
 		t.Fatalf("failed to write auth.json: %v", err)
 	}
 
-	usage, err := fetchUsageFromPath(authPath, "")
+	usage, err := fetchUsageFromPath(authPath, "", "")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -86,7 +95,7 @@ func TestFetchUsageFromPath_ApiKey(t *testing.T) {
 		t.Fatalf("failed to write auth.json: %v", err)
 	}
 
-	usage, err := fetchUsageFromPath(authPath, "")
+	usage, err := fetchUsageFromPath(authPath, "", "")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -117,7 +126,7 @@ func TestPlanLimitsFor(t *testing.T) {
 
 func TestFetchUsageFromPath_EnvAPIKey(t *testing.T) {
 	stubRateLimits(t)
-	usage, err := fetchUsageFromPath("", "sk-env-test")
+	usage, err := fetchUsageFromPath("", "", "sk-env-test")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
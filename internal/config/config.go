@@ -0,0 +1,363 @@
+// Package config loads optional user settings for ccstats from a JSON file.
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	defaultPromptBudget     = 500 * time.Millisecond
+	defaultPromptCacheTTL   = 60 * time.Second
+	defaultNotifyInterval   = 5 * time.Minute
+	defaultDaemonInterval   = time.Minute
+	defaultHistoryRetention = 30 * 24 * time.Hour
+	defaultPlanLimitsMaxAge = 90 * 24 * time.Hour
+)
+
+// Config holds all user-configurable settings.
+type Config struct {
+	Prompt  PromptConfig  `json:"prompt"`
+	Display DisplayConfig `json:"display"`
+	Codex   CodexConfig   `json:"codex"`
+	Cost    CostConfig    `json:"cost"`
+	Notify  NotifyConfig  `json:"notify"`
+	Daemon  DaemonConfig  `json:"daemon"`
+	Serve   ServeConfig   `json:"serve"`
+	// Profile names the profile used when none is selected with --profile
+	// or $CCSTATS_PROFILE.
+	Profile  string             `json:"profile"`
+	Profiles map[string]Profile `json:"profiles"`
+}
+
+// Profile is a named set of Claude and Codex logins, e.g. to keep personal
+// and work accounts apart. Empty fields use the default locations.
+type Profile struct {
+	// KeychainService is the macOS Keychain service holding the Claude
+	// Code credentials.
+	KeychainService string `json:"keychain_service"`
+	// ConfigDir is the Claude config directory, as $CLAUDE_CONFIG_DIR.
+	ConfigDir string `json:"config_dir"`
+	// CodexHome is the Codex home directory, as $CODEX_HOME.
+	CodexHome string `json:"codex_home"`
+}
+
+// ProfileNames returns the names of the configured profiles, sorted.
+func (c Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LookupProfile returns the profile called name.
+func (c Config) LookupProfile(name string) (Profile, error) {
+	profile, ok := c.Profiles[name]
+	if !ok {
+		if len(c.Profiles) == 0 {
+			return Profile{}, fmt.Errorf("unknown profile %q: no profiles are configured", name)
+		}
+		return Profile{}, fmt.Errorf("unknown profile %q (configured: %s)", name, strings.Join(c.ProfileNames(), ", "))
+	}
+	return profile, nil
+}
+
+// validProfileName reports whether name is safe to use in file names.
+func validProfileName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// ServeConfig holds settings for the HTTP API of `ccstats serve`.
+type ServeConfig struct {
+	// Addr is the address to listen on.
+	Addr string `json:"addr"`
+	// Token, when set, is required as a bearer token on every request.
+	// $CCSTATS_SERVE_TOKEN takes precedence.
+	Token string `json:"token"`
+	// CORSOrigins lists the browser origins allowed to call the API; "*"
+	// allows any.
+	CORSOrigins []string `json:"cors_origins"`
+	// CacheTTL is how long fetched usage is served before refetching.
+	CacheTTL Duration `json:"cache_ttl"`
+}
+
+// DaemonConfig holds settings for `ccstats daemon`.
+type DaemonConfig struct {
+	// Interval is how often the daemon polls both providers.
+	Interval Duration `json:"interval"`
+	// Notify runs the notifier after every poll.
+	Notify bool `json:"notify"`
+	// HistoryRetention is how long utilization history is kept.
+	HistoryRetention Duration `json:"history_retention"`
+}
+
+// NotifyConfig holds settings for threshold and reset notifications.
+type NotifyConfig struct {
+	// Thresholds are the utilization percentages that trigger an alert.
+	Thresholds []int `json:"thresholds"`
+	// Resets also alerts when a window that saw usage resets.
+	Resets bool `json:"resets"`
+	// Sinks lists where alerts are delivered: desktop, command or stdout.
+	Sinks []string `json:"sinks"`
+	// Command is the shell command run by the command sink. It receives
+	// the event as JSON on stdin.
+	Command string `json:"command"`
+	// Windows lists the window keys to watch. An empty list watches every
+	// available window.
+	Windows []string `json:"windows"`
+	// Interval is how often `ccstats notify --watch` checks usage.
+	Interval Duration `json:"interval"`
+	// Webhook configures the webhook sink.
+	Webhook WebhookConfig `json:"webhook"`
+}
+
+// WebhookConfig holds settings for the webhook notification sink.
+type WebhookConfig struct {
+	URL string `json:"url"`
+	// Format is generic, slack or teams.
+	Format string `json:"format"`
+	// Secret signs requests with HMAC-SHA256 when set.
+	Secret string `json:"secret"`
+	// Retries is how many times a failed delivery is retried.
+	Retries int `json:"retries"`
+	// DeadLetterFile collects deliveries that failed every retry. Empty
+	// uses notify-dead-letter.jsonl in the cache directory.
+	DeadLetterFile string `json:"dead_letter_file"`
+}
+
+// CostConfig holds settings for API-equivalent cost estimates.
+type CostConfig struct {
+	// PricingFile overrides the built-in price table. Empty uses
+	// pricing.json in Dir when it exists.
+	PricingFile string `json:"pricing_file"`
+}
+
+// PricingPath returns the price table override file location.
+func (c CostConfig) PricingPath() string {
+	if c.PricingFile != "" {
+		return c.PricingFile
+	}
+	dir := Dir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "pricing.json")
+}
+
+// CodexConfig holds settings for the Codex sections.
+type CodexConfig struct {
+	// PlanLimitsFile overrides the built-in plan limits. Empty uses
+	// codex-plan-limits.json in Dir when it exists.
+	PlanLimitsFile string `json:"plan_limits_file"`
+	// PlanLimitsMaxAge is how old the plan limits may be before a warning
+	// is printed. Zero disables the warning.
+	PlanLimitsMaxAge Duration `json:"plan_limits_max_age"`
+}
+
+// PlanLimitsPath returns the plan-limits override file location.
+func (c CodexConfig) PlanLimitsPath() string {
+	if c.PlanLimitsFile != "" {
+		return c.PlanLimitsFile
+	}
+	dir := Dir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "codex-plan-limits.json")
+}
+
+// DisplayConfig holds settings for the default progress-bar view.
+type DisplayConfig struct {
+	// Color is auto, always or never.
+	Color string `json:"color"`
+	// ResetTime is how reset times are shown: relative, absolute or both.
+	ResetTime string `json:"reset_time"`
+	// Timezone is an IANA name such as "Europe/Berlin". Empty uses $TZ or
+	// the system timezone.
+	Timezone string `json:"timezone"`
+	// Clock is "12h" or "24h". Empty derives it from the locale.
+	Clock string `json:"clock"`
+	// Theme names a built-in or user-defined color theme.
+	Theme string `json:"theme"`
+	// Gradient colors bars along a gradient regardless of the theme setting.
+	Gradient bool `json:"gradient"`
+	// BarStyle is blocks, ascii or fractional.
+	BarStyle string `json:"bar_style"`
+	// Accessible prints plain sentences instead of bars.
+	Accessible bool `json:"accessible"`
+	// Sparklines draws recent history next to the bars when the daemon has
+	// recorded some.
+	Sparklines bool `json:"sparklines"`
+	// Themes holds user-defined color themes by name.
+	Themes map[string]ThemeConfig `json:"themes"`
+}
+
+// ThemeConfig is a user-defined color theme. Colors are "#rrggbb" hex
+// values or ANSI color names such as "green" or "bright-red".
+type ThemeConfig struct {
+	Low      string `json:"low"`
+	Medium   string `json:"medium"`
+	High     string `json:"high"`
+	Gradient bool   `json:"gradient"`
+}
+
+// PromptConfig holds settings for the compact one-line output used in
+// shell prompts and tmux status lines.
+type PromptConfig struct {
+	// Windows lists the window keys to show (e.g. "5h", "7d", "codex-5h").
+	// An empty list shows every available window.
+	Windows []string `json:"windows"`
+	// Style is the default escape syntax: plain, ansi, tmux or zsh.
+	Style string `json:"style"`
+	// Budget is the longest a render may wait for fresh data before
+	// falling back to the cache.
+	Budget Duration `json:"budget"`
+	// CacheTTL is how long cached data is used without refetching.
+	CacheTTL Duration `json:"cache_ttl"`
+}
+
+// Duration is a time.Duration that is written as a string such as "500ms"
+// in the config file.
+type Duration struct {
+	time.Duration
+}
+
+// UnmarshalJSON parses a duration string.
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string like \"500ms\": %w", err)
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	d.Duration = parsed
+	return nil
+}
+
+// MarshalJSON writes the duration as a string.
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// Default returns the settings used when no config file exists.
+func Default() Config {
+	return Config{
+		Prompt: PromptConfig{
+			Style:    "ansi",
+			Budget:   Duration{defaultPromptBudget},
+			CacheTTL: Duration{defaultPromptCacheTTL},
+		},
+		Display: DisplayConfig{
+			Sparklines: true,
+		},
+		Notify: NotifyConfig{
+			Thresholds: []int{75, 90, 100},
+			Resets:     true,
+			Sinks:      []string{"desktop"},
+			Interval:   Duration{defaultNotifyInterval},
+			Webhook: WebhookConfig{
+				Format:  "generic",
+				Retries: 3,
+			},
+		},
+		Daemon: DaemonConfig{
+			Interval:         Duration{defaultDaemonInterval},
+			HistoryRetention: Duration{defaultHistoryRetention},
+		},
+		Serve: ServeConfig{
+			Addr:     "127.0.0.1:8787",
+			CacheTTL: Duration{defaultPromptCacheTTL},
+		},
+		Codex: CodexConfig{
+			PlanLimitsMaxAge: Duration{defaultPlanLimitsMaxAge},
+		},
+	}
+}
+
+// Path returns the config file location. It honors $CCSTATS_CONFIG, then
+// $XDG_CONFIG_HOME, and defaults to ~/.config/ccstats/config.json.
+func Path() string {
+	if path := os.Getenv("CCSTATS_CONFIG"); path != "" {
+		return path
+	}
+	return filepath.Join(Dir(), "config.json")
+}
+
+// Dir returns the directory holding ccstats configuration files.
+func Dir() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "ccstats")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "ccstats")
+}
+
+// Load reads the config file from Path, returning defaults if it does not exist.
+func Load() (Config, error) {
+	return LoadFrom(Path())
+}
+
+// LoadFrom reads the config file at path, returning defaults if it does not exist.
+// Settings missing from the file keep their default values.
+func LoadFrom(path string) (Config, error) {
+	cfg := Default()
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	for name := range cfg.Profiles {
+		if !validProfileName(name) {
+			return cfg, fmt.Errorf("invalid profile name %q in %s: use letters, digits, - and _", name, path)
+		}
+	}
+	thresholds, err := normalizeThresholds(cfg.Notify.Thresholds)
+	if err != nil {
+		return cfg, fmt.Errorf("invalid notify thresholds in %s: %w", path, err)
+	}
+	cfg.Notify.Thresholds = thresholds
+
+	return cfg, nil
+}
+
+// normalizeThresholds checks that alert thresholds are percentages between
+// 1 and 100 and returns them sorted without duplicates.
+func normalizeThresholds(thresholds []int) ([]int, error) {
+	for _, t := range thresholds {
+		if t < 1 || t > 100 {
+			return nil, fmt.Errorf("%d is not a percentage between 1 and 100", t)
+		}
+	}
+	sorted := slices.Clone(thresholds)
+	slices.Sort(sorted)
+	return slices.Compact(sorted), nil
+}
@@ -0,0 +1,197 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestLoadFrom_MissingFile(t *testing.T) {
+	cfg, err := LoadFrom(filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.Prompt.Budget.Duration != defaultPromptBudget {
+		t.Errorf("expected default budget %v, got %v", defaultPromptBudget, cfg.Prompt.Budget.Duration)
+	}
+	if cfg.Prompt.Style != "ansi" {
+		t.Errorf("expected default style ansi, got %q", cfg.Prompt.Style)
+	}
+}
+
+func TestLoadFrom_OverridesDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	content := []byte(`{"prompt": {"windows": ["5h", "codex-5h"], "style": "tmux", "budget": "250ms"}}`)
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	cfg, err := LoadFrom(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.Prompt.Style != "tmux" {
+		t.Errorf("expected style tmux, got %q", cfg.Prompt.Style)
+	}
+	if cfg.Prompt.Budget.Duration != 250*time.Millisecond {
+		t.Errorf("expected budget 250ms, got %v", cfg.Prompt.Budget.Duration)
+	}
+	if cfg.Prompt.CacheTTL.Duration != defaultPromptCacheTTL {
+		t.Errorf("expected default cache TTL to be kept, got %v", cfg.Prompt.CacheTTL.Duration)
+	}
+	if len(cfg.Prompt.Windows) != 2 || cfg.Prompt.Windows[1] != "codex-5h" {
+		t.Errorf("unexpected windows: %v", cfg.Prompt.Windows)
+	}
+}
+
+func TestLoadFrom_InvalidDuration(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"prompt": {"budget": "soon"}}`), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	if _, err := LoadFrom(path); err == nil {
+		t.Fatal("expected error for invalid duration, got nil")
+	}
+}
+
+func TestPath_EnvOverride(t *testing.T) {
+	t.Setenv("CCSTATS_CONFIG", "/tmp/custom.json")
+	if got := Path(); got != "/tmp/custom.json" {
+		t.Errorf("expected env override, got %q", got)
+	}
+}
+
+func TestCodexConfig_PlanLimitsPath(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", "/tmp/xdg")
+	if got := (CodexConfig{}).PlanLimitsPath(); got != "/tmp/xdg/ccstats/codex-plan-limits.json" {
+		t.Errorf("expected default path in config dir, got %q", got)
+	}
+	if got := (CodexConfig{PlanLimitsFile: "/etc/limits.json"}).PlanLimitsPath(); got != "/etc/limits.json" {
+		t.Errorf("expected configured path, got %q", got)
+	}
+}
+
+func TestLoadFrom_PlanLimitsMaxAge(t *testing.T) {
+	cfg, err := LoadFrom(filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Codex.PlanLimitsMaxAge.Duration != defaultPlanLimitsMaxAge {
+		t.Errorf("expected default max age %v, got %v", defaultPlanLimitsMaxAge, cfg.Codex.PlanLimitsMaxAge.Duration)
+	}
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"codex": {"plan_limits_max_age": "0s"}}`), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if cfg, err = LoadFrom(path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Codex.PlanLimitsMaxAge.Duration != 0 {
+		t.Errorf("expected zero to turn the warning off, got %v", cfg.Codex.PlanLimitsMaxAge.Duration)
+	}
+}
+
+func TestLoadFrom_NotifyOverrides(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	content := []byte(`{"notify": {"thresholds": [50], "resets": false, "sinks": ["command"], "command": "cat"}}`)
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	cfg, err := LoadFrom(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(cfg.Notify.Thresholds) != 1 || cfg.Notify.Thresholds[0] != 50 {
+		t.Errorf("expected thresholds [50], got %v", cfg.Notify.Thresholds)
+	}
+	if cfg.Notify.Resets {
+		t.Error("expected resets to be disabled")
+	}
+	if cfg.Notify.Interval.Duration != defaultNotifyInterval {
+		t.Errorf("expected default interval to be kept, got %v", cfg.Notify.Interval.Duration)
+	}
+}
+
+func TestLoadFrom_NotifyThresholds(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []int
+		wantErr bool
+	}{
+		{"sorted and deduped", `{"notify": {"thresholds": [90, 50, 90, 100]}}`, []int{50, 90, 100}, false},
+		{"zero", `{"notify": {"thresholds": [0, 50]}}`, nil, true},
+		{"above 100", `{"notify": {"thresholds": [50, 150]}}`, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.json")
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatalf("failed to write config: %v", err)
+			}
+
+			cfg, err := LoadFrom(path)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !slices.Equal(cfg.Notify.Thresholds, tt.want) {
+				t.Errorf("expected thresholds %v, got %v", tt.want, cfg.Notify.Thresholds)
+			}
+		})
+	}
+}
+
+func TestLoadFrom_Profiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	content := []byte(`{"profile": "work", "profiles": {
+		"work": {"keychain_service": "Claude Code-credentials-work", "codex_home": "/home/me/.codex-work"},
+		"personal": {}
+	}}`)
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	cfg, err := LoadFrom(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if names := cfg.ProfileNames(); len(names) != 2 || names[0] != "personal" || names[1] != "work" {
+		t.Errorf("expected sorted profile names, got %v", names)
+	}
+	work, err := cfg.LookupProfile(cfg.Profile)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if work.KeychainService != "Claude Code-credentials-work" || work.CodexHome != "/home/me/.codex-work" {
+		t.Errorf("unexpected work profile: %+v", work)
+	}
+	if _, err := cfg.LookupProfile("other"); err == nil {
+		t.Error("expected an error for an unknown profile")
+	}
+}
+
+func TestLoadFrom_InvalidProfileName(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"profiles": {"../work": {}}}`), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	if _, err := LoadFrom(path); err == nil {
+		t.Error("expected an error for a profile name that is not a file name")
+	}
+}
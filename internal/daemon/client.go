@@ -0,0 +1,156 @@
+package daemon
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/uesteibar/ccstats/internal/api"
+	"github.com/uesteibar/ccstats/internal/codex"
+	"github.com/uesteibar/ccstats/internal/history"
+	"github.com/uesteibar/ccstats/internal/snapshot"
+)
+
+// ErrNotRunning is returned when no daemon answers on the socket.
+var ErrNotRunning = errors.New("ccstats daemon is not running")
+
+// dialTimeout bounds connecting to the socket. A live daemon accepts
+// immediately, so this only matters for a wedged one.
+const dialTimeout = 200 * time.Millisecond
+
+// Call sends one request to the daemon at socketPath and decodes the result
+// into out.
+func Call(ctx context.Context, socketPath, method string, params, out any) error {
+	dialer := net.Dialer{Timeout: dialTimeout}
+	conn, err := dialer.DialContext(ctx, "unix", socketPath)
+	if err != nil {
+		return ErrNotRunning
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	req := request{Method: method}
+	if params != nil {
+		if req.Params, err = json.Marshal(params); err != nil {
+			return err
+		}
+	}
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return fmt.Errorf("failed to send daemon request: %w", err)
+	}
+
+	line, err := bufio.NewReader(conn).ReadBytes('\n')
+	if err != nil {
+		return fmt.Errorf("failed to read daemon response: %w", err)
+	}
+
+	var resp response
+	if err := json.Unmarshal(line, &resp); err != nil {
+		return fmt.Errorf("invalid daemon response: %w", err)
+	}
+	if resp.Error != "" {
+		return errors.New(resp.Error)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(resp.Result, out)
+}
+
+// Usage returns the daemon's latest snapshot and the errors of its last
+// poll. It fails with ErrNotRunning when no daemon is listening and when
+// the daemon has not completed a poll yet.
+func Usage(ctx context.Context, socketPath string) (*snapshot.Snapshot, snapshot.Errors, error) {
+	var result UsageResult
+	if err := Call(ctx, socketPath, MethodUsage, nil, &result); err != nil {
+		return nil, snapshot.Errors{}, err
+	}
+	if result.Snapshot == nil {
+		return nil, snapshot.Errors{}, ErrNotRunning
+	}
+
+	errs := snapshot.Errors{
+		Claude: decodeError(result.Errors["claude"]),
+		Codex:  decodeError(result.Errors["codex"]),
+	}
+	return result.Snapshot, errs, nil
+}
+
+// History returns the samples the daemon recorded since since.
+func History(ctx context.Context, socketPath string, since time.Time) ([]history.Sample, error) {
+	var samples []history.Sample
+	err := Call(ctx, socketPath, MethodHistory, HistoryParams{Since: since}, &samples)
+	return samples, err
+}
+
+// QueryStatus returns the status of the running daemon.
+func QueryStatus(ctx context.Context, socketPath string) (*Status, error) {
+	var status Status
+	if err := Call(ctx, socketPath, MethodStatus, nil, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// Stop asks the daemon to shut down, falling back to SIGTERM for the
+// process in the pidfile when the socket does not answer. It returns once
+// the daemon has released its pidfile or ctx is done.
+func Stop(ctx context.Context, socketPath, pidPath string) error {
+	err := Call(ctx, socketPath, MethodStop, nil, nil)
+	if err != nil && !errors.Is(err, ErrNotRunning) {
+		return err
+	}
+
+	pid, pidErr := readPid(pidPath)
+	if pidErr != nil || !processAlive(pid) {
+		if err != nil {
+			return ErrNotRunning
+		}
+		return nil
+	}
+	if err != nil {
+		process, err := os.FindProcess(pid)
+		if err != nil {
+			return err
+		}
+		if err := process.Signal(syscall.SIGTERM); err != nil {
+			return err
+		}
+	}
+
+	ticker := time.NewTicker(20 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		if _, err := os.Stat(pidPath); errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("ccstats daemon (pid %d) did not stop: %w", pid, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// decodeError restores the sentinel errors callers compare against.
+func decodeError(message string) error {
+	switch message {
+	case "":
+		return nil
+	case codex.ErrAuthNotFound.Error():
+		return codex.ErrAuthNotFound
+	case api.ErrSessionExpired.Error():
+		return api.ErrSessionExpired
+	default:
+		return errors.New(message)
+	}
+}
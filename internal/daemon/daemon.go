@@ -0,0 +1,329 @@
+// Package daemon runs a long-lived poller for both providers and answers
+// usage queries over a Unix socket, so short-lived commands such as shell
+// prompts read memory instead of calling the APIs.
+package daemon
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/uesteibar/ccstats/internal/history"
+	"github.com/uesteibar/ccstats/internal/snapshot"
+)
+
+// Methods answered over the socket.
+const (
+	MethodUsage   = "usage"
+	MethodHistory = "history"
+	MethodStatus  = "status"
+	MethodStop    = "stop"
+)
+
+// SocketPath returns the socket location of the daemon for a profile ("" for
+// none): $XDG_RUNTIME_DIR/ccstats.sock, or ccstats-<profile>.sock, when set,
+// otherwise daemon.sock in cacheDir.
+func SocketPath(cacheDir, profile string) string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		if profile != "" {
+			return filepath.Join(dir, "ccstats-"+profile+".sock")
+		}
+		return filepath.Join(dir, "ccstats.sock")
+	}
+	return filepath.Join(cacheDir, "daemon.sock")
+}
+
+// PidPath returns the pidfile location in cacheDir.
+func PidPath(cacheDir string) string {
+	return filepath.Join(cacheDir, "daemon.pid")
+}
+
+// request is one line sent by a client.
+type request struct {
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+// response is the line the daemon answers with.
+type response struct {
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// UsageResult answers MethodUsage.
+type UsageResult struct {
+	Snapshot *snapshot.Snapshot `json:"snapshot"`
+	// Errors holds the per-provider errors of the last poll.
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+// HistoryParams are the parameters of MethodHistory.
+type HistoryParams struct {
+	Since time.Time `json:"since"`
+}
+
+// Status answers MethodStatus.
+type Status struct {
+	PID      int               `json:"pid"`
+	Started  time.Time         `json:"started"`
+	LastPoll time.Time         `json:"last_poll"`
+	Interval string            `json:"interval"`
+	Socket   string            `json:"socket"`
+	Errors   map[string]string `json:"errors,omitempty"`
+}
+
+// Daemon polls usage every Interval and serves it over a Unix socket.
+type Daemon struct {
+	// Fetch retrieves fresh usage.
+	Fetch    func(ctx context.Context) (*snapshot.Snapshot, snapshot.Errors)
+	Interval time.Duration
+	// SocketPath and PidPath are where the socket and pidfile are created.
+	SocketPath string
+	PidPath    string
+	// History answers MethodHistory; nil answers with no samples.
+	History *history.Store
+	// OnPoll is called after every poll, e.g. to record history or send
+	// notifications.
+	OnPoll func(ctx context.Context, snap *snapshot.Snapshot, errs snapshot.Errors)
+
+	mu       sync.RWMutex
+	snap     *snapshot.Snapshot
+	errs     snapshot.Errors
+	lastPoll time.Time
+	started  time.Time
+	stop     context.CancelFunc
+}
+
+// Run polls and serves until ctx is done or a client asks the daemon to
+// stop. It refuses to start while another daemon holds the pidfile or
+// socket, and removes both on exit.
+func (d *Daemon) Run(ctx context.Context) error {
+	if err := d.acquirePidfile(); err != nil {
+		return err
+	}
+	defer os.Remove(d.PidPath)
+
+	listener, err := d.listen()
+	if err != nil {
+		return err
+	}
+	defer os.Remove(d.SocketPath)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	d.mu.Lock()
+	d.started = time.Now()
+	d.stop = cancel
+	d.mu.Unlock()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		d.serve(ctx, listener)
+	}()
+
+	go func() {
+		<-ctx.Done()
+		listener.Close()
+	}()
+
+	d.poll(ctx)
+	ticker := time.NewTicker(d.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return nil
+		case <-ticker.C:
+			d.poll(ctx)
+		}
+	}
+}
+
+func (d *Daemon) poll(ctx context.Context) {
+	snap, errs := d.Fetch(ctx)
+
+	d.mu.Lock()
+	d.snap, d.errs, d.lastPoll = snap, errs, time.Now()
+	d.mu.Unlock()
+
+	if d.OnPoll != nil {
+		d.OnPoll(ctx, snap, errs)
+	}
+}
+
+func (d *Daemon) acquirePidfile() error {
+	if pid, err := readPid(d.PidPath); err == nil && pid != os.Getpid() && processAlive(pid) {
+		return fmt.Errorf("ccstats daemon is already running (pid %d)", pid)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(d.PidPath), 0o700); err != nil {
+		return fmt.Errorf("failed to create pidfile dir: %w", err)
+	}
+	if err := os.WriteFile(d.PidPath, []byte(strconv.Itoa(os.Getpid())+"\n"), 0o600); err != nil {
+		return fmt.Errorf("failed to write pidfile: %w", err)
+	}
+	return nil
+}
+
+// listen creates the socket, replacing one left behind by a daemon that
+// did not shut down cleanly.
+func (d *Daemon) listen() (net.Listener, error) {
+	if conn, err := net.DialTimeout("unix", d.SocketPath, time.Second); err == nil {
+		conn.Close()
+		return nil, fmt.Errorf("ccstats daemon is already listening on %s", d.SocketPath)
+	}
+	_ = os.Remove(d.SocketPath)
+
+	if err := os.MkdirAll(filepath.Dir(d.SocketPath), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create socket dir: %w", err)
+	}
+	listener, err := net.Listen("unix", d.SocketPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", d.SocketPath, err)
+	}
+	// Usage and history are private to the user.
+	if err := os.Chmod(d.SocketPath, 0o600); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to secure socket: %w", err)
+	}
+	return listener, nil
+}
+
+func (d *Daemon) serve(ctx context.Context, listener net.Listener) {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+				return
+			}
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d.handle(conn)
+		}()
+	}
+}
+
+// handle answers the requests on one connection, one JSON line each.
+func (d *Daemon) handle(conn net.Conn) {
+	defer conn.Close()
+
+	scanner := bufio.NewScanner(conn)
+	enc := json.NewEncoder(conn)
+	for {
+		conn.SetDeadline(time.Now().Add(30 * time.Second))
+		if !scanner.Scan() {
+			return
+		}
+
+		var req request
+		var resp response
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			resp.Error = "invalid request: " + err.Error()
+		} else if result, err := d.answer(req); err != nil {
+			resp.Error = err.Error()
+		} else if resp.Result, err = json.Marshal(result); err != nil {
+			resp.Error = err.Error()
+		}
+
+		if err := enc.Encode(resp); err != nil {
+			return
+		}
+	}
+}
+
+func (d *Daemon) answer(req request) (any, error) {
+	switch req.Method {
+	case MethodUsage:
+		d.mu.RLock()
+		defer d.mu.RUnlock()
+		return UsageResult{Snapshot: d.snap, Errors: encodeErrors(d.errs)}, nil
+	case MethodHistory:
+		var params HistoryParams
+		if len(req.Params) > 0 {
+			if err := json.Unmarshal(req.Params, &params); err != nil {
+				return nil, fmt.Errorf("invalid history params: %w", err)
+			}
+		}
+		if d.History == nil {
+			return []history.Sample{}, nil
+		}
+		samples, err := d.History.Read(params.Since)
+		if samples == nil {
+			samples = []history.Sample{}
+		}
+		return samples, err
+	case MethodStatus:
+		d.mu.RLock()
+		defer d.mu.RUnlock()
+		return Status{
+			PID:      os.Getpid(),
+			Started:  d.started,
+			LastPoll: d.lastPoll,
+			Interval: d.Interval.String(),
+			Socket:   d.SocketPath,
+			Errors:   encodeErrors(d.errs),
+		}, nil
+	case MethodStop:
+		d.mu.RLock()
+		stop := d.stop
+		d.mu.RUnlock()
+		// Let the answer go out before the listener closes.
+		time.AfterFunc(50*time.Millisecond, stop)
+		return true, nil
+	default:
+		return nil, fmt.Errorf("unknown method %q", req.Method)
+	}
+}
+
+func encodeErrors(errs snapshot.Errors) map[string]string {
+	encoded := make(map[string]string)
+	if errs.Claude != nil {
+		encoded["claude"] = errs.Claude.Error()
+	}
+	if errs.Codex != nil {
+		encoded["codex"] = errs.Codex.Error()
+	}
+	if len(encoded) == 0 {
+		return nil
+	}
+	return encoded
+}
+
+func readPid(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(data)))
+}
+
+// processAlive reports whether a process with pid exists.
+func processAlive(pid int) bool {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	return process.Signal(syscall.Signal(0)) == nil
+}
@@ -0,0 +1,215 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/uesteibar/ccstats/internal/api"
+	"github.com/uesteibar/ccstats/internal/codex"
+	"github.com/uesteibar/ccstats/internal/history"
+	"github.com/uesteibar/ccstats/internal/snapshot"
+)
+
+// startDaemon runs a daemon with a fake fetcher in a temporary directory
+// and waits until it answers.
+func startDaemon(t *testing.T, d *Daemon) (done chan error) {
+	t.Helper()
+
+	dir, err := os.MkdirTemp("", "ccstatsd")
+	if err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	d.SocketPath = filepath.Join(dir, "d.sock")
+	d.PidPath = filepath.Join(dir, "d.pid")
+	if d.Interval == 0 {
+		d.Interval = time.Hour
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done = make(chan error, 1)
+	go func() { done <- d.Run(ctx) }()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if _, _, err := Usage(context.Background(), d.SocketPath); err == nil {
+			return done
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("daemon did not start")
+	return nil
+}
+
+func fakeFetch(calls *atomic.Int32) func(context.Context) (*snapshot.Snapshot, snapshot.Errors) {
+	return func(context.Context) (*snapshot.Snapshot, snapshot.Errors) {
+		n := calls.Add(1)
+		snap := &snapshot.Snapshot{
+			FetchedAt: time.Now(),
+			Claude:    &api.UsageResponse{FiveHour: api.UsageMetric{Utilization: float64(n) / 10}},
+		}
+		return snap, snapshot.Errors{Codex: codex.ErrAuthNotFound}
+	}
+}
+
+func TestDaemon_ServesUsage(t *testing.T) {
+	var calls atomic.Int32
+	d := &Daemon{Fetch: fakeFetch(&calls)}
+	startDaemon(t, d)
+
+	snap, errs, err := Usage(context.Background(), d.SocketPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if snap.Claude == nil || snap.Claude.FiveHour.Utilization != 0.1 {
+		t.Errorf("expected the polled snapshot, got %+v", snap.Claude)
+	}
+	if errs.Codex != codex.ErrAuthNotFound {
+		t.Errorf("expected the sentinel error to survive the socket, got %v", errs.Codex)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("expected queries to be answered from memory, got %d fetches", calls.Load())
+	}
+}
+
+func TestDaemon_PollsEveryInterval(t *testing.T) {
+	var calls atomic.Int32
+	d := &Daemon{Fetch: fakeFetch(&calls), Interval: 20 * time.Millisecond}
+
+	var polled atomic.Int32
+	d.OnPoll = func(context.Context, *snapshot.Snapshot, snapshot.Errors) { polled.Add(1) }
+	startDaemon(t, d)
+
+	time.Sleep(100 * time.Millisecond)
+	if calls.Load() < 3 || polled.Load() != calls.Load() {
+		t.Errorf("expected repeated polls with OnPoll after each, got %d fetches and %d callbacks", calls.Load(), polled.Load())
+	}
+}
+
+func TestDaemon_StatusAndHistory(t *testing.T) {
+	var calls atomic.Int32
+	store := &history.Store{Path: filepath.Join(t.TempDir(), "history.jsonl")}
+	now := time.Now()
+	if err := store.Append(
+		history.Sample{Time: now.Add(-2 * time.Hour), Provider: "claude", Window: "5h", Utilization: 0.1},
+		history.Sample{Time: now, Provider: "claude", Window: "5h", Utilization: 0.2},
+	); err != nil {
+		t.Fatalf("failed to seed history: %v", err)
+	}
+
+	d := &Daemon{Fetch: fakeFetch(&calls), History: store}
+	startDaemon(t, d)
+
+	status, err := QueryStatus(context.Background(), d.SocketPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if status.PID != os.Getpid() || status.LastPoll.IsZero() || status.Errors["codex"] == "" {
+		t.Errorf("unexpected status %+v", status)
+	}
+
+	samples, err := History(context.Background(), d.SocketPath, now.Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(samples) != 1 || samples[0].Utilization != 0.2 {
+		t.Errorf("expected the recent sample only, got %+v", samples)
+	}
+}
+
+func TestDaemon_Stop(t *testing.T) {
+	var calls atomic.Int32
+	d := &Daemon{Fetch: fakeFetch(&calls)}
+	done := startDaemon(t, d)
+
+	if err := Stop(context.Background(), d.SocketPath, d.PidPath); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	select {
+	case err := <-done:
+		done <- err // let the cleanup receive it
+		if err != nil {
+			t.Errorf("expected clean shutdown, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("daemon did not stop")
+	}
+
+	for _, path := range []string{d.SocketPath, d.PidPath} {
+		if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("expected %s to be removed, got %v", filepath.Base(path), err)
+		}
+	}
+	if _, _, err := Usage(context.Background(), d.SocketPath); !errors.Is(err, ErrNotRunning) {
+		t.Errorf("expected ErrNotRunning after stop, got %v", err)
+	}
+}
+
+func TestDaemon_RefusesSecondInstance(t *testing.T) {
+	var calls atomic.Int32
+	first := &Daemon{Fetch: fakeFetch(&calls)}
+	startDaemon(t, first)
+
+	second := &Daemon{Fetch: fakeFetch(&calls), Interval: time.Hour, SocketPath: first.SocketPath, PidPath: first.PidPath + ".2"}
+	if err := second.Run(context.Background()); err == nil {
+		t.Error("expected error for a second daemon on the same socket, got nil")
+	}
+}
+
+func TestDaemon_ReplacesStaleFiles(t *testing.T) {
+	dir, err := os.MkdirTemp("", "ccstatsd")
+	if err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	socket := filepath.Join(dir, "d.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	// Closing without unlinking leaves the socket file behind, as a crash
+	// would.
+	listener.(*net.UnixListener).SetUnlinkOnClose(false)
+	listener.Close()
+
+	// A pidfile naming a process that cannot exist.
+	pidPath := filepath.Join(dir, "d.pid")
+	if err := os.WriteFile(pidPath, []byte(strconv.Itoa(1<<22+12345)), 0o600); err != nil {
+		t.Fatalf("failed to write pidfile: %v", err)
+	}
+
+	var calls atomic.Int32
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	d := &Daemon{Fetch: fakeFetch(&calls), Interval: time.Hour, SocketPath: socket, PidPath: pidPath}
+	if err := d.Run(ctx); err != nil {
+		t.Fatalf("expected stale files to be replaced, got %v", err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("expected the daemon to poll, got %d fetches", calls.Load())
+	}
+}
+
+func TestCall_NotRunning(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "missing.sock")
+	if err := Call(context.Background(), socket, MethodStatus, nil, nil); !errors.Is(err, ErrNotRunning) {
+		t.Errorf("expected ErrNotRunning, got %v", err)
+	}
+	if err := Stop(context.Background(), socket, filepath.Join(t.TempDir(), "missing.pid")); !errors.Is(err, ErrNotRunning) {
+		t.Errorf("expected ErrNotRunning from stop, got %v", err)
+	}
+}
@@ -0,0 +1,123 @@
+package display
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/uesteibar/ccstats/internal/api"
+	"github.com/uesteibar/ccstats/internal/codex"
+	"github.com/uesteibar/ccstats/internal/quota"
+)
+
+// numberWords spells out the small numbers that appear in window labels.
+var numberWords = []string{"zero", "one", "two", "three", "four", "five", "six", "seven", "eight", "nine", "ten"}
+
+func accessibleClaudeLines(usage *api.UsageResponse, now time.Time, opts Options) []string {
+	lines := []string{"Claude Code usage:"}
+	if claudePlanKnown(usage) {
+		lines[0] = fmt.Sprintf("Claude Code usage, %s plan:", usage.Plan)
+	}
+	for _, window := range quota.ClaudeWindows(usage) {
+		lines = append(lines, accessibleSentence(window, now, opts))
+	}
+	return lines
+}
+
+func accessibleCodexLines(usage *codex.Usage, now time.Time, opts Options) []string {
+	lines := []string{fmt.Sprintf("Codex usage, %s plan:", usage.Plan.String())}
+
+	windows := quota.CodexWindows(usage)
+	if len(windows) == 0 {
+		return append(lines, "No Codex rate-limit data available. Run codex login and try again.")
+	}
+
+	for _, window := range windows {
+		lines = append(lines, accessibleSentence(window, now, opts))
+		if estimate, ok := codexMessageEstimate(usage.Plan, window, opts); ok {
+			lines = append(lines, estimate...)
+		}
+	}
+	return lines
+}
+
+// accessibleSentence describes a window in plain words, e.g.
+// "Five-hour window: 40 percent used, resets in 2 hours 15 minutes."
+func accessibleSentence(window quota.Window, now time.Time, opts Options) string {
+	sentence := fmt.Sprintf("%s window: %d percent used",
+		spokenLabel(window.Label),
+		int(clampUtilization(window.Metric.Utilization)*100),
+	)
+	if reset := accessibleResetTime(window.Metric.ResetAt, now, opts.Time); reset != "" {
+		sentence += ", " + reset
+	}
+	return sentence + "."
+}
+
+// accessibleResetTime formats a reset time in words according to cfg.
+func accessibleResetTime(resetAt time.Time, now time.Time, cfg TimeConfig) string {
+	if resetAt.IsZero() {
+		return ""
+	}
+	if !resetAt.After(now) {
+		return "resets now"
+	}
+
+	relative := "resets in " + spokenDuration(resetAt.Sub(now))
+	switch cfg.Mode {
+	case TimeAbsolute:
+		return "resets at " + FormatAbsoluteTime(resetAt, now, cfg)
+	case TimeBoth:
+		return relative + ", at " + FormatAbsoluteTime(resetAt, now, cfg)
+	default:
+		return relative
+	}
+}
+
+// spokenDuration spells out a duration, e.g. "3 days 5 hours" or "45 seconds".
+func spokenDuration(d time.Duration) string {
+	totalHours := int(d.Hours())
+	units := []struct {
+		n    int
+		name string
+	}{
+		{totalHours / 24, "day"},
+		{totalHours % 24, "hour"},
+		{int(d.Minutes()) % 60, "minute"},
+	}
+
+	var parts []string
+	for _, u := range units {
+		if u.n > 0 {
+			parts = append(parts, pluralize(u.n, u.name))
+		}
+	}
+	if len(parts) == 0 {
+		return pluralize(int(d.Seconds()), "second")
+	}
+	return strings.Join(parts, " ")
+}
+
+func pluralize(n int, unit string) string {
+	if n == 1 {
+		return "1 " + unit
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
+
+// spokenLabel spells out the leading number of a window label, turning
+// "5-hour" into "Five-hour" and "7-day Sonnet" into "Seven-day Sonnet".
+func spokenLabel(label string) string {
+	number, rest, ok := strings.Cut(label, "-")
+	if !ok {
+		return label
+	}
+
+	var n int
+	if _, err := fmt.Sscanf(number, "%d", &n); err != nil || fmt.Sprint(n) != number || n >= len(numberWords) {
+		return label
+	}
+
+	word := numberWords[n]
+	return strings.ToUpper(word[:1]) + word[1:] + "-" + rest
+}
@@ -0,0 +1,88 @@
+package display
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/uesteibar/ccstats/internal/codex"
+)
+
+func TestDisplayAll_Accessible(t *testing.T) {
+	now := time.Date(2026, 1, 16, 12, 0, 0, 0, time.UTC)
+	usage, codexUsage := statusbarFixture(now)
+
+	var buf bytes.Buffer
+	DisplayAll(&buf, usage, codexUsage, now, Options{Accessible: true, Color: ColorConfig{Enabled: true}})
+
+	want := strings.Join([]string{
+		"",
+		"Claude Code usage:",
+		"Five-hour window: 40 percent used, resets in 2 hours 15 minutes.",
+		"Seven-day window: 85 percent used, resets in 3 days 5 hours.",
+		"Seven-day Sonnet window: 10 percent used, resets in 3 days 5 hours.",
+		"",
+		"",
+		"Codex usage, Plus plan:",
+		"Five-hour window: 20 percent used, resets in 2 hours 10 minutes.",
+		"Roughly 9 to 45 of 45 to 225 local messages used, by plan limits as of " + codex.EmbeddedPlanLimits().LastUpdated + ".",
+		"",
+		"",
+	}, "\n")
+	if buf.String() != want {
+		t.Errorf("unexpected output:\n%q\nwant:\n%q", buf.String(), want)
+	}
+}
+
+func TestDisplayCodexUsageWithOptions_AccessibleNoData(t *testing.T) {
+	var buf bytes.Buffer
+	DisplayCodexUsageWithOptions(&buf, &codex.Usage{Plan: codex.PlanPro}, time.Now(), Options{Accessible: true})
+
+	if !strings.Contains(buf.String(), "Codex usage, Pro plan:\nNo Codex rate-limit data available.") {
+		t.Errorf("unexpected output:\n%s", buf.String())
+	}
+}
+
+func TestAccessibleResetTime(t *testing.T) {
+	now := time.Date(2026, 1, 16, 12, 0, 0, 0, time.UTC)
+	utc := TimeConfig{Location: time.UTC, Clock24: true}
+
+	tests := []struct {
+		name    string
+		resetAt time.Time
+		mode    TimeMode
+		want    string
+	}{
+		{name: "singular units", resetAt: now.Add(25*time.Hour + time.Minute), mode: TimeRelative, want: "resets in 1 day 1 hour 1 minute"},
+		{name: "seconds", resetAt: now.Add(45 * time.Second), mode: TimeRelative, want: "resets in 45 seconds"},
+		{name: "past", resetAt: now.Add(-time.Minute), mode: TimeRelative, want: "resets now"},
+		{name: "absolute", resetAt: now.Add(2 * time.Hour), mode: TimeAbsolute, want: "resets at 14:00 UTC"},
+		{name: "both", resetAt: now.Add(2 * time.Hour), mode: TimeBoth, want: "resets in 2 hours, at 14:00 UTC"},
+		{name: "zero", resetAt: time.Time{}, mode: TimeRelative, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := utc
+			cfg.Mode = tt.mode
+			if got := accessibleResetTime(tt.resetAt, now, cfg); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSpokenLabel(t *testing.T) {
+	tests := map[string]string{
+		"5-hour":       "Five-hour",
+		"7-day Sonnet": "Seven-day Sonnet",
+		"90-min":       "90-min",
+		"Limit":        "Limit",
+	}
+	for label, want := range tests {
+		if got := spokenLabel(label); got != want {
+			t.Errorf("spokenLabel(%q) = %q, want %q", label, got, want)
+		}
+	}
+}
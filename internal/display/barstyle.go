@@ -0,0 +1,81 @@
+package display
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BarStyle selects the characters used to draw progress bars.
+type BarStyle string
+
+const (
+	// BarBlocks draws full and shaded blocks: [████░░░░].
+	BarBlocks BarStyle = "blocks"
+	// BarASCII draws plain ASCII: [####----].
+	BarASCII BarStyle = "ascii"
+	// BarFractional uses eighth blocks for sub-character precision: [███▍    ].
+	BarFractional BarStyle = "fractional"
+)
+
+// fractionalChars holds the partial blocks for one to seven eighths.
+var fractionalChars = []string{"▏", "▎", "▍", "▌", "▋", "▊", "▉"}
+
+// ParseBarStyle validates a bar style name. An empty name is BarBlocks.
+func ParseBarStyle(s string) (BarStyle, error) {
+	switch style := BarStyle(strings.ToLower(strings.TrimSpace(s))); style {
+	case "":
+		return BarBlocks, nil
+	case BarBlocks, BarASCII, BarFractional:
+		return style, nil
+	default:
+		return "", fmt.Errorf("unknown bar style %q (want blocks, ascii or fractional)", s)
+	}
+}
+
+// barCells splits a bar of width cells into its drawn cells and the number
+// of trailing empty cells. utilization must already be clamped to 0.0-1.0.
+func barCells(utilization float64, width int, style BarStyle) (drawn []string, empty int) {
+	switch style {
+	case BarASCII:
+		filled := int(utilization * float64(width))
+		return repeatCell("#", filled), width - filled
+	case BarFractional:
+		eighths := int(utilization * float64(width) * 8)
+		drawn = repeatCell(FilledChar, eighths/8)
+		if rem := eighths % 8; rem > 0 {
+			drawn = append(drawn, fractionalChars[rem-1])
+		}
+		return drawn, width - len(drawn)
+	default:
+		filled := int(utilization * float64(width))
+		return repeatCell(FilledChar, filled), width - filled
+	}
+}
+
+// emptyCell returns the character for unfilled cells.
+func (s BarStyle) emptyCell() string {
+	switch s {
+	case BarASCII:
+		return "-"
+	case BarFractional:
+		return " "
+	default:
+		return EmptyChar
+	}
+}
+
+// ruleChar returns the character used for section rules.
+func (s BarStyle) ruleChar() string {
+	if s == BarASCII {
+		return "-"
+	}
+	return "─"
+}
+
+func repeatCell(cell string, n int) []string {
+	cells := make([]string, n)
+	for i := range cells {
+		cells[i] = cell
+	}
+	return cells
+}
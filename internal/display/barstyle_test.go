@@ -0,0 +1,66 @@
+package display
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatProgressBar_Styles(t *testing.T) {
+	tests := []struct {
+		name        string
+		utilization float64
+		style       BarStyle
+		want        string
+	}{
+		{name: "blocks", utilization: 0.25, style: BarBlocks, want: "[█████░░░░░░░░░░░░░░░]  25%"},
+		{name: "ascii", utilization: 0.25, style: BarASCII, want: "[#####---------------]  25%"},
+		{name: "ascii full", utilization: 1.0, style: BarASCII, want: "[####################] 100%"},
+		{name: "fractional partial cell", utilization: 0.27, style: BarFractional, want: "[█████▍              ]  27%"},
+		{name: "fractional whole cells", utilization: 0.5, style: BarFractional, want: "[██████████          ]  50%"},
+		{name: "fractional empty", utilization: 0.0, style: BarFractional, want: "[                    ]   0%"},
+		{name: "default style", utilization: 0.5, style: "", want: "[██████████░░░░░░░░░░]  50%"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := formatProgressBar(tt.utilization, ProgressBarWidth, tt.style, ColorConfig{})
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			if w := visibleWidth(got); w != ProgressBarWidth+barDecorationWidth {
+				t.Errorf("expected width %d, got %d", ProgressBarWidth+barDecorationWidth, w)
+			}
+		})
+	}
+}
+
+func TestDisplayAll_ASCII(t *testing.T) {
+	now := time.Date(2026, 1, 16, 12, 0, 0, 0, time.UTC)
+	usage, codexUsage := statusbarFixture(now)
+
+	var buf bytes.Buffer
+	DisplayAll(&buf, usage, codexUsage, now, Options{Bar: BarASCII})
+
+	for _, r := range buf.String() {
+		if r > 127 {
+			t.Fatalf("expected ASCII-only output, found %q in:\n%s", r, buf.String())
+		}
+	}
+	if !strings.Contains(buf.String(), strings.Repeat("-", 60)) {
+		t.Errorf("expected ASCII rule, got:\n%s", buf.String())
+	}
+}
+
+func TestParseBarStyle(t *testing.T) {
+	if style, err := ParseBarStyle(""); err != nil || style != BarBlocks {
+		t.Errorf("ParseBarStyle(\"\") = %q, %v", style, err)
+	}
+	if style, err := ParseBarStyle("ASCII"); err != nil || style != BarASCII {
+		t.Errorf("ParseBarStyle(ASCII) = %q, %v", style, err)
+	}
+	if _, err := ParseBarStyle("dots"); err == nil {
+		t.Error("expected error for unknown style")
+	}
+}
@@ -0,0 +1,55 @@
+package display
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/uesteibar/ccstats/internal/quota"
+	"github.com/uesteibar/ccstats/internal/transcript"
+)
+
+// breakdownBarWidth is the width of the per-project bars.
+const breakdownBarWidth = 10
+
+// DisplayBreakdown writes a window's live utilization followed by the
+// estimated part of it each project consumed.
+func DisplayBreakdown(w io.Writer, window quota.Window, shares []transcript.Share, now time.Time, opts Options) {
+	lay := newLayout(opts.Width, resetWidth([]quota.Window{window}, now, opts))
+
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "Claude %s Window by Project\n", window.Label)
+	fmt.Fprintln(w, strings.Repeat(opts.Bar.ruleChar(), lay.ruleWidth))
+	for _, line := range formatMetricLines(window.Label, window.Metric, nil, now, opts, lay) {
+		fmt.Fprintln(w, line)
+	}
+	fmt.Fprintln(w)
+
+	if len(shares) == 0 {
+		fmt.Fprintf(w, "No transcript activity since %s.\n", FormatAbsoluteTime(window.Start(now), now, opts.Time))
+		fmt.Fprintln(w)
+		return
+	}
+
+	table := [][]string{{"Project", "Messages", "Tokens", "Share", "Estimated use"}}
+	for _, share := range shares {
+		table = append(table, []string{
+			groupKey(share.Key, transcript.GroupByProject),
+			formatCount(int64(share.Messages)),
+			formatCount(share.Usage.Total()),
+			fmt.Sprintf("%d%%", int(math.Round(share.Share*100))),
+			formatProgressBar(share.Utilization, breakdownBarWidth, opts.Bar, opts.Color),
+		})
+	}
+
+	for _, line := range formatTable(table, []bool{false, true, true, true, false}, opts.Bar.ruleChar()) {
+		fmt.Fprintln(w, line)
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "Estimated from local transcripts since %s; tokens are weighted by\n", FormatAbsoluteTime(window.Start(now), now, opts.Time))
+	fmt.Fprintln(w, "their model's API price.")
+	fmt.Fprintln(w)
+}
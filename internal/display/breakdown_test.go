@@ -0,0 +1,65 @@
+package display
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/uesteibar/ccstats/internal/api"
+	"github.com/uesteibar/ccstats/internal/pricing"
+	"github.com/uesteibar/ccstats/internal/quota"
+	"github.com/uesteibar/ccstats/internal/transcript"
+)
+
+func TestDisplayBreakdown(t *testing.T) {
+	now := time.Date(2026, 1, 16, 12, 0, 0, 0, time.UTC)
+	window := quota.Window{
+		Key:      "5h",
+		Label:    "5-hour",
+		Duration: 5 * time.Hour,
+		Metric:   api.UsageMetric{Utilization: 0.40, ResetAt: now.Add(2 * time.Hour)},
+	}
+	shares := transcript.Attribute([]transcript.Row{
+		{Key: "/srv/api", Messages: 12, Usage: transcript.Usage{Output: 60}},
+		{Key: "/srv/web", Messages: 3, Usage: transcript.Usage{Input: 100}},
+	}, window.Metric.Utilization, pricing.Embedded())
+
+	var buf bytes.Buffer
+	DisplayBreakdown(&buf, window, shares, now, Options{Bar: BarASCII, Time: TimeConfig{Location: time.UTC, Clock24: true}})
+	output := buf.String()
+
+	for _, want := range []string{
+		"Claude 5-hour Window by Project\n",
+		"5-hour         [########------------]  40%  resets in 2h\n",
+		"Project   Messages  Tokens  Share  Estimated use\n",
+		"/srv/api        12      60    75%  [###-------]  30%\n",
+		"/srv/web         3     100    25%  [#---------]  10%\n",
+		"since 09:00 UTC",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in output:\n%s", want, output)
+		}
+	}
+}
+
+func TestDisplayBreakdown_NoActivity(t *testing.T) {
+	now := time.Date(2026, 1, 16, 12, 0, 0, 0, time.UTC)
+	window := quota.Window{Key: "5h", Label: "5-hour", Duration: 5 * time.Hour}
+
+	var buf bytes.Buffer
+	DisplayBreakdown(&buf, window, nil, now, Options{Time: TimeConfig{Location: time.UTC, Clock24: true}})
+
+	if !strings.Contains(buf.String(), "No transcript activity since 07:00 UTC.") {
+		t.Errorf("expected no-activity message, got:\n%s", buf.String())
+	}
+}
+
+func TestWindow_Start(t *testing.T) {
+	now := time.Date(2026, 1, 16, 12, 0, 0, 0, time.UTC)
+	window := quota.Window{Duration: 5 * time.Hour, Metric: api.UsageMetric{ResetAt: now.Add(time.Hour)}}
+
+	if got, want := window.Start(now), now.Add(-4*time.Hour); !got.Equal(want) {
+		t.Errorf("expected start %v, got %v", want, got)
+	}
+}
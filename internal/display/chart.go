@@ -0,0 +1,213 @@
+package display
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+)
+
+const (
+	defaultChartWidth  = 80
+	defaultChartHeight = 10
+	minChartColumns    = 10
+	// chartAxisWidth is the space taken by the "100%" labels and the axis.
+	chartAxisWidth = 5
+)
+
+// ChartOptions sizes and styles a chart.
+type ChartOptions struct {
+	// Width is the chart width in columns including the axis. Zero uses 80.
+	Width int
+	// Height is the plot height in rows. Zero uses 10.
+	Height int
+	// ASCII draws with plain characters instead of braille dots.
+	ASCII bool
+	// Time sets the timezone and clock of the axis labels.
+	Time TimeConfig
+}
+
+// chartGlyphs are the characters a chart is drawn with.
+type chartGlyphs struct {
+	axis, tick, corner, rule, resetRule, reset string
+	point, connector                           string
+}
+
+var (
+	unicodeGlyphs = chartGlyphs{axis: "│", tick: "┤", corner: "└", rule: "─", resetRule: "┴", reset: "┊"}
+	asciiGlyphs   = chartGlyphs{axis: "|", tick: "+", corner: "+", rule: "-", resetRule: "+", reset: ":", point: "*", connector: "|"}
+)
+
+func (o ChartOptions) columns() int {
+	width := o.Width
+	if width <= 0 {
+		width = defaultChartWidth
+	}
+	return max(width-chartAxisWidth, minChartColumns)
+}
+
+func (o ChartOptions) rows() int {
+	if o.Height <= 0 {
+		return defaultChartHeight
+	}
+	return max(o.Height, 2)
+}
+
+// dotsPerCell returns the plot resolution of one character: 2x4 braille
+// dots, or a single ASCII character.
+func (o ChartOptions) dotsPerCell() (x, y int) {
+	if o.ASCII {
+		return 1, 1
+	}
+	return 2, 4
+}
+
+// ChartPoints returns how many values a chart plots across its width.
+func ChartPoints(opts ChartOptions) int {
+	x, _ := opts.dotsPerCell()
+	return opts.columns() * x
+}
+
+// Chart draws utilization values between 0 and 1, spread evenly from since
+// to until and NaN where nothing was recorded, as a line chart with the
+// given reset times marked.
+func Chart(w io.Writer, title string, values []float64, since, until time.Time, resets []time.Time, opts ChartOptions) {
+	cols, rows := opts.columns(), opts.rows()
+	dotsX, dotsY := opts.dotsPerCell()
+	glyphs := unicodeGlyphs
+	if opts.ASCII {
+		glyphs = asciiGlyphs
+	}
+
+	// grid holds 1 for a plotted point and 2 for a line joining two.
+	width, height := cols*dotsX, rows*dotsY
+	grid := make([][]uint8, height)
+	for i := range grid {
+		grid[i] = make([]uint8, width)
+	}
+	prevX, prevY := -1, -1
+	for i, v := range values {
+		if math.IsNaN(v) {
+			prevX = -1
+			continue
+		}
+		x := i * width / len(values)
+		y := height - 1 - int(math.Round(min(max(v, 0), 1)*float64(height-1)))
+		grid[y][x] = 1
+		if prevX >= 0 && x-prevX <= 1 {
+			for yy := min(prevY, y) + 1; yy < max(prevY, y); yy++ {
+				if grid[yy][x] == 0 {
+					grid[yy][x] = 2
+				}
+			}
+		}
+		prevX, prevY = x, y
+	}
+
+	resetCols := make(map[int]bool)
+	if span := until.Sub(since); span > 0 {
+		for _, at := range resets {
+			col := int(float64(at.Sub(since)) / float64(span) * float64(cols))
+			if col >= 0 && col < cols {
+				resetCols[col] = true
+			}
+		}
+	}
+
+	if title != "" {
+		fmt.Fprintln(w, title)
+	}
+	for row := 0; row < rows; row++ {
+		var b strings.Builder
+		b.WriteString(chartLabel(row, rows, glyphs))
+		for col := 0; col < cols; col++ {
+			b.WriteString(chartCell(grid, col, row, opts, glyphs, resetCols[col]))
+		}
+		fmt.Fprintln(w, strings.TrimRight(b.String(), " "))
+	}
+
+	var axis strings.Builder
+	axis.WriteString(strings.Repeat(" ", chartAxisWidth-1) + glyphs.corner)
+	for col := 0; col < cols; col++ {
+		if resetCols[col] {
+			axis.WriteString(glyphs.resetRule)
+		} else {
+			axis.WriteString(glyphs.rule)
+		}
+	}
+	fmt.Fprintln(w, axis.String())
+
+	left, right := chartTime(since, opts.Time), chartTime(until, opts.Time)
+	gap := max(cols-len(left)-len(right), 1)
+	fmt.Fprintln(w, strings.Repeat(" ", chartAxisWidth)+left+strings.Repeat(" ", gap)+right)
+	if len(resetCols) > 0 {
+		fmt.Fprintln(w, strings.Repeat(" ", chartAxisWidth)+glyphs.reset+" window reset")
+	}
+}
+
+// chartLabel returns the y-axis label and axis of a row: 100% at the top,
+// 50% in the middle and 0% at the bottom.
+func chartLabel(row, rows int, glyphs chartGlyphs) string {
+	switch row {
+	case 0:
+		return "100%" + glyphs.tick
+	case (rows - 1) / 2:
+		if rows > 2 {
+			return " 50%" + glyphs.tick
+		}
+	case rows - 1:
+		return "  0%" + glyphs.tick
+	}
+	return "    " + glyphs.axis
+}
+
+// chartCell returns the character for one cell of the plot.
+func chartCell(grid [][]uint8, col, row int, opts ChartOptions, glyphs chartGlyphs, reset bool) string {
+	if opts.ASCII {
+		switch grid[row][col] {
+		case 1:
+			return glyphs.point
+		case 2:
+			return glyphs.connector
+		}
+	} else if r := brailleCell(grid, col, row); r != 0 {
+		return string(r)
+	}
+	if reset {
+		return glyphs.reset
+	}
+	return " "
+}
+
+// brailleDots maps a dot's position in a cell, [y][x], to its bit in the
+// braille pattern.
+var brailleDots = [4][2]rune{{0x01, 0x08}, {0x02, 0x10}, {0x04, 0x20}, {0x40, 0x80}}
+
+// brailleCell returns the braille character of a 2x4 cell, or 0 when the
+// cell is empty.
+func brailleCell(grid [][]uint8, col, row int) rune {
+	var bits rune
+	for dy := 0; dy < 4; dy++ {
+		for dx := 0; dx < 2; dx++ {
+			if grid[row*4+dy][col*2+dx] != 0 {
+				bits |= brailleDots[dy][dx]
+			}
+		}
+	}
+	if bits == 0 {
+		return 0
+	}
+	return 0x2800 + bits
+}
+
+// chartTime formats an axis label.
+func chartTime(t time.Time, cfg TimeConfig) string {
+	if cfg.Location != nil {
+		t = t.In(cfg.Location)
+	}
+	if cfg.Clock24 {
+		return t.Format("Jan 2 15:04")
+	}
+	return t.Format("Jan 2 3:04PM")
+}
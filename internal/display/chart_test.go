@@ -0,0 +1,57 @@
+package display
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestChart(t *testing.T) {
+	since := time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+	until := since.Add(10 * time.Hour)
+	opts := ChartOptions{Width: 15, Height: 3, ASCII: true, Time: TimeConfig{Location: time.UTC, Clock24: true}}
+
+	values := []float64{0, 0.5, 1, math.NaN(), 0, 0, 0.5, 1, 1, 1}
+	if n := ChartPoints(opts); n != len(values) {
+		t.Fatalf("expected %d points, got %d", len(values), n)
+	}
+
+	var buf bytes.Buffer
+	Chart(&buf, "5h window utilization", values, since, until, []time.Time{since.Add(4 * time.Hour)}, opts)
+
+	want := strings.Join([]string{
+		"5h window utilization",
+		"100%+  * :  ***",
+		" 50%+ *  : *",
+		"  0%+*   **",
+		"    +----+-----",
+	}, "\n")
+	// The time labels below the axis overlap at this width and are left out.
+	got := buf.String()
+	if plot := strings.Join(strings.Split(got, "\n")[:5], "\n"); plot != want {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, plot)
+	}
+	if !strings.HasSuffix(got, "     : window reset\n") {
+		t.Errorf("expected a reset legend, got:\n%s", got)
+	}
+}
+
+func TestChart_Braille(t *testing.T) {
+	since := time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+	opts := ChartOptions{Width: 15, Height: 2}
+
+	// Each cell holds 2x4 dots; a flat line at 0% fills the bottom dots.
+	values := make([]float64, ChartPoints(opts))
+	var buf bytes.Buffer
+	Chart(&buf, "", values, since, since.Add(time.Hour), nil, opts)
+
+	lines := strings.Split(buf.String(), "\n")
+	if want := "  0%┤" + strings.Repeat("⣀", 10); lines[1] != want {
+		t.Errorf("expected %q, got %q", want, lines[1])
+	}
+	if strings.Contains(buf.String(), "window reset") {
+		t.Errorf("expected no reset legend without resets, got:\n%s", buf.String())
+	}
+}
@@ -38,3 +38,24 @@ func TestDisplayCodexUsage(t *testing.T) {
 		t.Fatal("expected 5-hour label")
 	}
 }
+
+func TestDisplayCodexUsageWithOptions_AbsoluteTime(t *testing.T) {
+	now := time.Date(2026, 1, 16, 12, 0, 0, 0, time.UTC)
+	usage := &codex.Usage{
+		Plan: codex.PlanPlus,
+		Primary: &codex.UsageWindow{
+			WindowDurationMins: 10080,
+			Utilization:        0.25,
+			ResetAt:            now.Add(3*24*time.Hour + 2*time.Hour),
+		},
+	}
+
+	var buf bytes.Buffer
+	DisplayCodexUsageWithOptions(&buf, usage, now, Options{
+		Time: TimeConfig{Mode: TimeAbsolute, Location: time.UTC, Clock24: true},
+	})
+
+	if !strings.Contains(buf.String(), "resets Mon 14:00 UTC") {
+		t.Errorf("expected absolute reset time, got %q", buf.String())
+	}
+}
@@ -0,0 +1,119 @@
+package display
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/uesteibar/ccstats/internal/codex"
+	"github.com/uesteibar/ccstats/internal/quota"
+)
+
+// localMessagesWindow is the window that the local message limits in the
+// plan table refer to.
+const localMessagesWindow = 5 * time.Hour
+
+// DisplayCodexPlans writes a comparison table of the Codex plan limits,
+// highlighting the current plan and listing its notes and where the data
+// came from.
+func DisplayCodexPlans(w io.Writer, data *codex.PlanLimitsData, current codex.Plan, opts Options) {
+	limits := data.Plans
+	header := []string{"Plan", "Local messages / 5h", "Cloud tasks / 5h", "Code reviews / week"}
+	rows := [][]string{header}
+	for _, l := range limits {
+		rows = append(rows, []string{
+			l.Plan.String(),
+			l.LocalMessages5h.String(),
+			l.CloudTasks5h.String(),
+			l.CodeReviewsWeek.String(),
+		})
+	}
+
+	widths := make([]int, len(header))
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], len(cell))
+		}
+	}
+
+	marker := "▶"
+	if opts.Bar == BarASCII || opts.Accessible {
+		marker = "*"
+	}
+
+	totalWidth := 2
+	for _, width := range widths {
+		totalWidth += width + 2
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Codex Plan Limits")
+	fmt.Fprintln(w, strings.Repeat(opts.Bar.ruleChar(), totalWidth-2))
+	for i, row := range rows {
+		cells := make([]string, len(row))
+		for j, cell := range row {
+			cells[j] = fmt.Sprintf("%-*s", widths[j], cell)
+		}
+		line := strings.TrimRight(strings.Join(cells, "  "), " ")
+
+		isCurrent := i > 0 && limits[i-1].Plan == current
+		switch {
+		case isCurrent && opts.Color.Enabled:
+			fmt.Fprintln(w, marker+" "+colorBold+line+colorReset)
+		case isCurrent:
+			fmt.Fprintln(w, marker+" "+line)
+		default:
+			fmt.Fprintln(w, "  "+line)
+		}
+	}
+
+	for _, l := range limits {
+		if l.Plan != current || len(l.Notes) == 0 {
+			continue
+		}
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "Notes for your plan (%s):\n", l.Plan.String())
+		for _, note := range l.Notes {
+			fmt.Fprintln(w, "- "+note)
+		}
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "Limits last updated %s (%s).\n", data.LastUpdated, data.Origin())
+	if data.Source != "" {
+		fmt.Fprintf(w, "Source: %s\n", data.Source)
+	}
+	fmt.Fprintln(w)
+}
+
+// codexMessageEstimate translates a window's utilization into the range of
+// local messages it roughly corresponds to on the given plan, followed by
+// the date of the plan limits it is based on, e.g.
+// "≈ 13–67 of 45–225 local messages used" and "limits as of 2025-11-20".
+func codexMessageEstimate(plan codex.Plan, window quota.Window, opts Options) ([]string, bool) {
+	if window.Duration != localMessagesWindow {
+		return nil, false
+	}
+
+	limits, ok := codex.PlanLimitsFor(plan)
+	if !ok {
+		return nil, false
+	}
+
+	used, ok := limits.LocalMessages5h.Used(window.Metric.Utilization)
+	if !ok {
+		return nil, false
+	}
+
+	total := limits.LocalMessages5h
+	updated := codex.CurrentPlanLimits().LastUpdated
+	switch {
+	case opts.Accessible:
+		return []string{fmt.Sprintf("Roughly %d to %d of %d to %d local messages used, by plan limits as of %s.", used.Min, used.Max, total.Min, total.Max, updated)}, true
+	case opts.Bar == BarASCII:
+		return []string{fmt.Sprintf("~ %d-%d of %d-%d local messages used", used.Min, used.Max, total.Min, total.Max), "limits as of " + updated}, true
+	default:
+		return []string{fmt.Sprintf("≈ %d–%d of %d–%d local messages used", used.Min, used.Max, total.Min, total.Max), "limits as of " + updated}, true
+	}
+}
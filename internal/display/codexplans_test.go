@@ -0,0 +1,84 @@
+package display
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/uesteibar/ccstats/internal/codex"
+	"github.com/uesteibar/ccstats/internal/quota"
+)
+
+func TestDisplayCodexPlans(t *testing.T) {
+	var buf bytes.Buffer
+	DisplayCodexPlans(&buf, codex.EmbeddedPlanLimits(), codex.PlanPro, Options{})
+
+	assertGolden(t, "codex_plans", buf.String())
+}
+
+func TestDisplayCodexPlans_HighlightsWithColor(t *testing.T) {
+	var buf bytes.Buffer
+	DisplayCodexPlans(&buf, codex.EmbeddedPlanLimits(), codex.PlanPlus, Options{Color: ColorConfig{Enabled: true}, Bar: BarASCII})
+	output := buf.String()
+
+	if !strings.Contains(output, "* "+colorBold+"Plus ") {
+		t.Errorf("expected highlighted Plus row, got:\n%s", output)
+	}
+	if strings.Contains(output, "▶") {
+		t.Errorf("expected ASCII marker, got:\n%s", output)
+	}
+}
+
+func TestDisplayCodexUsage_MessageEstimate(t *testing.T) {
+	now := time.Date(2026, 1, 16, 12, 0, 0, 0, time.UTC)
+	usage := &codex.Usage{
+		Plan:      codex.PlanPlus,
+		Primary:   &codex.UsageWindow{WindowDurationMins: 300, Utilization: 0.30, ResetAt: now.Add(time.Hour)},
+		Secondary: &codex.UsageWindow{WindowDurationMins: 10080, Utilization: 0.50, ResetAt: now.Add(48 * time.Hour)},
+	}
+
+	var buf bytes.Buffer
+	DisplayCodexUsageWithOptions(&buf, usage, now, Options{})
+	output := buf.String()
+
+	want := "5-hour         [██████░░░░░░░░░░░░░░]  30%  resets in 1h\n" +
+		"               ≈ 13–67 of 45–225 local messages used\n" +
+		"               limits as of " + codex.EmbeddedPlanLimits().LastUpdated + "\n" +
+		"7-day "
+	if !strings.Contains(output, want) {
+		t.Errorf("expected estimate under the 5-hour window, got:\n%s", output)
+	}
+	if strings.Count(output, "messages used") != 1 {
+		t.Errorf("expected a single estimate, got:\n%s", output)
+	}
+}
+
+func TestCodexMessageEstimate_UnlimitedPlan(t *testing.T) {
+	window := quota.Window{Duration: 5 * time.Hour}
+	if _, ok := codexMessageEstimate(codex.PlanEnterprise, window, Options{}); ok {
+		t.Error("expected no estimate for a plan without fixed limits")
+	}
+	if _, ok := codexMessageEstimate(codex.PlanUnknown, window, Options{}); ok {
+		t.Error("expected no estimate for an unknown plan")
+	}
+}
+
+func TestDisplayCodexPlans_ShowsOverrideOrigin(t *testing.T) {
+	data := &codex.PlanLimitsData{
+		LastUpdated: "2026-03-01",
+		Path:        "/home/me/.config/ccstats/codex-plan-limits.json",
+		Plans:       []codex.PlanLimits{{Plan: codex.PlanGo}},
+	}
+
+	var buf bytes.Buffer
+	DisplayCodexPlans(&buf, data, codex.PlanGo, Options{})
+
+	want := "Limits last updated 2026-03-01 (/home/me/.config/ccstats/codex-plan-limits.json).\n"
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("expected %q, got:\n%s", want, buf.String())
+	}
+	if strings.Contains(buf.String(), "Source:") {
+		t.Errorf("expected no source line without a source, got:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,96 @@
+package display
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/uesteibar/ccstats/internal/codex"
+)
+
+// DisplayCodexSessions writes a table of Codex session activity grouped by
+// by, followed by a total row.
+func DisplayCodexSessions(w io.Writer, rows []codex.SessionRow, total codex.SessionRow, by codex.SessionGroupBy, opts Options) {
+	title := sessionGroupTitle(by)
+
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "Codex Sessions by %s\n", title)
+	fmt.Fprintln(w)
+
+	if len(rows) == 0 {
+		fmt.Fprintln(w, "No Codex sessions found for this period.")
+		fmt.Fprintln(w)
+		return
+	}
+
+	header := []string{title, "Sessions", "Turns", "Input", "Cached", "Output", "Reasoning", "Total"}
+	numeric := []bool{false, true, true, true, true, true, true, true}
+	if by != codex.SessionsByModel {
+		header = append(header, "Models")
+		numeric = append(numeric, false)
+	}
+
+	table := [][]string{header}
+	for _, row := range rows {
+		key := row.Key
+		switch {
+		case key == "":
+			key = "(unknown)"
+		case by == codex.SessionsByWorkspace:
+			key = shortenPath(key)
+		}
+		table = append(table, sessionCells(row, key, by))
+	}
+	table = append(table, nil, sessionCells(total, "Total", by))
+
+	for _, line := range formatTable(table, numeric, opts.Bar.ruleChar()) {
+		fmt.Fprintln(w, line)
+	}
+	fmt.Fprintln(w)
+}
+
+func sessionCells(row codex.SessionRow, key string, by codex.SessionGroupBy) []string {
+	cells := []string{
+		key,
+		formatCount(int64(row.Sessions)),
+		formatCount(int64(row.Turns)),
+		formatCount(row.Usage.Input),
+		formatCount(row.Usage.CachedInput),
+		formatCount(row.Usage.Output),
+		formatCount(row.Usage.ReasoningOutput),
+		formatCount(row.Usage.Total()),
+	}
+	if by != codex.SessionsByModel {
+		cells = append(cells, modelList(row.Models))
+	}
+	return cells
+}
+
+// modelList names the models of a row, most used first.
+func modelList(models map[string]codex.TokenUsage) string {
+	names := make([]string, 0, len(models))
+	for name := range models {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if ti, tj := models[names[i]].Total(), models[names[j]].Total(); ti != tj {
+			return ti > tj
+		}
+		return names[i] < names[j]
+	})
+	return strings.Join(names, ", ")
+}
+
+func sessionGroupTitle(by codex.SessionGroupBy) string {
+	switch by {
+	case codex.SessionsByWorkspace:
+		return "Workspace"
+	case codex.SessionsByModel:
+		return "Model"
+	default:
+		return "Day"
+	}
+}
@@ -0,0 +1,48 @@
+package display
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/uesteibar/ccstats/internal/codex"
+)
+
+func TestDisplayCodexSessions(t *testing.T) {
+	rows := []codex.SessionRow{
+		{Key: "2026-01-15", Sessions: 1, Turns: 3, Usage: codex.TokenUsage{Input: 1000, CachedInput: 200, Output: 300, ReasoningOutput: 100},
+			Models: map[string]codex.TokenUsage{"gpt-5-codex": {Input: 1000, Output: 300}}},
+		{Key: "2026-01-16", Sessions: 2, Turns: 1, Usage: codex.TokenUsage{Input: 20, Output: 5},
+			Models: map[string]codex.TokenUsage{"gpt-5": {Input: 5}, "gpt-5-codex": {Input: 15, Output: 5}}},
+	}
+	total := codex.SessionRow{Sessions: 2, Turns: 4, Usage: codex.TokenUsage{Input: 1020, CachedInput: 200, Output: 305, ReasoningOutput: 100}}
+
+	var buf bytes.Buffer
+	DisplayCodexSessions(&buf, rows, total, codex.SessionsByDay, Options{Bar: BarASCII})
+
+	want := `
+Codex Sessions by Day
+
+Day         Sessions  Turns  Input  Cached  Output  Reasoning  Total  Models
+----------------------------------------------------------------------------------------
+2026-01-15         1      3  1,000     200     300        100  1,300  gpt-5-codex
+2026-01-16         2      1     20       0       5          0     25  gpt-5-codex, gpt-5
+----------------------------------------------------------------------------------------
+Total              2      4  1,020     200     305        100  1,325
+
+`
+	if buf.String() != want {
+		t.Errorf("unexpected output:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func TestDisplayCodexSessions_ByModelOmitsModelsColumn(t *testing.T) {
+	rows := []codex.SessionRow{{Key: "gpt-5", Sessions: 1}}
+
+	var buf bytes.Buffer
+	DisplayCodexSessions(&buf, rows, codex.SessionRow{Sessions: 1}, codex.SessionsByModel, Options{})
+
+	if strings.Contains(buf.String(), "Models") {
+		t.Errorf("expected no Models column, got:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,66 @@
+package display
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// ColorMode is the user's color preference.
+type ColorMode string
+
+const (
+	// ColorAuto enables colors on terminals unless the environment says otherwise.
+	ColorAuto ColorMode = "auto"
+	// ColorAlways enables colors regardless of the environment.
+	ColorAlways ColorMode = "always"
+	// ColorNever disables colors.
+	ColorNever ColorMode = "never"
+)
+
+// ParseColorMode validates a color mode name. An empty name is ColorAuto.
+func ParseColorMode(s string) (ColorMode, error) {
+	switch mode := ColorMode(strings.ToLower(strings.TrimSpace(s))); mode {
+	case "":
+		return ColorAuto, nil
+	case ColorAuto, ColorAlways, ColorNever:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown color mode %q (want auto, always or never)", s)
+	}
+}
+
+// ResolveColor decides whether to use colors. An explicit always or never
+// wins. In auto mode, in order of precedence: NO_COLOR (any non-empty
+// value) disables colors, CLICOLOR_FORCE (any value other than "0")
+// enables them, CLICOLOR=0 or TERM=dumb disable them, and otherwise colors
+// follow whether the output is a terminal.
+func ResolveColor(mode ColorMode, getenv func(string) string, isTTY bool) ColorConfig {
+	switch mode {
+	case ColorAlways:
+		return ColorConfig{Enabled: true}
+	case ColorNever:
+		return ColorConfig{Enabled: false}
+	}
+
+	if getenv("NO_COLOR") != "" {
+		return ColorConfig{Enabled: false}
+	}
+	if force := getenv("CLICOLOR_FORCE"); force != "" && force != "0" {
+		return ColorConfig{Enabled: true}
+	}
+	if getenv("CLICOLOR") == "0" || getenv("TERM") == "dumb" {
+		return ColorConfig{Enabled: false}
+	}
+	return ColorConfig{Enabled: isTTY}
+}
+
+// ResolveColorForStdout applies ResolveColor to the process environment and
+// stdout, and detects the terminal's color depth.
+func ResolveColorForStdout(mode ColorMode) ColorConfig {
+	cfg := ResolveColor(mode, os.Getenv, term.IsTerminal(int(os.Stdout.Fd())))
+	cfg.Depth = DetectColorDepth(os.Getenv)
+	return cfg
+}
@@ -0,0 +1,49 @@
+package display
+
+import "testing"
+
+func TestResolveColor(t *testing.T) {
+	tests := []struct {
+		name  string
+		mode  ColorMode
+		env   map[string]string
+		isTTY bool
+		want  bool
+	}{
+		{name: "auto on tty", mode: ColorAuto, isTTY: true, want: true},
+		{name: "auto piped", mode: ColorAuto, isTTY: false, want: false},
+		{name: "always piped", mode: ColorAlways, isTTY: false, want: true},
+		{name: "never on tty", mode: ColorNever, isTTY: true, want: false},
+		{name: "always beats NO_COLOR", mode: ColorAlways, env: map[string]string{"NO_COLOR": "1"}, want: true},
+		{name: "NO_COLOR on tty", mode: ColorAuto, env: map[string]string{"NO_COLOR": "1"}, isTTY: true, want: false},
+		{name: "empty NO_COLOR ignored", mode: ColorAuto, env: map[string]string{"NO_COLOR": ""}, isTTY: true, want: true},
+		{name: "NO_COLOR beats CLICOLOR_FORCE", mode: ColorAuto, env: map[string]string{"NO_COLOR": "1", "CLICOLOR_FORCE": "1"}, want: false},
+		{name: "CLICOLOR_FORCE piped", mode: ColorAuto, env: map[string]string{"CLICOLOR_FORCE": "1"}, want: true},
+		{name: "CLICOLOR_FORCE=0 ignored", mode: ColorAuto, env: map[string]string{"CLICOLOR_FORCE": "0"}, isTTY: false, want: false},
+		{name: "CLICOLOR_FORCE beats TERM=dumb", mode: ColorAuto, env: map[string]string{"CLICOLOR_FORCE": "1", "TERM": "dumb"}, want: true},
+		{name: "TERM=dumb on tty", mode: ColorAuto, env: map[string]string{"TERM": "dumb"}, isTTY: true, want: false},
+		{name: "CLICOLOR=0 on tty", mode: ColorAuto, env: map[string]string{"CLICOLOR": "0"}, isTTY: true, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getenv := func(key string) string { return tt.env[key] }
+			got := ResolveColor(tt.mode, getenv, tt.isTTY)
+			if got.Enabled != tt.want {
+				t.Errorf("ResolveColor() enabled = %v, want %v", got.Enabled, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseColorMode(t *testing.T) {
+	if mode, err := ParseColorMode(""); err != nil || mode != ColorAuto {
+		t.Errorf("ParseColorMode(\"\") = %q, %v", mode, err)
+	}
+	if mode, err := ParseColorMode("Always"); err != nil || mode != ColorAlways {
+		t.Errorf("ParseColorMode(Always) = %q, %v", mode, err)
+	}
+	if _, err := ParseColorMode("sometimes"); err == nil {
+		t.Error("expected error for unknown mode")
+	}
+}
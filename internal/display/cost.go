@@ -0,0 +1,103 @@
+package display
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/uesteibar/ccstats/internal/pricing"
+)
+
+// DisplayCost writes a table of API-equivalent costs grouped by by,
+// followed by a total row and the price table it used.
+func DisplayCost(w io.Writer, rows []pricing.Row, by pricing.GroupBy, table *pricing.Table, unpriced map[string]int64, opts Options) {
+	title := costGroupTitle(by)
+
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "API-Equivalent Cost by %s\n", title)
+	fmt.Fprintln(w)
+
+	if len(rows) == 0 {
+		fmt.Fprintln(w, "No usage found for this period.")
+		fmt.Fprintln(w)
+		return
+	}
+
+	data := [][]string{{title, "Tokens", "Claude", "Codex", "Total"}}
+	for _, row := range rows {
+		key := row.Key
+		switch {
+		case key == "":
+			key = "(unknown)"
+		case by == pricing.GroupByProject:
+			key = shortenPath(key)
+		}
+		data = append(data, costCells(row, key, table.Currency))
+	}
+	data = append(data, nil, costCells(pricing.Total(rows), "Total", table.Currency))
+
+	for _, line := range formatTable(data, []bool{false, true, true, true, true}, opts.Bar.ruleChar()) {
+		fmt.Fprintln(w, line)
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "What these tokens would cost at API rates as of %s (%s).\n", table.LastUpdated, table.Origin())
+
+	if len(unpriced) > 0 {
+		models := make([]string, 0, len(unpriced))
+		for model := range unpriced {
+			models = append(models, model)
+		}
+		sort.Strings(models)
+
+		parts := make([]string, len(models))
+		for i, model := range models {
+			name := model
+			if name == "" {
+				name = "(unknown model)"
+			}
+			parts[i] = fmt.Sprintf("%s (%s tokens)", name, formatCount(unpriced[model]))
+		}
+		fmt.Fprintf(w, "Not priced: %s.\n", strings.Join(parts, ", "))
+	}
+	fmt.Fprintln(w)
+}
+
+func costCells(row pricing.Row, key, currency string) []string {
+	return []string{
+		key,
+		formatCount(row.Tokens),
+		formatMoney(row.Claude, currency),
+		formatMoney(row.Codex, currency),
+		formatMoney(row.Total(), currency),
+	}
+}
+
+// formatMoney formats an amount with two decimals and thousands separators,
+// e.g. "$1,234.56". Currencies other than USD are prefixed with their code.
+func formatMoney(amount float64, currency string) string {
+	cents := int64(math.Round(amount * 100))
+	sign := ""
+	if cents < 0 {
+		sign, cents = "-", -cents
+	}
+
+	symbol := "$"
+	if currency != "" && currency != "USD" {
+		symbol = currency + " "
+	}
+	return fmt.Sprintf("%s%s%s.%02d", sign, symbol, formatCount(cents/100), cents%100)
+}
+
+func costGroupTitle(by pricing.GroupBy) string {
+	switch by {
+	case pricing.GroupByProject:
+		return "Project"
+	case pricing.GroupByModel:
+		return "Model"
+	default:
+		return "Day"
+	}
+}
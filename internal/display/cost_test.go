@@ -0,0 +1,65 @@
+package display
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/uesteibar/ccstats/internal/pricing"
+)
+
+func TestDisplayCost(t *testing.T) {
+	rows := []pricing.Row{
+		{Key: "2026-01-15", Tokens: 1_204_881, Claude: 12.5, Codex: 1.234},
+		{Key: "2026-01-16", Tokens: 80_000, Claude: 1003.1},
+	}
+	table := &pricing.Table{LastUpdated: "2025-11-25", Currency: "USD"}
+
+	var buf bytes.Buffer
+	DisplayCost(&buf, rows, pricing.GroupByDay, table, map[string]int64{"mystery": 1200}, Options{Bar: BarASCII})
+
+	want := `
+API-Equivalent Cost by Day
+
+Day            Tokens     Claude  Codex      Total
+--------------------------------------------------
+2026-01-15  1,204,881     $12.50  $1.23     $13.73
+2026-01-16     80,000  $1,003.10  $0.00  $1,003.10
+--------------------------------------------------
+Total       1,284,881  $1,015.60  $1.23  $1,016.83
+
+What these tokens would cost at API rates as of 2025-11-25 (built-in).
+Not priced: mystery (1,200 tokens).
+
+`
+	if buf.String() != want {
+		t.Errorf("unexpected output:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func TestDisplayCost_Empty(t *testing.T) {
+	var buf bytes.Buffer
+	DisplayCost(&buf, nil, pricing.GroupByModel, &pricing.Table{}, nil, Options{})
+
+	if !strings.Contains(buf.String(), "No usage found") {
+		t.Errorf("expected empty message, got:\n%s", buf.String())
+	}
+}
+
+func TestFormatMoney(t *testing.T) {
+	tests := []struct {
+		amount   float64
+		currency string
+		want     string
+	}{
+		{0, "USD", "$0.00"},
+		{1234.567, "USD", "$1,234.57"},
+		{-2.5, "", "-$2.50"},
+		{9.99, "EUR", "EUR 9.99"},
+	}
+	for _, tt := range tests {
+		if got := formatMoney(tt.amount, tt.currency); got != tt.want {
+			t.Errorf("formatMoney(%v, %q) = %q, want %q", tt.amount, tt.currency, got, tt.want)
+		}
+	}
+}
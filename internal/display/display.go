@@ -4,12 +4,11 @@ package display
 import (
 	"fmt"
 	"io"
-	"os"
 	"strings"
 	"time"
 
 	"github.com/uesteibar/ccstats/internal/api"
-	"golang.org/x/term"
+	"github.com/uesteibar/ccstats/internal/quota"
 )
 
 const (
@@ -24,6 +23,7 @@ const (
 // ANSI color codes
 const (
 	colorReset  = "\033[0m"
+	colorBold   = "\033[1m"
 	colorGreen  = "\033[32m"
 	colorYellow = "\033[33m"
 	colorRed    = "\033[31m"
@@ -32,26 +32,109 @@ const (
 // ColorConfig holds settings for color output.
 type ColorConfig struct {
 	Enabled bool
+	// Theme selects the colors. Nil uses ThemeDefault.
+	Theme *Theme
+	// Depth is the terminal's color capability.
+	Depth ColorDepth
 }
 
-// DefaultColorConfig returns a ColorConfig with colors enabled if stdout is a TTY.
+func (c ColorConfig) theme() Theme {
+	if c.Theme == nil {
+		return ThemeDefault
+	}
+	return *c.Theme
+}
+
+// colorize wraps text in the theme color for the given utilization.
+func (c ColorConfig) colorize(utilization float64, text string) string {
+	if !c.Enabled {
+		return text
+	}
+	seq := escape(c.theme().colorFor(LevelFor(utilization)).sgr(c.Depth))
+	if seq == "" {
+		return text
+	}
+	return seq + text + colorReset
+}
+
+// Options controls how usage sections are rendered.
+type Options struct {
+	Color ColorConfig
+	Time  TimeConfig
+	// Width is the terminal width in columns. Zero uses the fixed
+	// 60-column layout.
+	Width int
+	// Bar selects the progress bar characters. Empty uses BarBlocks.
+	Bar BarStyle
+	// Accessible replaces bars with plain sentences for screen readers.
+	Accessible bool
+	// Trends holds recent utilization by window key, oldest first and NaN
+	// where nothing was recorded, drawn as sparklines next to the bars.
+	Trends map[string][]float64
+}
+
+// DefaultOptions returns TTY-detected colors and relative reset times.
+func DefaultOptions() Options {
+	return Options{
+		Color: DefaultColorConfig(),
+		Time:  DefaultTimeConfig(),
+	}
+}
+
+// DefaultColorConfig returns a ColorConfig for stdout using the auto color
+// policy (see ResolveColor).
 func DefaultColorConfig() ColorConfig {
-	return ColorConfig{
-		Enabled: term.IsTerminal(int(os.Stdout.Fd())),
+	return ResolveColorForStdout(ColorAuto)
+}
+
+// Level buckets a utilization into the thresholds used for coloring.
+type Level int
+
+const (
+	// LevelLow is below 50% utilization.
+	LevelLow Level = iota
+	// LevelMedium is between 50% and 80% utilization.
+	LevelMedium
+	// LevelHigh is above 80% utilization.
+	LevelHigh
+)
+
+// String returns the lowercase name of the level.
+func (l Level) String() string {
+	switch l {
+	case LevelHigh:
+		return "high"
+	case LevelMedium:
+		return "medium"
+	default:
+		return "low"
 	}
 }
 
-// getColorForUtilization returns the appropriate ANSI color code for the given utilization.
-// < 50% = green, 50-80% = yellow, > 80% = red
-func getColorForUtilization(utilization float64) string {
+// LevelFor returns the threshold level for the given utilization (0.0-1.0).
+// < 50% = low, 50-80% = medium, > 80% = high
+func LevelFor(utilization float64) Level {
 	percentage := utilization * 100
 	if percentage > 80 {
-		return colorRed
+		return LevelHigh
 	}
 	if percentage >= 50 {
+		return LevelMedium
+	}
+	return LevelLow
+}
+
+// getColorForUtilization returns the appropriate ANSI color code for the given utilization.
+// < 50% = green, 50-80% = yellow, > 80% = red
+func getColorForUtilization(utilization float64) string {
+	switch LevelFor(utilization) {
+	case LevelHigh:
+		return colorRed
+	case LevelMedium:
 		return colorYellow
+	default:
+		return colorGreen
 	}
-	return colorGreen
 }
 
 // FormatProgressBar creates an ASCII progress bar for the given utilization (0.0-1.0).
@@ -61,32 +144,59 @@ func FormatProgressBar(utilization float64) string {
 }
 
 // FormatProgressBarWithColor creates an ASCII progress bar with optional color based on utilization.
-// < 50% = green, 50-80% = yellow, > 80% = red
+// With the default theme: < 50% = green, 50-80% = yellow, > 80% = red
 func FormatProgressBarWithColor(utilization float64, colorCfg ColorConfig) string {
-	// Clamp utilization to valid range
-	if utilization < 0 {
-		utilization = 0
-	}
-	if utilization > 1 {
-		utilization = 1
-	}
+	return formatProgressBar(utilization, ProgressBarWidth, BarBlocks, colorCfg)
+}
 
-	filled := int(utilization * float64(ProgressBarWidth))
-	empty := ProgressBarWidth - filled
+// formatProgressBar creates a progress bar with the given number of cells and style.
+func formatProgressBar(utilization float64, width int, style BarStyle, colorCfg ColorConfig) string {
+	utilization = clampUtilization(utilization)
+	drawn, empty := barCells(utilization, width, style)
+	emptyCells := strings.Repeat(style.emptyCell(), empty)
 
 	percentage := int(utilization * 100)
 
+	theme := colorCfg.theme()
+	if colorCfg.Enabled && theme.Gradient && colorCfg.Depth >= Depth256 {
+		if bar, ok := formatGradientBar(drawn, width, colorCfg); ok {
+			return bar + emptyCells + "] " + colorCfg.colorize(utilization, fmt.Sprintf("%3d%%", percentage))
+		}
+	}
+
 	bar := fmt.Sprintf("[%s%s] %3d%%",
-		strings.Repeat(FilledChar, filled),
-		strings.Repeat(EmptyChar, empty),
+		strings.Join(drawn, ""),
+		emptyCells,
 		percentage,
 	)
 
-	if colorCfg.Enabled {
-		color := getColorForUtilization(utilization)
-		return color + bar + colorReset
+	return colorCfg.colorize(utilization, bar)
+}
+
+// formatGradientBar renders the opening bracket and drawn cells, each
+// colored by its position along the theme's low-medium-high gradient.
+func formatGradientBar(drawn []string, width int, colorCfg ColorConfig) (string, bool) {
+	theme := colorCfg.theme()
+
+	var b strings.Builder
+	b.WriteString("[")
+	prev := ""
+	for i, cell := range drawn {
+		rgb, ok := theme.gradientAt((float64(i) + 0.5) / float64(width))
+		if !ok {
+			return "", false
+		}
+		seq := escape(Color{RGB: &rgb}.sgr(colorCfg.Depth))
+		if seq != prev {
+			b.WriteString(seq)
+			prev = seq
+		}
+		b.WriteString(cell)
+	}
+	if len(drawn) > 0 {
+		b.WriteString(colorReset)
 	}
-	return bar
+	return b.String(), true
 }
 
 // FormatRelativeTime formats a time.Time as a human-readable relative duration.
@@ -98,43 +208,7 @@ func FormatRelativeTime(resetAt time.Time) string {
 // FormatRelativeTimeFrom formats a time.Time as a human-readable relative duration from a given reference time.
 // This is useful for testing with deterministic time values.
 func FormatRelativeTimeFrom(resetAt time.Time, now time.Time) string {
-	if resetAt.IsZero() {
-		return ""
-	}
-
-	duration := resetAt.Sub(now)
-
-	if duration <= 0 {
-		return "resets now"
-	}
-
-	totalHours := int(duration.Hours())
-	days := totalHours / 24
-	hours := totalHours % 24
-	minutes := int(duration.Minutes()) % 60
-
-	if days > 0 {
-		if hours > 0 && minutes > 0 {
-			return fmt.Sprintf("resets in %dd %dh %dm", days, hours, minutes)
-		} else if hours > 0 {
-			return fmt.Sprintf("resets in %dd %dh", days, hours)
-		} else if minutes > 0 {
-			return fmt.Sprintf("resets in %dd %dm", days, minutes)
-		}
-		return fmt.Sprintf("resets in %dd", days)
-	}
-
-	if hours > 0 && minutes > 0 {
-		return fmt.Sprintf("resets in %dh %dm", hours, minutes)
-	} else if hours > 0 {
-		return fmt.Sprintf("resets in %dh", hours)
-	} else if minutes > 0 {
-		return fmt.Sprintf("resets in %dm", minutes)
-	}
-
-	// Less than a minute
-	seconds := int(duration.Seconds())
-	return fmt.Sprintf("resets in %ds", seconds)
+	return quota.ResetsIn(resetAt, now)
 }
 
 // FormatMetric formats a single usage metric with its name, progress bar, and reset time.
@@ -149,15 +223,19 @@ func FormatMetricFrom(name string, metric api.UsageMetric, now time.Time) string
 
 // FormatMetricWithColor formats a single usage metric with optional color output.
 func FormatMetricWithColor(name string, metric api.UsageMetric, now time.Time, colorCfg ColorConfig) string {
-	progressBar := FormatProgressBarWithColor(metric.Utilization, colorCfg)
-	relativeTime := FormatRelativeTimeFrom(metric.ResetAt, now)
-	return fmt.Sprintf("%-14s %s  %s", name, progressBar, relativeTime)
+	return FormatMetricWithOptions(name, metric, now, Options{Color: colorCfg, Time: TimeConfig{Mode: TimeRelative}})
+}
+
+// FormatMetricWithOptions formats a single usage metric with the given rendering options.
+// It always uses the fixed-width layout; Options.Width only affects whole sections.
+func FormatMetricWithOptions(name string, metric api.UsageMetric, now time.Time, opts Options) string {
+	return formatMetricLines(name, metric, nil, now, opts, newLayout(0, 0))[0]
 }
 
 // DisplayUsage writes the formatted usage response to the given writer.
 // It automatically detects if stdout is a TTY and enables colors accordingly.
 func DisplayUsage(w io.Writer, usage *api.UsageResponse) {
-	DisplayUsageWithColor(w, usage, time.Now(), DefaultColorConfig())
+	DisplayUsageWithOptions(w, usage, time.Now(), DefaultOptions())
 }
 
 // DisplayUsageFrom writes the formatted usage response to the given writer with a reference time.
@@ -167,11 +245,15 @@ func DisplayUsageFrom(w io.Writer, usage *api.UsageResponse, now time.Time) {
 
 // DisplayUsageWithColor writes the formatted usage response with optional color output.
 func DisplayUsageWithColor(w io.Writer, usage *api.UsageResponse, now time.Time, colorCfg ColorConfig) {
-	fmt.Fprintln(w)
-	fmt.Fprintln(w, "Claude Code Usage Statistics")
-	fmt.Fprintln(w, strings.Repeat("─", 60))
-	fmt.Fprintln(w, FormatMetricWithColor("5-hour", usage.FiveHour, now, colorCfg))
-	fmt.Fprintln(w, FormatMetricWithColor("7-day", usage.SevenDay, now, colorCfg))
-	fmt.Fprintln(w, FormatMetricWithColor("7-day Sonnet", usage.SevenDaySonnet, now, colorCfg))
-	fmt.Fprintln(w)
+	DisplayUsageWithOptions(w, usage, now, Options{Color: colorCfg, Time: TimeConfig{Mode: TimeRelative}})
+}
+
+// DisplayUsageWithOptions writes the formatted usage response with the given rendering options.
+func DisplayUsageWithOptions(w io.Writer, usage *api.UsageResponse, now time.Time, opts Options) {
+	if opts.Accessible {
+		writeSection(w, accessibleClaudeLines(usage, now, opts))
+		return
+	}
+	lay := newLayout(opts.Width, resetWidth(quota.ClaudeWindows(usage), now, opts))
+	writeSection(w, claudeSectionLines(usage, now, opts, lay))
 }
@@ -435,3 +435,51 @@ func TestColorConfigDefault(t *testing.T) {
 	// but we can verify the struct is valid
 	_ = cfg.Enabled // just verify it's accessible
 }
+
+func TestDisplayUsageWithOptions_BothTimes(t *testing.T) {
+	now := time.Date(2026, 1, 16, 12, 0, 0, 0, time.UTC)
+	usage := &api.UsageResponse{
+		FiveHour: api.UsageMetric{Utilization: 0.3, ResetAt: now.Add(2 * time.Hour)},
+		SevenDay: api.UsageMetric{Utilization: 0.5, ResetAt: now.Add(3 * 24 * time.Hour)},
+	}
+
+	var buf bytes.Buffer
+	DisplayUsageWithOptions(&buf, usage, now, Options{
+		Time: TimeConfig{Mode: TimeBoth, Location: time.UTC, Clock24: false},
+	})
+	output := buf.String()
+
+	if !strings.Contains(output, "resets in 2h (2:00 PM UTC)") {
+		t.Errorf("expected relative and absolute 5-hour reset, got %q", output)
+	}
+	if !strings.Contains(output, "resets in 3d (Mon 12:00 PM UTC)") {
+		t.Errorf("expected relative and absolute 7-day reset, got %q", output)
+	}
+}
+
+func TestDisplayUsageWithOptions_ClaudePlan(t *testing.T) {
+	now := time.Date(2026, 1, 16, 12, 0, 0, 0, time.UTC)
+	usage := &api.UsageResponse{
+		FiveHour: api.UsageMetric{Utilization: 0.25, ResetAt: now.Add(time.Hour)},
+		Plan:     api.PlanMax20x,
+	}
+
+	var buf bytes.Buffer
+	DisplayUsageWithOptions(&buf, usage, now, Options{})
+	if !strings.Contains(buf.String(), "Claude Code Usage Statistics (Plan: Max 20x)\n") {
+		t.Errorf("expected plan in header, got:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	DisplayUsageWithOptions(&buf, usage, now, Options{Accessible: true})
+	if !strings.Contains(buf.String(), "Claude Code usage, Max 20x plan:\n") {
+		t.Errorf("expected plan in accessible header, got:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	usage.Plan = api.PlanUnknown
+	DisplayUsageWithOptions(&buf, usage, now, Options{})
+	if !strings.Contains(buf.String(), "Claude Code Usage Statistics\n") {
+		t.Errorf("expected plain header for an unknown plan, got:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,263 @@
+package display
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/uesteibar/ccstats/internal/api"
+	"github.com/uesteibar/ccstats/internal/codex"
+	"github.com/uesteibar/ccstats/internal/quota"
+	"golang.org/x/term"
+)
+
+const (
+	// defaultRuleWidth is the length of the rule under section headers in
+	// the fixed layout.
+	defaultRuleWidth = 60
+	// labelWidth is the width of the metric name column.
+	labelWidth = 14
+	// condensedLabelWidth fits the longest label ("7-day Sonnet") in the
+	// condensed layout.
+	condensedLabelWidth = 12
+	// condensedMaxWidth is the width below which the condensed layout is used.
+	condensedMaxWidth = 50
+	// sideBySideMinWidth is the width from which Claude and Codex are shown
+	// in two columns.
+	sideBySideMinWidth = 140
+	// sideBySideGap separates the two columns.
+	sideBySideGap = 4
+	// barDecorationWidth is the space taken by the brackets and " 100%".
+	barDecorationWidth = 7
+	minBarWidth        = 10
+	maxBarWidth        = 60
+	minCondensedBar    = 5
+)
+
+var ansiEscape = regexp.MustCompile("\033\\[[0-9;]*m")
+
+// StdoutWidth returns the width of the terminal attached to stdout, or zero
+// when stdout is not a terminal.
+func StdoutWidth() int {
+	fd := int(os.Stdout.Fd())
+	if !term.IsTerminal(fd) {
+		return 0
+	}
+	width, _, err := term.GetSize(fd)
+	if err != nil {
+		return 0
+	}
+	return width
+}
+
+// layout holds the measurements for rendering a section.
+type layout struct {
+	barWidth  int
+	ruleWidth int
+	// condensed puts reset times on their own line for narrow terminals.
+	condensed bool
+	// trendWidth, when set, is the width of the sparkline column between
+	// the bars and the reset times.
+	trendWidth int
+}
+
+// newLayout sizes a section for a terminal of the given width. resetWidth is
+// the length of the longest reset time to fit next to the bars. A width of
+// zero returns the fixed 60-column layout.
+func newLayout(width int, resetWidth int) layout {
+	switch {
+	case width <= 0:
+		return layout{barWidth: ProgressBarWidth, ruleWidth: defaultRuleWidth}
+	case width < condensedMaxWidth:
+		bar := width - condensedLabelWidth - 1 - barDecorationWidth
+		return layout{barWidth: max(bar, minCondensedBar), ruleWidth: width, condensed: true}
+	default:
+		fixed := labelWidth + 1 + barDecorationWidth + 2 + resetWidth
+		bar := min(max(width-fixed, minBarWidth), maxBarWidth)
+		return layout{barWidth: bar, ruleWidth: min(fixed+bar, width)}
+	}
+}
+
+// withTrend makes room for a sparkline column of width cells, which the
+// condensed layout has none for.
+func (l layout) withTrend(width int) layout {
+	if !l.condensed {
+		l.trendWidth = width
+	}
+	return l
+}
+
+// resetWidth returns the length of the longest reset time among windows.
+func resetWidth(windows []quota.Window, now time.Time, opts Options) int {
+	longest := 0
+	for _, window := range windows {
+		longest = max(longest, len([]rune(FormatResetTime(window.Metric.ResetAt, now, opts.Time))))
+	}
+	return longest
+}
+
+// formatMetricLines formats a metric as one line, or two in the condensed
+// layout. trend is drawn as a sparkline when the layout has room for one.
+func formatMetricLines(name string, metric api.UsageMetric, trend []float64, now time.Time, opts Options, lay layout) []string {
+	progressBar := formatProgressBar(metric.Utilization, lay.barWidth, opts.Bar, opts.Color)
+	resetTime := FormatResetTime(metric.ResetAt, now, opts.Time)
+
+	if lay.condensed {
+		lines := []string{fmt.Sprintf("%-*s %s", condensedLabelWidth, name, progressBar)}
+		if resetTime != "" {
+			lines = append(lines, "  "+resetTime)
+		}
+		return lines
+	}
+
+	if lay.trendWidth > 0 {
+		spark := sparkline(trend, opts.Bar)
+		return []string{fmt.Sprintf("%-*s %s  %-*s  %s", labelWidth, name, progressBar, lay.trendWidth, spark, resetTime)}
+	}
+	return []string{fmt.Sprintf("%-*s %s  %s", labelWidth, name, progressBar, resetTime)}
+}
+
+func claudeSectionLines(usage *api.UsageResponse, now time.Time, opts Options, lay layout) []string {
+	lines := []string{
+		claudeHeader(usage),
+		strings.Repeat(opts.Bar.ruleChar(), lay.ruleWidth),
+	}
+	for _, window := range quota.ClaudeWindows(usage) {
+		lines = append(lines, formatMetricLines(window.Label, window.Metric, opts.Trends[window.Key], now, opts, lay)...)
+	}
+	return lines
+}
+
+// claudeHeader titles the Claude section, naming the plan when it is known.
+func claudeHeader(usage *api.UsageResponse) string {
+	if !claudePlanKnown(usage) {
+		return "Claude Code Usage Statistics"
+	}
+	return fmt.Sprintf("Claude Code Usage Statistics (Plan: %s)", usage.Plan)
+}
+
+func claudePlanKnown(usage *api.UsageResponse) bool {
+	return usage.Plan != "" && usage.Plan != api.PlanUnknown
+}
+
+func codexSectionLines(usage *codex.Usage, now time.Time, opts Options, lay layout) []string {
+	lines := []string{
+		fmt.Sprintf("Codex Usage Limits (Plan: %s)", usage.Plan.String()),
+		strings.Repeat(opts.Bar.ruleChar(), lay.ruleWidth),
+	}
+
+	windows := quota.CodexWindows(usage)
+	if len(windows) == 0 {
+		return append(lines,
+			"No Codex rate-limit data available.",
+			"Run `codex login` and try again.",
+		)
+	}
+
+	indent := strings.Repeat(" ", labelWidth+1)
+	if lay.condensed {
+		indent = "  "
+	}
+	for _, window := range windows {
+		lines = append(lines, formatMetricLines(window.Label, window.Metric, opts.Trends[window.Key], now, opts, lay)...)
+		if estimate, ok := codexMessageEstimate(usage.Plan, window, opts); ok {
+			for _, line := range estimate {
+				lines = append(lines, indent+line)
+			}
+		}
+	}
+	return lines
+}
+
+// writeSection writes lines surrounded by blank lines.
+func writeSection(w io.Writer, lines []string) {
+	fmt.Fprintln(w)
+	for _, line := range lines {
+		fmt.Fprintln(w, line)
+	}
+	fmt.Fprintln(w)
+}
+
+// DisplayProfileHeading titles the sections of one profile when several
+// are shown.
+func DisplayProfileHeading(w io.Writer, name string, opts Options) {
+	heading := "Profile: " + name
+	if opts.Color.Enabled {
+		heading = colorBold + heading + colorReset
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, heading)
+}
+
+// DisplayAll writes the Claude and Codex sections, either of which may be
+// nil. When Options.Width leaves room for both, they are laid out side by
+// side; otherwise Codex follows Claude.
+func DisplayAll(w io.Writer, usage *api.UsageResponse, codexUsage *codex.Usage, now time.Time, opts Options) {
+	if opts.Accessible {
+		if usage != nil {
+			writeSection(w, accessibleClaudeLines(usage, now, opts))
+		}
+		if codexUsage != nil {
+			writeSection(w, accessibleCodexLines(codexUsage, now, opts))
+		}
+		return
+	}
+
+	windows := append(quota.ClaudeWindows(usage), quota.CodexWindows(codexUsage)...)
+	reset := resetWidth(windows, now, opts)
+	trend := 0
+	if hasTrends(windows, opts) {
+		trend = SparklineWidth
+		reset += trend + 2
+	}
+
+	if usage != nil && codexUsage != nil && opts.Width >= sideBySideMinWidth {
+		columnWidth := (opts.Width - sideBySideGap) / 2
+		lay := newLayout(columnWidth, reset).withTrend(trend)
+		writeSection(w, joinColumns(
+			claudeSectionLines(usage, now, opts, lay),
+			codexSectionLines(codexUsage, now, opts, lay),
+			columnWidth+sideBySideGap,
+		))
+		return
+	}
+
+	lay := newLayout(opts.Width, reset).withTrend(trend)
+	if usage != nil {
+		writeSection(w, claudeSectionLines(usage, now, opts, lay))
+	}
+	if codexUsage != nil {
+		writeSection(w, codexSectionLines(codexUsage, now, opts, lay))
+	}
+}
+
+// joinColumns places right next to left, padding left lines to offset
+// visible columns.
+func joinColumns(left []string, right []string, offset int) []string {
+	rows := max(len(left), len(right))
+	lines := make([]string, 0, rows)
+	for i := 0; i < rows; i++ {
+		var l, r string
+		if i < len(left) {
+			l = left[i]
+		}
+		if i < len(right) {
+			r = right[i]
+		}
+		if r == "" {
+			lines = append(lines, l)
+			continue
+		}
+		lines = append(lines, l+strings.Repeat(" ", max(offset-visibleWidth(l), 1))+r)
+	}
+	return lines
+}
+
+// visibleWidth returns the number of terminal columns s occupies, ignoring
+// ANSI color escapes.
+func visibleWidth(s string) int {
+	return len([]rune(ansiEscape.ReplaceAllString(s, "")))
+}
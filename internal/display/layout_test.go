@@ -0,0 +1,121 @@
+package display
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewLayout(t *testing.T) {
+	tests := []struct {
+		name       string
+		width      int
+		resetWidth int
+		want       layout
+	}{
+		{name: "fixed", width: 0, resetWidth: 16, want: layout{barWidth: 20, ruleWidth: 60}},
+		{name: "condensed", width: 40, resetWidth: 16, want: layout{barWidth: 20, ruleWidth: 40, condensed: true}},
+		{name: "condensed minimum bar", width: 20, resetWidth: 16, want: layout{barWidth: 5, ruleWidth: 20, condensed: true}},
+		{name: "scaled", width: 100, resetWidth: 16, want: layout{barWidth: 60, ruleWidth: 100}},
+		{name: "scaled medium", width: 80, resetWidth: 16, want: layout{barWidth: 40, ruleWidth: 80}},
+		{name: "capped bar", width: 200, resetWidth: 16, want: layout{barWidth: 60, ruleWidth: 100}},
+		{name: "minimum bar", width: 50, resetWidth: 30, want: layout{barWidth: 10, ruleWidth: 50}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newLayout(tt.width, tt.resetWidth); got != tt.want {
+				t.Errorf("newLayout(%d, %d) = %+v, want %+v", tt.width, tt.resetWidth, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDisplayAll_Layouts(t *testing.T) {
+	now := time.Date(2026, 1, 16, 12, 0, 0, 0, time.UTC)
+	usage, codexUsage := statusbarFixture(now)
+
+	t.Run("fixed width matches sequential sections", func(t *testing.T) {
+		var all, sequential bytes.Buffer
+		DisplayAll(&all, usage, codexUsage, now, Options{})
+		DisplayUsageWithOptions(&sequential, usage, now, Options{})
+		DisplayCodexUsageWithOptions(&sequential, codexUsage, now, Options{})
+
+		if all.String() != sequential.String() {
+			t.Errorf("expected identical output:\n%s\nvs\n%s", all.String(), sequential.String())
+		}
+	})
+
+	t.Run("scaled bars fit the terminal", func(t *testing.T) {
+		var buf bytes.Buffer
+		DisplayAll(&buf, usage, codexUsage, now, Options{Width: 80})
+
+		for _, line := range strings.Split(strings.TrimRight(buf.String(), "\n"), "\n") {
+			if w := visibleWidth(line); w > 80 {
+				t.Errorf("line exceeds 80 columns (%d): %q", w, line)
+			}
+		}
+		if !strings.Contains(buf.String(), "["+strings.Repeat("█", 16)+strings.Repeat("░", 24)+"]  40%") {
+			t.Errorf("expected a 40-cell bar, got:\n%s", buf.String())
+		}
+	})
+
+	t.Run("condensed puts reset times on their own line", func(t *testing.T) {
+		var buf bytes.Buffer
+		DisplayAll(&buf, usage, nil, now, Options{Width: 40})
+
+		if !strings.Contains(buf.String(), "5-hour       [████████░░░░░░░░░░░░]  40%\n  resets in 2h 15m\n") {
+			t.Errorf("unexpected condensed output:\n%s", buf.String())
+		}
+		if !strings.Contains(buf.String(), strings.Repeat("─", 40)+"\n") {
+			t.Errorf("expected 40-column rule, got:\n%s", buf.String())
+		}
+	})
+
+	t.Run("side by side on wide terminals", func(t *testing.T) {
+		var buf bytes.Buffer
+		DisplayAll(&buf, usage, codexUsage, now, Options{Width: 160})
+		lines := strings.Split(buf.String(), "\n")
+
+		if !strings.HasPrefix(lines[1], "Claude Code Usage Statistics") || !strings.Contains(lines[1], "Codex Usage Limits (Plan: Plus)") {
+			t.Errorf("expected both headers on one line, got %q", lines[1])
+		}
+		if strings.Count(lines[3], "5-hour") != 2 {
+			t.Errorf("expected both 5-hour windows on one line, got %q", lines[3])
+		}
+		for _, line := range lines {
+			if w := visibleWidth(line); w > 160 {
+				t.Errorf("line exceeds 160 columns (%d): %q", w, line)
+			}
+		}
+	})
+
+	t.Run("side by side needs both providers", func(t *testing.T) {
+		var buf bytes.Buffer
+		DisplayAll(&buf, usage, nil, now, Options{Width: 160})
+		if strings.Contains(buf.String(), "Codex") {
+			t.Errorf("unexpected Codex section:\n%s", buf.String())
+		}
+	})
+}
+
+func TestVisibleWidth(t *testing.T) {
+	if got := visibleWidth("\033[32m[██░░]\033[0m"); got != 6 {
+		t.Errorf("visibleWidth() = %d, want 6", got)
+	}
+}
+
+func TestDisplayProfileHeading(t *testing.T) {
+	var plain bytes.Buffer
+	DisplayProfileHeading(&plain, "work", Options{})
+	if plain.String() != "\nProfile: work\n" {
+		t.Errorf("expected a plain heading, got %q", plain.String())
+	}
+
+	var colored bytes.Buffer
+	DisplayProfileHeading(&colored, "work", Options{Color: ColorConfig{Enabled: true}})
+	if !strings.Contains(colored.String(), colorBold+"Profile: work"+colorReset) {
+		t.Errorf("expected a bold heading, got %q", colored.String())
+	}
+}
@@ -0,0 +1,82 @@
+package display
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/uesteibar/ccstats/internal/quota"
+)
+
+// LineStyle selects the escape syntax used to color compact one-line output.
+type LineStyle string
+
+const (
+	// LineStylePlain emits no color codes.
+	LineStylePlain LineStyle = "plain"
+	// LineStyleANSI emits raw ANSI escape sequences.
+	LineStyleANSI LineStyle = "ansi"
+	// LineStyleTmux emits tmux status-line style directives (#[fg=...]).
+	LineStyleTmux LineStyle = "tmux"
+	// LineStyleZsh emits zsh prompt escapes (%F{...}).
+	LineStyleZsh LineStyle = "zsh"
+	// LineStylePolybar emits polybar format tags (%{F#...}).
+	LineStylePolybar LineStyle = "polybar"
+)
+
+// lineSeparator is placed between windows in one-line output.
+const lineSeparator = " · "
+
+// ParseLineStyle validates a line style name.
+func ParseLineStyle(s string) (LineStyle, error) {
+	switch style := LineStyle(strings.ToLower(strings.TrimSpace(s))); style {
+	case LineStylePlain, LineStyleANSI, LineStyleTmux, LineStyleZsh, LineStylePolybar:
+		return style, nil
+	default:
+		return "", fmt.Errorf("unknown line style %q (want plain, ansi, tmux, zsh or polybar)", s)
+	}
+}
+
+// FormatLine renders the given windows as a terse single line such as
+// "5h 40% · 7d 70% · codex-5h 20%", colored with the same thresholds as
+// the progress bars.
+func FormatLine(windows []quota.Window, style LineStyle) string {
+	segments := make([]string, 0, len(windows))
+	for _, window := range windows {
+		segments = append(segments, formatLineSegment(window, style))
+	}
+	return strings.Join(segments, lineSeparator)
+}
+
+func formatLineSegment(window quota.Window, style LineStyle) string {
+	utilization := window.Metric.Utilization
+	if utilization < 0 {
+		utilization = 0
+	}
+	text := fmt.Sprintf("%s %d%%", window.Key, int(utilization*100))
+	name := levelColorName(LevelFor(utilization))
+
+	switch style {
+	case LineStyleANSI:
+		return getColorForUtilization(utilization) + text + colorReset
+	case LineStyleTmux:
+		return "#[fg=" + name + "]" + strings.ReplaceAll(text, "#", "##") + "#[fg=default]"
+	case LineStyleZsh:
+		return "%F{" + name + "}" + strings.ReplaceAll(text, "%", "%%") + "%f"
+	case LineStylePolybar:
+		return "%{F" + levelHexColor(LevelFor(utilization)) + "}" + text + "%{F-}"
+	default:
+		return text
+	}
+}
+
+// levelColorName returns the color name understood by tmux and zsh for a level.
+func levelColorName(level Level) string {
+	switch level {
+	case LevelHigh:
+		return "red"
+	case LevelMedium:
+		return "yellow"
+	default:
+		return "green"
+	}
+}
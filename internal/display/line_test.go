@@ -0,0 +1,60 @@
+package display
+
+import (
+	"testing"
+
+	"github.com/uesteibar/ccstats/internal/api"
+	"github.com/uesteibar/ccstats/internal/quota"
+)
+
+func TestFormatLine(t *testing.T) {
+	windows := []quota.Window{
+		{Key: "5h", Metric: api.UsageMetric{Utilization: 0.4}},
+		{Key: "7d", Metric: api.UsageMetric{Utilization: 0.6}},
+		{Key: "codex-5h", Metric: api.UsageMetric{Utilization: 0.9}},
+	}
+
+	tests := []struct {
+		style LineStyle
+		want  string
+	}{
+		{
+			style: LineStylePlain,
+			want:  "5h 40% · 7d 60% · codex-5h 90%",
+		},
+		{
+			style: LineStyleANSI,
+			want:  "\033[32m5h 40%\033[0m · \033[33m7d 60%\033[0m · \033[31mcodex-5h 90%\033[0m",
+		},
+		{
+			style: LineStyleTmux,
+			want:  "#[fg=green]5h 40%#[fg=default] · #[fg=yellow]7d 60%#[fg=default] · #[fg=red]codex-5h 90%#[fg=default]",
+		},
+		{
+			style: LineStyleZsh,
+			want:  "%F{green}5h 40%%%f · %F{yellow}7d 60%%%f · %F{red}codex-5h 90%%%f",
+		},
+		{
+			style: LineStylePolybar,
+			want:  "%{F#00FF00}5h 40%%{F-} · %{F#FFFF00}7d 60%%{F-} · %{F#FF0000}codex-5h 90%%{F-}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.style), func(t *testing.T) {
+			got := FormatLine(windows, tt.style)
+			if got != tt.want {
+				t.Errorf("FormatLine(%s) = %q, want %q", tt.style, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseLineStyle(t *testing.T) {
+	if style, err := ParseLineStyle("TMUX"); err != nil || style != LineStyleTmux {
+		t.Errorf("ParseLineStyle(TMUX) = %q, %v", style, err)
+	}
+	if _, err := ParseLineStyle("fish"); err == nil {
+		t.Error("expected error for unknown style")
+	}
+}
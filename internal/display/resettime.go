@@ -0,0 +1,162 @@
+package display
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// TimeMode selects how reset times are shown.
+type TimeMode string
+
+const (
+	// TimeRelative shows "resets in 3d 5h".
+	TimeRelative TimeMode = "relative"
+	// TimeAbsolute shows "resets Thu 14:30 CEST".
+	TimeAbsolute TimeMode = "absolute"
+	// TimeBoth shows "resets in 3d 5h (Thu 14:30 CEST)".
+	TimeBoth TimeMode = "both"
+)
+
+// twelveHourRegions lists locale regions that conventionally use a 12-hour clock.
+var twelveHourRegions = map[string]bool{
+	"US": true,
+	"CA": true,
+	"AU": true,
+	"NZ": true,
+	"PH": true,
+	"IN": true,
+	"PK": true,
+	"EG": true,
+}
+
+// TimeConfig controls how reset times are formatted.
+type TimeConfig struct {
+	Mode TimeMode
+	// Location is the timezone for absolute times. Nil uses time.Local,
+	// which honors $TZ.
+	Location *time.Location
+	Clock24  bool
+}
+
+// Zone returns the configured timezone, or time.Local when none is set.
+func (c TimeConfig) Zone() *time.Location {
+	if c.Location == nil {
+		return time.Local
+	}
+	return c.Location
+}
+
+// DefaultTimeConfig returns relative reset times with the clock preference
+// derived from the locale environment.
+func DefaultTimeConfig() TimeConfig {
+	return TimeConfig{
+		Mode:    TimeRelative,
+		Clock24: localeUses24HourClock(),
+	}
+}
+
+// ParseTimeConfig builds a TimeConfig from user settings. Empty values keep
+// the defaults: relative mode, the local timezone and the locale's clock.
+func ParseTimeConfig(mode string, timezone string, clock string) (TimeConfig, error) {
+	cfg := DefaultTimeConfig()
+
+	switch m := TimeMode(strings.ToLower(strings.TrimSpace(mode))); m {
+	case "":
+	case TimeRelative, TimeAbsolute, TimeBoth:
+		cfg.Mode = m
+	default:
+		return cfg, fmt.Errorf("unknown reset time mode %q (want relative, absolute or both)", mode)
+	}
+
+	if timezone != "" {
+		loc, err := time.LoadLocation(timezone)
+		if err != nil {
+			return cfg, fmt.Errorf("unknown timezone %q: %w", timezone, err)
+		}
+		cfg.Location = loc
+	}
+
+	switch strings.ToLower(strings.TrimSpace(clock)) {
+	case "":
+	case "24h", "24":
+		cfg.Clock24 = true
+	case "12h", "12":
+		cfg.Clock24 = false
+	default:
+		return cfg, fmt.Errorf("unknown clock %q (want 12h or 24h)", clock)
+	}
+
+	return cfg, nil
+}
+
+// FormatResetTime formats a reset time according to cfg.
+func FormatResetTime(resetAt time.Time, now time.Time, cfg TimeConfig) string {
+	if resetAt.IsZero() {
+		return ""
+	}
+
+	relative := FormatRelativeTimeFrom(resetAt, now)
+	if !resetAt.After(now) {
+		return relative
+	}
+
+	switch cfg.Mode {
+	case TimeAbsolute:
+		return "resets " + FormatAbsoluteTime(resetAt, now, cfg)
+	case TimeBoth:
+		return relative + " (" + FormatAbsoluteTime(resetAt, now, cfg) + ")"
+	default:
+		return relative
+	}
+}
+
+// FormatAbsoluteTime formats t as wall-clock time in the configured
+// timezone, e.g. "14:30 CEST" today, "Thu 14:30 CEST" within the week and
+// "Jan 23 14:30 CET" further out.
+func FormatAbsoluteTime(t time.Time, now time.Time, cfg TimeConfig) string {
+	loc := cfg.Zone()
+	t = t.In(loc)
+	now = now.In(loc)
+
+	clock := "3:04 PM"
+	if cfg.Clock24 {
+		clock = "15:04"
+	}
+
+	ty, tm, td := t.Date()
+	ny, nm, nd := now.Date()
+	switch {
+	case ty == ny && tm == nm && td == nd:
+		return t.Format(clock + " MST")
+	case t.Sub(now) < 6*24*time.Hour:
+		return t.Format("Mon " + clock + " MST")
+	default:
+		return t.Format("Jan 2 " + clock + " MST")
+	}
+}
+
+// localeUses24HourClock inspects LC_ALL, LC_TIME and LANG (in that order of
+// precedence) and reports whether the locale's region uses a 24-hour clock.
+func localeUses24HourClock() bool {
+	for _, key := range []string{"LC_ALL", "LC_TIME", "LANG"} {
+		value := os.Getenv(key)
+		if value == "" {
+			continue
+		}
+		return !twelveHourRegions[localeRegion(value)]
+	}
+	return true
+}
+
+// localeRegion extracts the region from a locale such as "en_US.UTF-8".
+func localeRegion(locale string) string {
+	if i := strings.IndexAny(locale, ".@"); i >= 0 {
+		locale = locale[:i]
+	}
+	if i := strings.IndexAny(locale, "_-"); i >= 0 {
+		return strings.ToUpper(locale[i+1:])
+	}
+	return ""
+}
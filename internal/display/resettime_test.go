@@ -0,0 +1,136 @@
+package display
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatResetTime(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+	// Friday 12:00 UTC, 13:00 CET
+	now := time.Date(2026, 1, 16, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		resetAt time.Time
+		cfg     TimeConfig
+		want    string
+	}{
+		{
+			name:    "relative",
+			resetAt: now.Add(3*24*time.Hour + 5*time.Hour),
+			cfg:     TimeConfig{Mode: TimeRelative, Location: berlin, Clock24: true},
+			want:    "resets in 3d 5h",
+		},
+		{
+			name:    "absolute same day",
+			resetAt: now.Add(2*time.Hour + 30*time.Minute),
+			cfg:     TimeConfig{Mode: TimeAbsolute, Location: berlin, Clock24: true},
+			want:    "resets 15:30 CET",
+		},
+		{
+			name:    "absolute later this week",
+			resetAt: now.Add(3*24*time.Hour + 5*time.Hour),
+			cfg:     TimeConfig{Mode: TimeAbsolute, Location: berlin, Clock24: true},
+			want:    "resets Mon 18:00 CET",
+		},
+		{
+			name:    "absolute 12-hour clock",
+			resetAt: now.Add(3*24*time.Hour + 5*time.Hour),
+			cfg:     TimeConfig{Mode: TimeAbsolute, Location: berlin, Clock24: false},
+			want:    "resets Mon 6:00 PM CET",
+		},
+		{
+			name:    "absolute beyond a week",
+			resetAt: now.Add(7 * 24 * time.Hour),
+			cfg:     TimeConfig{Mode: TimeAbsolute, Location: time.UTC, Clock24: true},
+			want:    "resets Jan 23 12:00 UTC",
+		},
+		{
+			name:    "both",
+			resetAt: now.Add(2*time.Hour + 15*time.Minute),
+			cfg:     TimeConfig{Mode: TimeBoth, Location: berlin, Clock24: true},
+			want:    "resets in 2h 15m (15:15 CET)",
+		},
+		{
+			name:    "past reset",
+			resetAt: now.Add(-time.Hour),
+			cfg:     TimeConfig{Mode: TimeBoth, Location: berlin, Clock24: true},
+			want:    "resets now",
+		},
+		{
+			name:    "zero time",
+			resetAt: time.Time{},
+			cfg:     TimeConfig{Mode: TimeAbsolute},
+			want:    "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FormatResetTime(tt.resetAt, now, tt.cfg)
+			if got != tt.want {
+				t.Errorf("FormatResetTime() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTimeConfig(t *testing.T) {
+	cfg, err := ParseTimeConfig("Both", "UTC", "12h")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Mode != TimeBoth || cfg.Location != time.UTC || cfg.Clock24 {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+
+	for _, invalid := range [][3]string{
+		{"sometimes", "", ""},
+		{"", "Mars/Olympus", ""},
+		{"", "", "10h"},
+	} {
+		if _, err := ParseTimeConfig(invalid[0], invalid[1], invalid[2]); err == nil {
+			t.Errorf("expected error for %v", invalid)
+		}
+	}
+}
+
+func TestLocaleUses24HourClock(t *testing.T) {
+	tests := []struct {
+		lcAll, lcTime, lang string
+		want                bool
+	}{
+		{"", "", "en_US.UTF-8", false},
+		{"", "", "de_DE.UTF-8", true},
+		{"", "en_GB.UTF-8", "en_US.UTF-8", true},
+		{"en_US", "de_DE", "de_DE", false},
+		{"", "", "", true},
+		{"", "", "C", true},
+	}
+
+	for _, tt := range tests {
+		t.Setenv("LC_ALL", tt.lcAll)
+		t.Setenv("LC_TIME", tt.lcTime)
+		t.Setenv("LANG", tt.lang)
+		if got := localeUses24HourClock(); got != tt.want {
+			t.Errorf("LC_ALL=%q LC_TIME=%q LANG=%q: got %v, want %v", tt.lcAll, tt.lcTime, tt.lang, got, tt.want)
+		}
+	}
+}
+
+func TestTimeConfig_Zone(t *testing.T) {
+	if got := (TimeConfig{}).Zone(); got != time.Local {
+		t.Errorf("expected time.Local without a timezone, got %v", got)
+	}
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatalf("failed to load timezone: %v", err)
+	}
+	if got := (TimeConfig{Location: tokyo}).Zone(); got != tokyo {
+		t.Errorf("expected Asia/Tokyo, got %v", got)
+	}
+}
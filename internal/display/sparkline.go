@@ -0,0 +1,51 @@
+package display
+
+import (
+	"math"
+	"strings"
+
+	"github.com/uesteibar/ccstats/internal/quota"
+)
+
+// SparklineWidth is the number of values a sparkline is drawn with.
+const SparklineWidth = 12
+
+var (
+	sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+	sparkASCII  = []rune("_.-~=+*#")
+)
+
+// sparkline draws values between 0 and 1 as one character each, blank
+// where a value is NaN. It returns "" when no value is known.
+func sparkline(values []float64, style BarStyle) string {
+	levels := sparkBlocks
+	if style == BarASCII {
+		levels = sparkASCII
+	}
+
+	var b strings.Builder
+	known := false
+	for _, v := range values {
+		if math.IsNaN(v) {
+			b.WriteByte(' ')
+			continue
+		}
+		known = true
+		v = min(max(v, 0), 1)
+		b.WriteRune(levels[int(math.Round(v*float64(len(levels)-1)))])
+	}
+	if !known {
+		return ""
+	}
+	return b.String()
+}
+
+// hasTrends reports whether any of windows has a trend to draw.
+func hasTrends(windows []quota.Window, opts Options) bool {
+	for _, w := range windows {
+		if sparkline(opts.Trends[w.Key], opts.Bar) != "" {
+			return true
+		}
+	}
+	return false
+}
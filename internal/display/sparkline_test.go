@@ -0,0 +1,78 @@
+package display
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSparkline(t *testing.T) {
+	nan := math.NaN()
+	tests := []struct {
+		name   string
+		values []float64
+		style  BarStyle
+		want   string
+	}{
+		{name: "levels", values: []float64{0, 0.5, 1}, want: "▁▅█"},
+		{name: "clamped", values: []float64{-0.2, 1.4}, want: "▁█"},
+		{name: "gaps", values: []float64{nan, 0.3, nan}, want: " ▃ "},
+		{name: "ascii", values: []float64{0, 0.5, 1}, style: BarASCII, want: "_=#"},
+		{name: "unknown", values: []float64{nan, nan}, want: ""},
+		{name: "empty", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sparkline(tt.values, tt.style); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestDisplayAll_Trends(t *testing.T) {
+	now := time.Date(2026, 1, 16, 12, 0, 0, 0, time.UTC)
+	usage, codexUsage := statusbarFixture(now)
+	trend := []float64{0, 0.25, 0.5, 0.75, 1}
+
+	t.Run("drawn between bar and reset time", func(t *testing.T) {
+		var buf bytes.Buffer
+		DisplayAll(&buf, usage, codexUsage, now, Options{Width: 100, Trends: map[string][]float64{"5h": trend}})
+
+		var line string
+		for _, l := range strings.Split(buf.String(), "\n") {
+			if strings.HasPrefix(l, "5-hour") {
+				line = l
+				break
+			}
+		}
+		if !strings.Contains(line, "%  ▁▃▅▆█") {
+			t.Errorf("expected a sparkline after the bar, got %q", line)
+		}
+		for _, l := range strings.Split(strings.TrimRight(buf.String(), "\n"), "\n") {
+			if w := visibleWidth(l); w > 100 {
+				t.Errorf("line exceeds 100 columns (%d): %q", w, l)
+			}
+		}
+	})
+
+	t.Run("omitted without history", func(t *testing.T) {
+		var with, without bytes.Buffer
+		DisplayAll(&with, usage, codexUsage, now, Options{Width: 100, Trends: map[string][]float64{"5h": {math.NaN()}}})
+		DisplayAll(&without, usage, codexUsage, now, Options{Width: 100})
+		if with.String() != without.String() {
+			t.Errorf("expected no trend column:\n%s\nvs\n%s", with.String(), without.String())
+		}
+	})
+
+	t.Run("omitted in the condensed layout", func(t *testing.T) {
+		var buf bytes.Buffer
+		DisplayAll(&buf, usage, nil, now, Options{Width: 40, Trends: map[string][]float64{"5h": trend}})
+		if strings.Contains(buf.String(), "▁") {
+			t.Errorf("expected no sparkline, got:\n%s", buf.String())
+		}
+	})
+}
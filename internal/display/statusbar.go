@@ -0,0 +1,167 @@
+package display
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/uesteibar/ccstats/internal/api"
+	"github.com/uesteibar/ccstats/internal/codex"
+	"github.com/uesteibar/ccstats/internal/quota"
+)
+
+// Hex colors for each threshold level, used by status bar integrations.
+const (
+	hexColorLow    = "#00FF00"
+	hexColorMedium = "#FFFF00"
+	hexColorHigh   = "#FF0000"
+)
+
+// WaybarOutput is a single update for a Waybar custom module with
+// "return-type": "json".
+type WaybarOutput struct {
+	Text       string `json:"text"`
+	Tooltip    string `json:"tooltip"`
+	Class      string `json:"class"`
+	Percentage int    `json:"percentage"`
+}
+
+// FormatWaybar builds a Waybar update. The text shows the given windows,
+// while the tooltip lists every Claude and Codex window with reset times.
+// Class and percentage follow the most utilized of the shown windows.
+func FormatWaybar(windows []quota.Window, usage *api.UsageResponse, codexUsage *codex.Usage, now time.Time) WaybarOutput {
+	highest := highestUtilization(windows)
+	return WaybarOutput{
+		Text:       FormatLine(windows, LineStylePlain),
+		Tooltip:    FormatTooltip(usage, codexUsage, now),
+		Class:      LevelFor(highest).String(),
+		Percentage: int(highest * 100),
+	}
+}
+
+// FormatTooltip lists every window of both providers, one per line, grouped
+// under a header per provider.
+func FormatTooltip(usage *api.UsageResponse, codexUsage *codex.Usage, now time.Time) string {
+	var lines []string
+
+	if usage != nil {
+		lines = append(lines, "Claude Code")
+		for _, window := range quota.ClaudeWindows(usage) {
+			lines = append(lines, formatTooltipWindow(window, now))
+		}
+	}
+
+	if codexUsage != nil {
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, fmt.Sprintf("Codex (Plan: %s)", codexUsage.Plan.String()))
+		windows := quota.CodexWindows(codexUsage)
+		if len(windows) == 0 {
+			lines = append(lines, "No rate-limit data available")
+		}
+		for _, window := range windows {
+			lines = append(lines, formatTooltipWindow(window, now))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func formatTooltipWindow(window quota.Window, now time.Time) string {
+	line := fmt.Sprintf("%-12s %3d%%", window.Label, int(clampUtilization(window.Metric.Utilization)*100))
+	if reset := FormatRelativeTimeFrom(window.Metric.ResetAt, now); reset != "" {
+		line += "  " + reset
+	}
+	return line
+}
+
+// I3barBlock is a single block of the i3bar protocol.
+type I3barBlock struct {
+	FullText  string `json:"full_text"`
+	ShortText string `json:"short_text,omitempty"`
+	Color     string `json:"color,omitempty"`
+	Name      string `json:"name"`
+	Instance  string `json:"instance,omitempty"`
+}
+
+// I3barBlocks returns one block per window, colored by threshold level.
+func I3barBlocks(windows []quota.Window) []I3barBlock {
+	blocks := make([]I3barBlock, 0, len(windows))
+	for _, window := range windows {
+		text := FormatLine([]quota.Window{window}, LineStylePlain)
+		blocks = append(blocks, I3barBlock{
+			FullText:  text,
+			ShortText: fmt.Sprintf("%d%%", int(clampUtilization(window.Metric.Utilization)*100)),
+			Color:     levelHexColor(LevelFor(window.Metric.Utilization)),
+			Name:      "ccstats",
+			Instance:  window.Key,
+		})
+	}
+	return blocks
+}
+
+// levelHexColor returns the hex color for a threshold level.
+func levelHexColor(level Level) string {
+	switch level {
+	case LevelHigh:
+		return hexColorHigh
+	case LevelMedium:
+		return hexColorMedium
+	default:
+		return hexColorLow
+	}
+}
+
+// I3barWriter streams status lines using the i3bar protocol: a version
+// header followed by an endless JSON array of block arrays.
+type I3barWriter struct {
+	w       io.Writer
+	started bool
+}
+
+// NewI3barWriter returns a writer that emits the protocol header before the
+// first status line.
+func NewI3barWriter(w io.Writer) *I3barWriter {
+	return &I3barWriter{w: w}
+}
+
+// Write emits one status line.
+func (iw *I3barWriter) Write(blocks []I3barBlock) error {
+	payload, err := json.Marshal(blocks)
+	if err != nil {
+		return err
+	}
+
+	prefix := ","
+	if !iw.started {
+		prefix = "{\"version\":1}\n[\n"
+		iw.started = true
+	}
+
+	_, err = fmt.Fprintf(iw.w, "%s%s\n", prefix, payload)
+	return err
+}
+
+func highestUtilization(windows []quota.Window) float64 {
+	highest := 0.0
+	for _, window := range windows {
+		if u := clampUtilization(window.Metric.Utilization); u > highest {
+			highest = u
+		}
+	}
+	return highest
+}
+
+// clampUtilization limits utilization to the 0.0-1.0 range.
+func clampUtilization(utilization float64) float64 {
+	if utilization < 0 {
+		return 0
+	}
+	if utilization > 1 {
+		return 1
+	}
+	return utilization
+}
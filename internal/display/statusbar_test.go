@@ -0,0 +1,91 @@
+package display
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/uesteibar/ccstats/internal/api"
+	"github.com/uesteibar/ccstats/internal/codex"
+	"github.com/uesteibar/ccstats/internal/quota"
+)
+
+func statusbarFixture(now time.Time) (*api.UsageResponse, *codex.Usage) {
+	usage := &api.UsageResponse{
+		FiveHour:       api.UsageMetric{Utilization: 0.4, ResetAt: now.Add(2*time.Hour + 15*time.Minute)},
+		SevenDay:       api.UsageMetric{Utilization: 0.85, ResetAt: now.Add(3*24*time.Hour + 5*time.Hour)},
+		SevenDaySonnet: api.UsageMetric{Utilization: 0.1, ResetAt: now.Add(3*24*time.Hour + 5*time.Hour)},
+	}
+	codexUsage := &codex.Usage{
+		Plan:    codex.PlanPlus,
+		Primary: &codex.UsageWindow{WindowDurationMins: 300, Utilization: 0.2, ResetAt: now.Add(2*time.Hour + 10*time.Minute)},
+	}
+	return usage, codexUsage
+}
+
+func TestFormatWaybar(t *testing.T) {
+	now := time.Date(2026, 1, 16, 12, 0, 0, 0, time.UTC)
+	usage, codexUsage := statusbarFixture(now)
+	windows := quota.FilterWindows(quota.Windows(usage, codexUsage), []string{"5h", "7d"})
+
+	out := FormatWaybar(windows, usage, codexUsage, now)
+
+	if out.Text != "5h 40% · 7d 85%" {
+		t.Errorf("unexpected text %q", out.Text)
+	}
+	if out.Class != "high" {
+		t.Errorf("expected class high, got %q", out.Class)
+	}
+	if out.Percentage != 85 {
+		t.Errorf("expected percentage 85, got %d", out.Percentage)
+	}
+
+	wantTooltip := strings.Join([]string{
+		"Claude Code",
+		"5-hour        40%  resets in 2h 15m",
+		"7-day         85%  resets in 3d 5h",
+		"7-day Sonnet  10%  resets in 3d 5h",
+		"",
+		"Codex (Plan: Plus)",
+		"5-hour        20%  resets in 2h 10m",
+	}, "\n")
+	if out.Tooltip != wantTooltip {
+		t.Errorf("unexpected tooltip:\n%s\nwant:\n%s", out.Tooltip, wantTooltip)
+	}
+
+	payload, err := json.Marshal(out)
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+	for _, key := range []string{`"text"`, `"tooltip"`, `"class"`, `"percentage"`} {
+		if !bytes.Contains(payload, []byte(key)) {
+			t.Errorf("expected %s in JSON output %s", key, payload)
+		}
+	}
+}
+
+func TestI3barWriter(t *testing.T) {
+	windows := []quota.Window{
+		{Key: "5h", Metric: api.UsageMetric{Utilization: 0.3}},
+		{Key: "7d", Metric: api.UsageMetric{Utilization: 0.6}},
+	}
+
+	var buf bytes.Buffer
+	writer := NewI3barWriter(&buf)
+	if err := writer.Write(I3barBlocks(windows)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := writer.Write(I3barBlocks(windows[:1])); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "{\"version\":1}\n[\n" +
+		`[{"full_text":"5h 30%","short_text":"30%","color":"#00FF00","name":"ccstats","instance":"5h"},` +
+		`{"full_text":"7d 60%","short_text":"60%","color":"#FFFF00","name":"ccstats","instance":"7d"}]` + "\n" +
+		`,[{"full_text":"5h 30%","short_text":"30%","color":"#00FF00","name":"ccstats","instance":"5h"}]` + "\n"
+	if buf.String() != want {
+		t.Errorf("unexpected i3bar stream:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
@@ -0,0 +1,65 @@
+package display
+
+import (
+	"fmt"
+	"strings"
+)
+
+// formatTable lays out rows in columns separated by two spaces. The first
+// row is the header and is followed by a rule; numeric columns are right
+// aligned. A row of nil is replaced by a rule.
+func formatTable(rows [][]string, numeric []bool, rule string) []string {
+	widths := make([]int, len(rows[0]))
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], visibleWidth(cell))
+		}
+	}
+
+	total := 0
+	for _, width := range widths {
+		total += width + 2
+	}
+	ruleLine := strings.Repeat(rule, total-2)
+
+	lines := make([]string, 0, len(rows)+1)
+	for i, row := range rows {
+		if row == nil {
+			lines = append(lines, ruleLine)
+			continue
+		}
+
+		cells := make([]string, len(row))
+		for j, cell := range row {
+			pad := strings.Repeat(" ", widths[j]-visibleWidth(cell))
+			if j < len(numeric) && numeric[j] {
+				cells[j] = pad + cell
+			} else {
+				cells[j] = cell + pad
+			}
+		}
+		lines = append(lines, strings.TrimRight(strings.Join(cells, "  "), " "))
+
+		if i == 0 {
+			lines = append(lines, ruleLine)
+		}
+	}
+	return lines
+}
+
+// formatCount formats n with thousands separators, e.g. "1,234,567".
+func formatCount(n int64) string {
+	if n < 0 {
+		return "-" + formatCount(-n)
+	}
+	s := fmt.Sprintf("%d", n)
+
+	var b strings.Builder
+	for i, r := range s {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
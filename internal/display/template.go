@@ -0,0 +1,106 @@
+package display
+
+import (
+	"fmt"
+	"text/template"
+	"time"
+
+	"github.com/uesteibar/ccstats/internal/api"
+	"github.com/uesteibar/ccstats/internal/codex"
+	"github.com/uesteibar/ccstats/internal/quota"
+)
+
+// absoluteTimeLayout returns the layout used by the absolute template
+// helper for the configured clock.
+func absoluteTimeLayout(cfg TimeConfig) string {
+	if cfg.Clock24 {
+		return "Mon 15:04 MST"
+	}
+	return "Mon 3:04 PM MST"
+}
+
+// TemplateData is the value passed to user templates.
+type TemplateData struct {
+	Now     time.Time
+	Claude  *api.UsageResponse
+	Codex   *codex.Usage
+	Windows []quota.Window
+}
+
+// NewTemplateData bundles usage from both providers for template rendering.
+func NewTemplateData(usage *api.UsageResponse, codexUsage *codex.Usage, now time.Time) TemplateData {
+	return TemplateData{
+		Now:     now,
+		Claude:  usage,
+		Codex:   codexUsage,
+		Windows: quota.Windows(usage, codexUsage),
+	}
+}
+
+// NewTemplate parses a user-supplied text/template with the ccstats helper
+// functions available:
+//
+//	percent   utilization as an integer percentage: {{percent .Claude.FiveHour.Utilization}}
+//	bar       progress bar, colored when enabled: {{bar .Claude.SevenDay.Utilization}}
+//	relative  relative reset time: {{relative .Claude.FiveHour.ResetAt}}
+//	absolute  reset time in the configured or a given timezone: {{absolute .Claude.SevenDay.ResetAt "Europe/Berlin"}}
+//	color     colors text by utilization: {{color .Claude.FiveHour.Utilization "5h"}}
+//	level     threshold level name (low, medium, high): {{level .Claude.FiveHour.Utilization}}
+//	plan      human-readable Codex plan name: {{plan .Codex.Plan}}
+//	window    looks up a window by key: {{with window . "codex-5h"}}{{.Label}}{{end}}
+//
+// timeCfg sets the timezone and clock of absolute.
+func NewTemplate(text string, now time.Time, colorCfg ColorConfig, timeCfg TimeConfig) (*template.Template, error) {
+	tmpl, err := template.New("ccstats").Funcs(templateFuncs(now, colorCfg, timeCfg)).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template: %w", err)
+	}
+	return tmpl, nil
+}
+
+func templateFuncs(now time.Time, colorCfg ColorConfig, timeCfg TimeConfig) template.FuncMap {
+	return template.FuncMap{
+		"percent": func(utilization float64) int {
+			return int(clampUtilization(utilization) * 100)
+		},
+		"bar": func(utilization float64) string {
+			return FormatProgressBarWithColor(utilization, colorCfg)
+		},
+		"relative": func(resetAt time.Time) string {
+			return FormatRelativeTimeFrom(resetAt, now)
+		},
+		"absolute": func(resetAt time.Time, timezone ...string) (string, error) {
+			if resetAt.IsZero() {
+				return "", nil
+			}
+			loc := timeCfg.Location
+			if loc == nil {
+				loc = time.Local
+			}
+			if len(timezone) > 0 {
+				var err error
+				if loc, err = time.LoadLocation(timezone[0]); err != nil {
+					return "", err
+				}
+			}
+			return resetAt.In(loc).Format(absoluteTimeLayout(timeCfg)), nil
+		},
+		"color": func(utilization float64, text string) string {
+			return colorCfg.colorize(utilization, text)
+		},
+		"level": func(utilization float64) string {
+			return LevelFor(utilization).String()
+		},
+		"plan": func(plan codex.Plan) string {
+			return plan.String()
+		},
+		"window": func(data TemplateData, key string) *quota.Window {
+			for _, window := range data.Windows {
+				if window.Key == key {
+					return &window
+				}
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,108 @@
+package display
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestNewTemplate(t *testing.T) {
+	now := time.Date(2026, 1, 16, 12, 0, 0, 0, time.UTC)
+	usage, codexUsage := statusbarFixture(now)
+	data := NewTemplateData(usage, codexUsage, now)
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("failed to load timezone: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		text     string
+		colorCfg ColorConfig
+		timeCfg  TimeConfig
+		want     string
+	}{
+		{
+			name: "percent and relative",
+			text: `5h={{percent .Claude.FiveHour.Utilization}}% {{relative .Claude.FiveHour.ResetAt}}`,
+			want: "5h=40% resets in 2h 15m",
+		},
+		{
+			name: "bar",
+			text: `{{bar .Claude.SevenDay.Utilization}}`,
+			want: "[█████████████████░░░]  85%",
+		},
+		{
+			name:     "colored bar",
+			text:     `{{bar .Claude.FiveHour.Utilization}}`,
+			colorCfg: ColorConfig{Enabled: true},
+			want:     "\033[32m[████████░░░░░░░░░░░░]  40%\033[0m",
+		},
+		{
+			name:    "absolute in timezone",
+			text:    `{{absolute .Claude.FiveHour.ResetAt "Europe/Berlin"}}`,
+			timeCfg: TimeConfig{Clock24: true},
+			want:    "Fri 15:15 CET",
+		},
+		{
+			name:    "absolute in configured timezone and clock",
+			text:    `{{absolute .Claude.FiveHour.ResetAt}}`,
+			timeCfg: TimeConfig{Location: newYork},
+			want:    "Fri 9:15 AM EST",
+		},
+		{
+			name:     "color and level",
+			text:     `{{color .Claude.SevenDay.Utilization "7d"}} {{level .Claude.SevenDay.Utilization}}`,
+			colorCfg: ColorConfig{Enabled: true},
+			want:     "\033[31m7d\033[0m high",
+		},
+		{
+			name: "window lookup and plan",
+			text: `{{plan .Codex.Plan}}{{with window . "codex-5h"}} {{.Label}} {{percent .Metric.Utilization}}%{{end}}{{with window . "missing"}}!{{end}}`,
+			want: "Plus 5-hour 20%",
+		},
+		{
+			name: "range over windows",
+			text: `{{range .Windows}}{{.Key}} {{end}}`,
+			want: "5h 7d 7d-sonnet codex-5h ",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := NewTemplate(tt.text, now, tt.colorCfg, tt.timeCfg)
+			if err != nil {
+				t.Fatalf("unexpected parse error: %v", err)
+			}
+
+			var buf bytes.Buffer
+			if err := tmpl.Execute(&buf, data); err != nil {
+				t.Fatalf("unexpected execute error: %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("got %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestNewTemplate_InvalidSyntax(t *testing.T) {
+	if _, err := NewTemplate("{{percent", time.Now(), ColorConfig{}, TimeConfig{}); err == nil {
+		t.Fatal("expected parse error")
+	}
+}
+
+func TestNewTemplate_UnknownTimezone(t *testing.T) {
+	now := time.Date(2026, 1, 16, 12, 0, 0, 0, time.UTC)
+	usage, codexUsage := statusbarFixture(now)
+
+	tmpl, err := NewTemplate(`{{absolute .Claude.FiveHour.ResetAt "Mars/Olympus"}}`, now, ColorConfig{}, TimeConfig{})
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, NewTemplateData(usage, codexUsage, now)); err == nil {
+		t.Fatal("expected error for unknown timezone")
+	}
+}
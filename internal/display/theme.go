@@ -0,0 +1,324 @@
+package display
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ColorDepth is the range of colors a terminal can show.
+type ColorDepth int
+
+const (
+	// Depth16 is the basic ANSI palette.
+	Depth16 ColorDepth = iota
+	// Depth256 is the xterm 256-color palette.
+	Depth256
+	// DepthTrueColor is 24-bit color.
+	DepthTrueColor
+)
+
+// DetectColorDepth inspects COLORTERM and TERM to determine the terminal's
+// color capability.
+func DetectColorDepth(getenv func(string) string) ColorDepth {
+	switch strings.ToLower(getenv("COLORTERM")) {
+	case "truecolor", "24bit":
+		return DepthTrueColor
+	}
+
+	term := strings.ToLower(getenv("TERM"))
+	switch {
+	case strings.HasSuffix(term, "-direct"):
+		return DepthTrueColor
+	case strings.Contains(term, "256color"):
+		return Depth256
+	default:
+		return Depth16
+	}
+}
+
+// RGB is a 24-bit color.
+type RGB struct {
+	R, G, B uint8
+}
+
+// Color is a theme color. Terminals with 256 or more colors use RGB when it
+// is set; everything else uses the ANSI SGR parameters.
+type Color struct {
+	// ANSI holds SGR parameters such as "32" or "1;91". Empty means no styling.
+	ANSI string
+	RGB  *RGB
+	// Bold adds bold text when RGB is used.
+	Bold bool
+}
+
+// ansiPalette maps the basic SGR foreground codes to representative RGB
+// values, used for gradients and for picking fallbacks for hex colors.
+var ansiPalette = map[string]RGB{
+	"30": {0, 0, 0},
+	"31": {205, 49, 49},
+	"32": {13, 188, 121},
+	"33": {229, 229, 16},
+	"34": {36, 114, 200},
+	"35": {188, 63, 188},
+	"36": {17, 168, 205},
+	"37": {229, 229, 229},
+	"90": {102, 102, 102},
+	"91": {241, 76, 76},
+	"92": {35, 209, 139},
+	"93": {245, 245, 67},
+	"94": {59, 142, 234},
+	"95": {214, 112, 214},
+	"96": {41, 184, 219},
+	"97": {255, 255, 255},
+}
+
+// ansiNames maps color names accepted in user themes to SGR codes.
+var ansiNames = map[string]string{
+	"black":          "30",
+	"red":            "31",
+	"green":          "32",
+	"yellow":         "33",
+	"blue":           "34",
+	"magenta":        "35",
+	"cyan":           "36",
+	"white":          "37",
+	"bright-black":   "90",
+	"bright-red":     "91",
+	"bright-green":   "92",
+	"bright-yellow":  "93",
+	"bright-blue":    "94",
+	"bright-magenta": "95",
+	"bright-cyan":    "96",
+	"bright-white":   "97",
+}
+
+// ParseColor parses a user color: a hex value such as "#d55e00" or a basic
+// ANSI color name such as "green" or "bright-red".
+func ParseColor(s string) (Color, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+
+	if strings.HasPrefix(s, "#") {
+		rgb, err := parseHex(s)
+		if err != nil {
+			return Color{}, err
+		}
+		return Color{ANSI: nearestANSI(rgb), RGB: &rgb}, nil
+	}
+
+	if code, ok := ansiNames[s]; ok {
+		return Color{ANSI: code}, nil
+	}
+
+	return Color{}, fmt.Errorf("unknown color %q (want #rrggbb or an ANSI color name)", s)
+}
+
+func parseHex(s string) (RGB, error) {
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) != 6 {
+		return RGB{}, fmt.Errorf("invalid hex color %q", s)
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return RGB{}, fmt.Errorf("invalid hex color %q", s)
+	}
+	return RGB{uint8(v >> 16), uint8(v >> 8), uint8(v)}, nil
+}
+
+// sgr returns the SGR parameters for the color at the given depth.
+func (c Color) sgr(depth ColorDepth) string {
+	if c.RGB == nil || depth == Depth16 {
+		return c.ANSI
+	}
+
+	var params string
+	if depth == DepthTrueColor {
+		params = fmt.Sprintf("38;2;%d;%d;%d", c.RGB.R, c.RGB.G, c.RGB.B)
+	} else {
+		params = fmt.Sprintf("38;5;%d", rgbTo256(*c.RGB))
+	}
+	if c.Bold {
+		params = "1;" + params
+	}
+	return params
+}
+
+// rgb returns the color as RGB, approximating basic ANSI colors.
+func (c Color) rgb() (RGB, bool) {
+	if c.RGB != nil {
+		return *c.RGB, true
+	}
+	params := strings.Split(c.ANSI, ";")
+	rgb, ok := ansiPalette[params[len(params)-1]]
+	return rgb, ok
+}
+
+// escape wraps SGR parameters in an escape sequence.
+func escape(params string) string {
+	if params == "" {
+		return ""
+	}
+	return "\033[" + params + "m"
+}
+
+// rgbTo256 maps an RGB color to the nearest entry of the xterm 6x6x6 color cube.
+func rgbTo256(c RGB) int {
+	level := func(v uint8) int {
+		switch {
+		case v < 48:
+			return 0
+		case v < 115:
+			return 1
+		default:
+			return (int(v) - 35) / 40
+		}
+	}
+	return 16 + 36*level(c.R) + 6*level(c.G) + level(c.B)
+}
+
+// nearestANSI returns the basic SGR code closest to the given color.
+func nearestANSI(c RGB) string {
+	best, bestDist := "", -1
+	codes := make([]string, 0, len(ansiPalette))
+	for code := range ansiPalette {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	for _, code := range codes {
+		p := ansiPalette[code]
+		dr, dg, db := int(c.R)-int(p.R), int(c.G)-int(p.G), int(c.B)-int(p.B)
+		if dist := dr*dr + dg*dg + db*db; bestDist < 0 || dist < bestDist {
+			best, bestDist = code, dist
+		}
+	}
+	return best
+}
+
+// Theme maps utilization levels to colors.
+type Theme struct {
+	Name   string
+	Low    Color
+	Medium Color
+	High   Color
+	// Gradient colors each filled cell of a bar by its position instead of
+	// coloring the whole bar by level. It needs a 256-color terminal.
+	Gradient bool
+}
+
+func rgbPtr(r, g, b uint8) *RGB {
+	return &RGB{r, g, b}
+}
+
+// Built-in themes.
+var (
+	// ThemeDefault is the classic green/yellow/red terminal palette.
+	ThemeDefault = Theme{
+		Name:   "default",
+		Low:    Color{ANSI: "32"},
+		Medium: Color{ANSI: "33"},
+		High:   Color{ANSI: "31"},
+	}
+	// ThemeColorblind uses the Okabe-Ito blue/orange/vermillion colors,
+	// which stay distinguishable with common color vision deficiencies.
+	ThemeColorblind = Theme{
+		Name:   "colorblind",
+		Low:    Color{ANSI: "34", RGB: rgbPtr(0, 114, 178)},
+		Medium: Color{ANSI: "33", RGB: rgbPtr(230, 159, 0)},
+		High:   Color{ANSI: "35", RGB: rgbPtr(213, 94, 0)},
+	}
+	// ThemeMonochrome conveys levels with text attributes only.
+	ThemeMonochrome = Theme{
+		Name:   "monochrome",
+		Low:    Color{},
+		Medium: Color{ANSI: "1"},
+		High:   Color{ANSI: "1;7"},
+	}
+	// ThemeHighContrast uses bold, fully saturated colors.
+	ThemeHighContrast = Theme{
+		Name:   "high-contrast",
+		Low:    Color{ANSI: "1;92", RGB: rgbPtr(0, 255, 0), Bold: true},
+		Medium: Color{ANSI: "1;93", RGB: rgbPtr(255, 255, 0), Bold: true},
+		High:   Color{ANSI: "1;91", RGB: rgbPtr(255, 0, 0), Bold: true},
+	}
+)
+
+var builtinThemes = map[string]Theme{
+	ThemeDefault.Name:      ThemeDefault,
+	ThemeColorblind.Name:   ThemeColorblind,
+	ThemeMonochrome.Name:   ThemeMonochrome,
+	ThemeHighContrast.Name: ThemeHighContrast,
+}
+
+// NewTheme builds a user-defined theme from color strings accepted by ParseColor.
+func NewTheme(name string, low string, medium string, high string, gradient bool) (Theme, error) {
+	theme := Theme{Name: name, Gradient: gradient}
+
+	for _, c := range []struct {
+		level string
+		value string
+		dst   *Color
+	}{
+		{"low", low, &theme.Low},
+		{"medium", medium, &theme.Medium},
+		{"high", high, &theme.High},
+	} {
+		color, err := ParseColor(c.value)
+		if err != nil {
+			return Theme{}, fmt.Errorf("theme %q %s color: %w", name, c.level, err)
+		}
+		*c.dst = color
+	}
+
+	return theme, nil
+}
+
+// LookupTheme returns the named theme, checking custom themes before the
+// built-in ones. An empty name returns ThemeDefault.
+func LookupTheme(name string, custom map[string]Theme) (Theme, error) {
+	if name == "" {
+		return ThemeDefault, nil
+	}
+	if theme, ok := custom[name]; ok {
+		return theme, nil
+	}
+	if theme, ok := builtinThemes[name]; ok {
+		return theme, nil
+	}
+	return Theme{}, fmt.Errorf("unknown theme %q (built-in themes: default, colorblind, monochrome, high-contrast)", name)
+}
+
+func (t Theme) colorFor(level Level) Color {
+	switch level {
+	case LevelHigh:
+		return t.High
+	case LevelMedium:
+		return t.Medium
+	default:
+		return t.Low
+	}
+}
+
+// gradientAt returns the color at position p (0.0-1.0) of a gradient
+// running through the low, medium and high colors.
+func (t Theme) gradientAt(p float64) (RGB, bool) {
+	low, okLow := t.Low.rgb()
+	medium, okMedium := t.Medium.rgb()
+	high, okHigh := t.High.rgb()
+	if !okLow || !okMedium || !okHigh {
+		return RGB{}, false
+	}
+
+	if p <= 0.5 {
+		return lerpRGB(low, medium, p/0.5), true
+	}
+	return lerpRGB(medium, high, (p-0.5)/0.5), true
+}
+
+func lerpRGB(a RGB, b RGB, t float64) RGB {
+	mix := func(x, y uint8) uint8 {
+		return uint8(float64(x) + (float64(y)-float64(x))*t + 0.5)
+	}
+	return RGB{mix(a.R, b.R), mix(a.G, b.G), mix(a.B, b.B)}
+}
@@ -0,0 +1,149 @@
+package display
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDetectColorDepth(t *testing.T) {
+	tests := []struct {
+		colorterm, term string
+		want            ColorDepth
+	}{
+		{"truecolor", "xterm-256color", DepthTrueColor},
+		{"24bit", "", DepthTrueColor},
+		{"", "xterm-direct", DepthTrueColor},
+		{"", "screen-256color", Depth256},
+		{"", "xterm", Depth16},
+		{"", "", Depth16},
+	}
+
+	for _, tt := range tests {
+		env := map[string]string{"COLORTERM": tt.colorterm, "TERM": tt.term}
+		if got := DetectColorDepth(func(k string) string { return env[k] }); got != tt.want {
+			t.Errorf("COLORTERM=%q TERM=%q: got %d, want %d", tt.colorterm, tt.term, got, tt.want)
+		}
+	}
+}
+
+func TestParseColor(t *testing.T) {
+	c, err := ParseColor("#D55E00")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c.RGB == nil || *c.RGB != (RGB{213, 94, 0}) {
+		t.Errorf("unexpected RGB: %+v", c.RGB)
+	}
+	if c.ANSI != "31" {
+		t.Errorf("expected nearest ANSI red fallback, got %q", c.ANSI)
+	}
+
+	c, err = ParseColor("bright-red")
+	if err != nil || c.ANSI != "91" || c.RGB != nil {
+		t.Errorf("ParseColor(bright-red) = %+v, %v", c, err)
+	}
+
+	for _, invalid := range []string{"#12345", "#gggggg", "chartreuse"} {
+		if _, err := ParseColor(invalid); err == nil {
+			t.Errorf("expected error for %q", invalid)
+		}
+	}
+}
+
+func TestColorSGR(t *testing.T) {
+	c := Color{ANSI: "31", RGB: &RGB{213, 94, 0}}
+
+	if got := c.sgr(Depth16); got != "31" {
+		t.Errorf("16-color: got %q", got)
+	}
+	if got := c.sgr(Depth256); got != "38;5;166" {
+		t.Errorf("256-color: got %q", got)
+	}
+	if got := c.sgr(DepthTrueColor); got != "38;2;213;94;0" {
+		t.Errorf("truecolor: got %q", got)
+	}
+
+	bold := Color{ANSI: "1;91", RGB: &RGB{255, 0, 0}, Bold: true}
+	if got := bold.sgr(DepthTrueColor); got != "1;38;2;255;0;0" {
+		t.Errorf("bold truecolor: got %q", got)
+	}
+}
+
+func TestLookupTheme(t *testing.T) {
+	if theme, err := LookupTheme("", nil); err != nil || theme.Name != "default" {
+		t.Errorf("LookupTheme(\"\") = %q, %v", theme.Name, err)
+	}
+	for _, name := range []string{"default", "colorblind", "monochrome", "high-contrast"} {
+		if _, err := LookupTheme(name, nil); err != nil {
+			t.Errorf("expected built-in theme %q: %v", name, err)
+		}
+	}
+
+	custom, err := NewTheme("default", "#00ff00", "yellow", "#ff0000", true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	theme, err := LookupTheme("default", map[string]Theme{"default": custom})
+	if err != nil || !theme.Gradient {
+		t.Errorf("expected custom theme to override built-in, got %+v, %v", theme, err)
+	}
+
+	if _, err := LookupTheme("neon", nil); err == nil {
+		t.Error("expected error for unknown theme")
+	}
+	if _, err := NewTheme("broken", "green", "nope", "red", false); err == nil {
+		t.Error("expected error for invalid theme color")
+	}
+}
+
+func TestFormatProgressBarWithColor_Themes(t *testing.T) {
+	t.Run("colorblind truecolor", func(t *testing.T) {
+		theme := ThemeColorblind
+		got := FormatProgressBarWithColor(0.9, ColorConfig{Enabled: true, Theme: &theme, Depth: DepthTrueColor})
+		want := "\033[38;2;213;94;0m[██████████████████░░]  90%\033[0m"
+		if got != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	})
+
+	t.Run("colorblind falls back to ANSI on 16 colors", func(t *testing.T) {
+		theme := ThemeColorblind
+		got := FormatProgressBarWithColor(0.2, ColorConfig{Enabled: true, Theme: &theme, Depth: Depth16})
+		if !strings.HasPrefix(got, "\033[34m") {
+			t.Errorf("expected blue ANSI prefix, got %q", got)
+		}
+	})
+
+	t.Run("monochrome low has no escapes", func(t *testing.T) {
+		theme := ThemeMonochrome
+		got := FormatProgressBarWithColor(0.2, ColorConfig{Enabled: true, Theme: &theme, Depth: DepthTrueColor})
+		if strings.Contains(got, "\033[") {
+			t.Errorf("expected no escapes, got %q", got)
+		}
+	})
+
+	t.Run("gradient", func(t *testing.T) {
+		theme := ThemeDefault
+		theme.Gradient = true
+		got := FormatProgressBarWithColor(0.5, ColorConfig{Enabled: true, Theme: &theme, Depth: DepthTrueColor})
+
+		if !strings.HasPrefix(got, "[\033[38;2;") {
+			t.Errorf("expected per-cell truecolor escapes inside the bar, got %q", got)
+		}
+		if strings.Count(got, "38;2;") < 5 {
+			t.Errorf("expected the color to change along the bar, got %q", got)
+		}
+		if !strings.HasSuffix(got, "] \033[33m 50%\033[0m") {
+			t.Errorf("expected percentage in level color, got %q", got)
+		}
+	})
+
+	t.Run("gradient needs 256 colors", func(t *testing.T) {
+		theme := ThemeDefault
+		theme.Gradient = true
+		got := FormatProgressBarWithColor(0.5, ColorConfig{Enabled: true, Theme: &theme, Depth: Depth16})
+		if got != "\033[33m[██████████░░░░░░░░░░]  50%\033[0m" {
+			t.Errorf("expected solid fallback, got %q", got)
+		}
+	})
+}
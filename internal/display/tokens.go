@@ -0,0 +1,106 @@
+package display
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/uesteibar/ccstats/internal/transcript"
+)
+
+// sessionIDWidth is how much of a session ID the tokens table shows.
+const sessionIDWidth = 8
+
+// DisplayTokens writes a table of transcript token usage grouped by by,
+// followed by a total row.
+func DisplayTokens(w io.Writer, rows []transcript.Row, by transcript.GroupBy, opts Options) {
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "Claude Code Token Usage by %s\n", groupTitle(by))
+	fmt.Fprintln(w)
+
+	if len(rows) == 0 {
+		fmt.Fprintln(w, "No token usage found for this period.")
+		fmt.Fprintln(w)
+		return
+	}
+
+	header := []string{groupTitle(by)}
+	numeric := []bool{false}
+	if by == transcript.GroupBySession {
+		header = append(header, "Project")
+		numeric = append(numeric, false)
+	}
+	header = append(header, "Messages", "Input", "Output", "Cache write", "Cache read", "Total")
+	numeric = append(numeric, true, true, true, true, true, true)
+
+	table := [][]string{header}
+	for _, row := range rows {
+		table = append(table, tokenCells(row, by, groupKey(row.Key, by)))
+	}
+	table = append(table, nil, tokenCells(transcript.Total(rows), by, "Total"))
+
+	for _, line := range formatTable(table, numeric, opts.Bar.ruleChar()) {
+		fmt.Fprintln(w, line)
+	}
+	fmt.Fprintln(w)
+}
+
+func tokenCells(row transcript.Row, by transcript.GroupBy, key string) []string {
+	cells := []string{key}
+	if by == transcript.GroupBySession {
+		cells = append(cells, shortenPath(row.Project))
+	}
+	return append(cells,
+		formatCount(int64(row.Messages)),
+		formatCount(row.Usage.Input),
+		formatCount(row.Usage.Output),
+		formatCount(row.Usage.CacheCreation),
+		formatCount(row.Usage.CacheRead),
+		formatCount(row.Usage.Total()),
+	)
+}
+
+func groupTitle(by transcript.GroupBy) string {
+	switch by {
+	case transcript.GroupByProject:
+		return "Project"
+	case transcript.GroupBySession:
+		return "Session"
+	case transcript.GroupByModel:
+		return "Model"
+	default:
+		return "Day"
+	}
+}
+
+func groupKey(key string, by transcript.GroupBy) string {
+	switch by {
+	case transcript.GroupByProject:
+		return shortenPath(key)
+	case transcript.GroupBySession:
+		if len(key) > sessionIDWidth {
+			return key[:sessionIDWidth]
+		}
+	}
+	if key == "" {
+		return "(unknown)"
+	}
+	return key
+}
+
+// shortenPath replaces the home directory prefix with "~".
+func shortenPath(path string) string {
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return path
+	}
+	if path == home {
+		return "~"
+	}
+	if strings.HasPrefix(path, home+string(filepath.Separator)) {
+		return "~" + path[len(home):]
+	}
+	return path
+}
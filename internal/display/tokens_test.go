@@ -0,0 +1,66 @@
+package display
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/uesteibar/ccstats/internal/transcript"
+)
+
+func TestDisplayTokens(t *testing.T) {
+	rows := []transcript.Row{
+		{Key: "2026-01-15", Messages: 1, Usage: transcript.Usage{Input: 100, Output: 50, CacheCreation: 1000, CacheRead: 2000}},
+		{Key: "2026-01-16", Messages: 2, Usage: transcript.Usage{Input: 11, Output: 502, CacheCreation: 3, CacheRead: 3004}},
+	}
+
+	var buf bytes.Buffer
+	DisplayTokens(&buf, rows, transcript.GroupByDay, Options{Bar: BarASCII})
+
+	want := `
+Claude Code Token Usage by Day
+
+Day         Messages  Input  Output  Cache write  Cache read  Total
+-------------------------------------------------------------------
+2026-01-15         1    100      50        1,000       2,000  3,150
+2026-01-16         2     11     502            3       3,004  3,520
+-------------------------------------------------------------------
+Total              3    111     552        1,003       5,004  6,670
+
+`
+	if buf.String() != want {
+		t.Errorf("unexpected output:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func TestDisplayTokens_SessionsShowProject(t *testing.T) {
+	t.Setenv("HOME", "/home/me")
+	rows := []transcript.Row{
+		{Key: "0123456789abcdef", Project: "/home/me/app", Messages: 1},
+	}
+
+	var buf bytes.Buffer
+	DisplayTokens(&buf, rows, transcript.GroupBySession, Options{})
+
+	if !strings.Contains(buf.String(), "01234567  ~/app") {
+		t.Errorf("expected shortened session and project, got:\n%s", buf.String())
+	}
+}
+
+func TestDisplayTokens_Empty(t *testing.T) {
+	var buf bytes.Buffer
+	DisplayTokens(&buf, nil, transcript.GroupByModel, Options{})
+
+	if !strings.Contains(buf.String(), "No token usage found") {
+		t.Errorf("expected empty message, got:\n%s", buf.String())
+	}
+}
+
+func TestFormatCount(t *testing.T) {
+	tests := map[int64]string{0: "0", 999: "999", 1000: "1,000", 1234567: "1,234,567", -4200: "-4,200"}
+	for n, want := range tests {
+		if got := formatCount(n); got != want {
+			t.Errorf("formatCount(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
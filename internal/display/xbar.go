@@ -0,0 +1,74 @@
+package display
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/uesteibar/ccstats/internal/api"
+	"github.com/uesteibar/ccstats/internal/codex"
+	"github.com/uesteibar/ccstats/internal/quota"
+)
+
+// xbarRowParams are the xbar/SwiftBar parameters for dropdown rows, using a
+// monospaced font so the progress bars line up.
+const xbarRowParams = "font=Menlo size=12"
+
+// FormatXbar renders usage in the SwiftBar/xbar plugin text protocol: a
+// header line with the most utilized window, then a dropdown listing every
+// window with reset times, the Codex plan and a Refresh action.
+func FormatXbar(usage *api.UsageResponse, codexUsage *codex.Usage, now time.Time) string {
+	var b strings.Builder
+
+	windows := quota.Windows(usage, codexUsage)
+	if len(windows) == 0 {
+		b.WriteString("CC –\n---\nNo usage data available\n")
+	} else {
+		top := mostUtilizedWindow(windows)
+		fmt.Fprintf(&b, "%s | color=%s\n", FormatLine([]quota.Window{top}, LineStylePlain), levelHexColor(LevelFor(top.Metric.Utilization)))
+	}
+
+	if usage != nil {
+		b.WriteString("---\n")
+		b.WriteString("Claude Code\n")
+		for _, window := range quota.ClaudeWindows(usage) {
+			writeXbarRow(&b, window, now)
+		}
+	}
+
+	if codexUsage != nil {
+		b.WriteString("---\n")
+		fmt.Fprintf(&b, "Codex (Plan: %s)\n", codexUsage.Plan.String())
+		windows := quota.CodexWindows(codexUsage)
+		if len(windows) == 0 {
+			b.WriteString("No Codex rate-limit data available\n")
+		}
+		for _, window := range windows {
+			writeXbarRow(&b, window, now)
+		}
+	}
+
+	b.WriteString("---\n")
+	b.WriteString("Refresh | refresh=true\n")
+	return b.String()
+}
+
+func writeXbarRow(b *strings.Builder, window quota.Window, now time.Time) {
+	fmt.Fprintf(b, "%s | %s color=%s\n",
+		FormatMetricFrom(window.Label, window.Metric, now),
+		xbarRowParams,
+		levelHexColor(LevelFor(window.Metric.Utilization)),
+	)
+}
+
+// mostUtilizedWindow returns the window with the highest utilization,
+// preferring the earliest one on ties.
+func mostUtilizedWindow(windows []quota.Window) quota.Window {
+	top := windows[0]
+	for _, window := range windows[1:] {
+		if window.Metric.Utilization > top.Metric.Utilization {
+			top = window
+		}
+	}
+	return top
+}
@@ -0,0 +1,54 @@
+package display
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/uesteibar/ccstats/internal/codex"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// assertGolden compares got with testdata/<name>.golden, rewriting the file
+// when the -update flag is set.
+func assertGolden(t *testing.T, name string, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatalf("failed to update golden file: %v", err)
+		}
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read golden file: %v", err)
+	}
+	if got != string(want) {
+		t.Errorf("output does not match %s:\n%s\nwant:\n%s", path, got, want)
+	}
+}
+
+func TestFormatXbar(t *testing.T) {
+	now := time.Date(2026, 1, 16, 12, 0, 0, 0, time.UTC)
+	usage, codexUsage := statusbarFixture(now)
+
+	assertGolden(t, "xbar", FormatXbar(usage, codexUsage, now))
+}
+
+func TestFormatXbar_CodexWithoutRateLimits(t *testing.T) {
+	now := time.Date(2026, 1, 16, 12, 0, 0, 0, time.UTC)
+	usage, _ := statusbarFixture(now)
+
+	assertGolden(t, "xbar_codex_unavailable", FormatXbar(usage, &codex.Usage{Plan: codex.PlanPro}, now))
+}
+
+func TestFormatXbar_NoData(t *testing.T) {
+	now := time.Date(2026, 1, 16, 12, 0, 0, 0, time.UTC)
+
+	assertGolden(t, "xbar_empty", FormatXbar(nil, nil, now))
+}
@@ -0,0 +1,110 @@
+// Package forecast projects when a usage window will run out at its
+// current burn rate.
+package forecast
+
+import (
+	"time"
+
+	"github.com/uesteibar/ccstats/internal/history"
+	"github.com/uesteibar/ccstats/internal/quota"
+)
+
+const (
+	// lookback is how far back recent samples are used for the burn rate.
+	lookback = time.Hour
+	// minSpan is the shortest stretch of samples trusted for a rate.
+	minSpan = 10 * time.Minute
+)
+
+// Bases of a projection's burn rate.
+const (
+	// BasisRecent uses the samples recorded over the last hour.
+	BasisRecent = "recent"
+	// BasisWindow averages the usage since the window started.
+	BasisWindow = "window"
+	// BasisNone means no rate could be derived.
+	BasisNone = "none"
+)
+
+// Projection is the expected exhaustion of one window.
+type Projection struct {
+	Provider    string    `json:"provider"`
+	Window      string    `json:"window"`
+	Label       string    `json:"label"`
+	Utilization float64   `json:"utilization"`
+	ResetAt     time.Time `json:"reset_at,omitzero"`
+	// RatePerHour is the utilization gained per hour, e.g. 0.1 for 10%.
+	RatePerHour float64 `json:"rate_per_hour"`
+	Basis       string  `json:"basis"`
+	// ExhaustsAt is when the window reaches 100% at the current rate; zero
+	// when it is not being used.
+	ExhaustsAt time.Time `json:"exhausts_at,omitzero"`
+	// ExhaustsBeforeReset reports whether that happens before the reset.
+	ExhaustsBeforeReset bool `json:"exhausts_before_reset"`
+}
+
+// Project projects w at now, preferring the burn rate of recent history
+// samples over the average since the window started.
+func Project(w quota.Window, samples []history.Sample, now time.Time) Projection {
+	p := Projection{
+		Provider:    w.Provider,
+		Window:      w.Key,
+		Label:       w.Label,
+		Utilization: w.Metric.Utilization,
+		ResetAt:     w.Metric.ResetAt,
+		Basis:       BasisNone,
+	}
+
+	if rate, ok := recentRate(w, samples, now); ok {
+		p.RatePerHour, p.Basis = rate, BasisRecent
+	} else if elapsed := now.Sub(w.Start(now)); w.Duration > 0 && elapsed > 0 {
+		p.RatePerHour, p.Basis = w.Metric.Utilization/elapsed.Hours(), BasisWindow
+	}
+
+	switch {
+	case p.Utilization >= 1:
+		p.ExhaustsAt = now
+	case p.RatePerHour > 0:
+		hours := (1 - p.Utilization) / p.RatePerHour
+		p.ExhaustsAt = now.Add(time.Duration(hours * float64(time.Hour)))
+	default:
+		return p
+	}
+	p.ExhaustsBeforeReset = p.ResetAt.IsZero() || p.ExhaustsAt.Before(p.ResetAt)
+	return p
+}
+
+// recentRate returns the burn rate over the samples of w's current cycle
+// recorded in the last hour.
+func recentRate(w quota.Window, samples []history.Sample, now time.Time) (float64, bool) {
+	start := w.Start(now)
+	if cutoff := now.Add(-lookback); cutoff.After(start) {
+		start = cutoff
+	}
+
+	var first, last *history.Sample
+	for i, s := range samples {
+		if s.Provider != w.Provider || s.Window != w.Key || s.Time.Before(start) || s.Time.After(now) {
+			continue
+		}
+		if first == nil {
+			first = &samples[i]
+		}
+		last = &samples[i]
+	}
+	if first == nil {
+		return 0, false
+	}
+
+	// The current reading is newer than any sample.
+	span := now.Sub(first.Time)
+	if span < minSpan {
+		return 0, false
+	}
+	rate := (w.Metric.Utilization - first.Utilization) / span.Hours()
+	if rate < 0 || last.Utilization > w.Metric.Utilization {
+		// Usage fell, so the samples straddle a reset.
+		return 0, false
+	}
+	return rate, true
+}
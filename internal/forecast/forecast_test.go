@@ -0,0 +1,90 @@
+package forecast
+
+import (
+	"testing"
+	"time"
+
+	"github.com/uesteibar/ccstats/internal/api"
+	"github.com/uesteibar/ccstats/internal/history"
+	"github.com/uesteibar/ccstats/internal/quota"
+)
+
+var now = time.Date(2025, 11, 20, 12, 0, 0, 0, time.UTC)
+
+func fiveHour(utilization float64, resetIn time.Duration) quota.Window {
+	return quota.Window{
+		Provider: "claude",
+		Key:      "5h",
+		Label:    "5-hour",
+		Duration: 5 * time.Hour,
+		Metric:   api.UsageMetric{Utilization: utilization, ResetAt: now.Add(resetIn)},
+	}
+}
+
+func sampleAt(ago time.Duration, utilization float64) history.Sample {
+	return history.Sample{Time: now.Add(-ago), Provider: "claude", Window: "5h", Utilization: utilization}
+}
+
+func TestProject_WindowAverage(t *testing.T) {
+	// An hour and a half into the window at 40%: 100% in 2h15m, before the
+	// reset in 3h30m.
+	p := Project(fiveHour(0.4, 3*time.Hour+30*time.Minute), nil, now)
+
+	if p.Basis != BasisWindow {
+		t.Fatalf("expected window basis, got %s", p.Basis)
+	}
+	if diff := p.RatePerHour - 0.4/1.5; diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("expected rate %.3f, got %.3f", 0.4/1.5, p.RatePerHour)
+	}
+	if want := now.Add(time.Duration(0.6 / (0.4 / 1.5) * float64(time.Hour))); !p.ExhaustsAt.Equal(want) {
+		t.Errorf("expected exhaustion at %v, got %v", want, p.ExhaustsAt)
+	}
+	if !p.ExhaustsBeforeReset {
+		t.Error("expected exhaustion before the reset")
+	}
+}
+
+func TestProject_RecentHistory(t *testing.T) {
+	samples := []history.Sample{
+		sampleAt(3*time.Hour, 0.05), // before the lookback
+		sampleAt(50*time.Minute, 0.5),
+		sampleAt(20*time.Minute, 0.55),
+	}
+	p := Project(fiveHour(0.6, 3*time.Hour), samples, now)
+
+	if p.Basis != BasisRecent {
+		t.Fatalf("expected recent basis, got %s", p.Basis)
+	}
+	if want := 0.1 / (50.0 / 60); p.RatePerHour < want-1e-9 || p.RatePerHour > want+1e-9 {
+		t.Errorf("expected rate %.3f, got %.3f", want, p.RatePerHour)
+	}
+	if p.ExhaustsBeforeReset {
+		t.Errorf("expected the reset to come first, exhausts at %v", p.ExhaustsAt)
+	}
+}
+
+func TestProject_IgnoresSamplesAcrossReset(t *testing.T) {
+	samples := []history.Sample{sampleAt(40*time.Minute, 0.9)}
+	p := Project(fiveHour(0.1, 4*time.Hour+50*time.Minute), samples, now)
+
+	if p.Basis != BasisWindow {
+		t.Errorf("expected to fall back to the window average, got %s", p.Basis)
+	}
+}
+
+func TestProject_Exhausted(t *testing.T) {
+	p := Project(fiveHour(1.0, time.Hour), nil, now)
+
+	if !p.ExhaustsAt.Equal(now) || !p.ExhaustsBeforeReset {
+		t.Errorf("expected an exhausted window, got %+v", p)
+	}
+}
+
+func TestProject_Idle(t *testing.T) {
+	samples := []history.Sample{sampleAt(30*time.Minute, 0.2)}
+	p := Project(fiveHour(0.2, 2*time.Hour), samples, now)
+
+	if p.RatePerHour != 0 || !p.ExhaustsAt.IsZero() || p.ExhaustsBeforeReset {
+		t.Errorf("expected no exhaustion for an idle window, got %+v", p)
+	}
+}
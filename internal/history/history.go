@@ -0,0 +1,265 @@
+// Package history keeps a local, append-only record of window utilization
+// over time so trends can be queried after the fact.
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/uesteibar/ccstats/internal/quota"
+)
+
+// maxLineSize bounds a single history line.
+const maxLineSize = 1 << 20
+
+// Sample is the utilization of one window at a point in time.
+type Sample struct {
+	Time        time.Time `json:"time"`
+	Provider    string    `json:"provider"`
+	Window      string    `json:"window"`
+	Utilization float64   `json:"utilization"`
+	ResetAt     time.Time `json:"reset_at,omitzero"`
+}
+
+// SamplesFrom converts windows observed at now to samples.
+func SamplesFrom(windows []quota.Window, now time.Time) []Sample {
+	samples := make([]Sample, len(windows))
+	for i, w := range windows {
+		samples[i] = Sample{
+			Time:        now,
+			Provider:    w.Provider,
+			Window:      w.Key,
+			Utilization: w.Metric.Utilization,
+			ResetAt:     w.Metric.ResetAt,
+		}
+	}
+	return samples
+}
+
+// Store is a history file of JSON lines. It is safe for concurrent use,
+// also across processes: writes hold an exclusive lock on a file next to
+// it, so a run recorded by `ccstats exec` is not lost to a daemon pruning.
+type Store struct {
+	Path string
+
+	mu sync.Mutex
+}
+
+// DefaultPath returns the history file location in the cache directory.
+func DefaultPath(cacheDir string) string {
+	if cacheDir == "" {
+		return ""
+	}
+	return filepath.Join(cacheDir, "history.jsonl")
+}
+
+// Append adds samples to the end of the file.
+func (s *Store) Append(samples ...Sample) error {
+	if len(samples) == 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return withLock(s.Path, func() error {
+		return appendLines(s.Path, samples)
+	})
+}
+
+// Read returns the samples recorded at or after since (zero reads all) in
+// chronological order. A missing file is an empty history; unreadable
+// lines are skipped.
+func (s *Store) Read(since time.Time) ([]Sample, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.read(since)
+}
+
+func (s *Store) read(since time.Time) ([]Sample, error) {
+	samples, err := readLines(s.Path, func(sample Sample) bool {
+		return since.IsZero() || !sample.Time.Before(since)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(samples, func(i, j int) bool {
+		return samples[i].Time.Before(samples[j].Time)
+	})
+	return samples, nil
+}
+
+// Prune drops the samples recorded before cutoff, rewriting the file
+// atomically.
+func (s *Store) Prune(cutoff time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := os.Stat(s.Path); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return withLock(s.Path, func() error {
+		return s.prune(cutoff)
+	})
+}
+
+func (s *Store) prune(cutoff time.Time) error {
+	samples, err := s.read(cutoff)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.Path), ".history-*.jsonl")
+	if err != nil {
+		return fmt.Errorf("failed to prune history: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	w := bufio.NewWriter(tmp)
+	enc := json.NewEncoder(w)
+	for _, sample := range samples {
+		if err := enc.Encode(sample); err != nil {
+			tmp.Close()
+			return fmt.Errorf("failed to prune history: %w", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to prune history: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to prune history: %w", err)
+	}
+	return os.Rename(tmp.Name(), s.Path)
+}
+
+// Recorder appends samples to a store, skipping a window's sample when
+// nothing changed since the last one recorded unless Heartbeat has passed,
+// so frequent polling does not bloat the file.
+type Recorder struct {
+	Store     *Store
+	Heartbeat time.Duration
+
+	mu   sync.Mutex
+	last map[string]Sample
+}
+
+// Record stores the samples that carry new information.
+func (r *Recorder) Record(samples []Sample) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.last == nil {
+		r.last = make(map[string]Sample)
+	}
+
+	var changed []Sample
+	for _, sample := range samples {
+		key := sample.Provider + ":" + sample.Window
+		prev, ok := r.last[key]
+		if ok && prev.Utilization == sample.Utilization && prev.ResetAt.Equal(sample.ResetAt) &&
+			sample.Time.Sub(prev.Time) < r.Heartbeat {
+			continue
+		}
+		r.last[key] = sample
+		changed = append(changed, sample)
+	}
+	return r.Store.Append(changed...)
+}
+
+// Series returns the samples of one window in chronological order.
+func Series(samples []Sample, provider, window string) []Sample {
+	var series []Sample
+	for _, sample := range samples {
+		if sample.Provider == provider && sample.Window == window {
+			series = append(series, sample)
+		}
+	}
+	return series
+}
+
+// withLock runs fn while holding an exclusive lock on path's lock file,
+// waiting for other processes to release it first.
+func withLock(path string, fn func() error) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create history dir: %w", err)
+	}
+	f, err := os.OpenFile(path+".lock", os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open history lock: %w", err)
+	}
+	defer f.Close()
+
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		return fmt.Errorf("failed to lock history: %w", err)
+	}
+	defer syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+
+	return fn()
+}
+
+// appendLines appends values to the file at path as JSON lines, creating
+// it as needed.
+func appendLines[T any](path string, values []T) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create history dir: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open history: %w", err)
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, value := range values {
+		if err := enc.Encode(value); err != nil {
+			return fmt.Errorf("failed to write history: %w", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	return nil
+}
+
+// readLines returns the JSON lines of the file at path that keep accepts.
+// A missing file is empty; unreadable lines are skipped.
+func readLines[T any](path string, keep func(T) bool) ([]T, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	var values []T
+	for scanner.Scan() {
+		var value T
+		if err := json.Unmarshal(scanner.Bytes(), &value); err != nil {
+			continue
+		}
+		if keep(value) {
+			values = append(values, value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	return values, nil
+}
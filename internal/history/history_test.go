@@ -0,0 +1,147 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var base = time.Date(2025, 11, 20, 12, 0, 0, 0, time.UTC)
+
+func sample(minutes int, window string, utilization float64) Sample {
+	return Sample{
+		Time:        base.Add(time.Duration(minutes) * time.Minute),
+		Provider:    "claude",
+		Window:      window,
+		Utilization: utilization,
+		ResetAt:     base.Add(5 * time.Hour),
+	}
+}
+
+func TestStore_AppendAndRead(t *testing.T) {
+	store := &Store{Path: filepath.Join(t.TempDir(), "nested", "history.jsonl")}
+
+	if samples, err := store.Read(time.Time{}); err != nil || len(samples) != 0 {
+		t.Fatalf("expected empty history for missing file, got %v, %v", samples, err)
+	}
+
+	if err := store.Append(sample(10, "5h", 0.2), sample(0, "5h", 0.1)); err != nil {
+		t.Fatalf("failed to append: %v", err)
+	}
+	if err := store.Append(sample(20, "7d", 0.5)); err != nil {
+		t.Fatalf("failed to append: %v", err)
+	}
+
+	samples, err := store.Read(base.Add(5 * time.Minute))
+	if err != nil {
+		t.Fatalf("failed to read: %v", err)
+	}
+	if len(samples) != 2 {
+		t.Fatalf("expected 2 samples since the cutoff, got %d", len(samples))
+	}
+	if samples[0].Utilization != 0.2 || samples[1].Window != "7d" {
+		t.Errorf("expected chronological order, got %+v", samples)
+	}
+}
+
+func TestStore_SkipsCorruptLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	content := `{"time":"2025-11-20T12:00:00Z","provider":"claude","window":"5h","utilization":0.3}
+{"time":
+`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write history: %v", err)
+	}
+
+	samples, err := (&Store{Path: path}).Read(time.Time{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(samples) != 1 {
+		t.Errorf("expected the valid line only, got %d samples", len(samples))
+	}
+}
+
+func TestStore_Prune(t *testing.T) {
+	store := &Store{Path: filepath.Join(t.TempDir(), "history.jsonl")}
+	if err := store.Append(sample(0, "5h", 0.1), sample(60, "5h", 0.4)); err != nil {
+		t.Fatalf("failed to append: %v", err)
+	}
+
+	if err := store.Prune(base.Add(30 * time.Minute)); err != nil {
+		t.Fatalf("failed to prune: %v", err)
+	}
+
+	samples, err := store.Read(time.Time{})
+	if err != nil {
+		t.Fatalf("failed to read: %v", err)
+	}
+	if len(samples) != 1 || samples[0].Utilization != 0.4 {
+		t.Errorf("expected only the recent sample to remain, got %+v", samples)
+	}
+}
+
+func TestStore_AppendWaitsForLock(t *testing.T) {
+	store := &Store{Path: filepath.Join(t.TempDir(), "history.jsonl")}
+	appended := make(chan error, 1)
+
+	// Another process holding the lock, e.g. a daemon pruning.
+	held := make(chan struct{})
+	release := make(chan struct{})
+	go withLock(store.Path, func() error {
+		close(held)
+		<-release
+		return nil
+	})
+	<-held
+
+	go func() { appended <- store.Append(sample(0, "5h", 0.1)) }()
+	select {
+	case err := <-appended:
+		t.Fatalf("expected append to wait for the lock, got %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	if err := <-appended; err != nil {
+		t.Fatalf("failed to append: %v", err)
+	}
+	if samples, err := store.Read(time.Time{}); err != nil || len(samples) != 1 {
+		t.Errorf("expected 1 sample after the lock was released, got %v, %v", samples, err)
+	}
+}
+
+func TestRecorder_SkipsUnchanged(t *testing.T) {
+	store := &Store{Path: filepath.Join(t.TempDir(), "history.jsonl")}
+	recorder := &Recorder{Store: store, Heartbeat: 15 * time.Minute}
+
+	steps := [][]Sample{
+		{sample(0, "5h", 0.1)},
+		{sample(1, "5h", 0.1)},  // unchanged
+		{sample(2, "5h", 0.2)},  // changed
+		{sample(20, "5h", 0.2)}, // heartbeat
+	}
+	for _, samples := range steps {
+		if err := recorder.Record(samples); err != nil {
+			t.Fatalf("failed to record: %v", err)
+		}
+	}
+
+	samples, err := store.Read(time.Time{})
+	if err != nil {
+		t.Fatalf("failed to read: %v", err)
+	}
+	if len(samples) != 3 {
+		t.Errorf("expected 3 recorded samples, got %d", len(samples))
+	}
+}
+
+func TestSeries(t *testing.T) {
+	samples := []Sample{sample(0, "5h", 0.1), sample(1, "7d", 0.5), sample(2, "5h", 0.2)}
+
+	series := Series(samples, "claude", "5h")
+	if len(series) != 2 || series[1].Utilization != 0.2 {
+		t.Errorf("unexpected series %+v", series)
+	}
+}
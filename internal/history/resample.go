@@ -0,0 +1,66 @@
+package history
+
+import (
+	"math"
+	"time"
+
+	"github.com/uesteibar/ccstats/internal/quota"
+)
+
+// maxHold is how long a sample stands for the utilization after it. The
+// daemon records unchanged windows every 15 minutes, so a longer silence
+// means nothing was recording.
+const maxHold = 20 * time.Minute
+
+// Resample returns the utilization of one window's series at points
+// evenly spaced buckets between since and until: the highest sample in
+// each bucket, or the latest earlier sample while it is recent. Buckets
+// without data are NaN.
+func Resample(series []Sample, since, until time.Time, points int) []float64 {
+	values := make([]float64, points)
+	if points <= 0 || !until.After(since) {
+		return values
+	}
+
+	step := until.Sub(since) / time.Duration(points)
+	if step <= 0 {
+		step = 1
+	}
+
+	var last *Sample
+	next := 0
+	for i := range values {
+		start := since.Add(time.Duration(i) * step)
+		end := start.Add(step)
+
+		values[i] = math.NaN()
+		for ; next < len(series) && series[next].Time.Before(end); next++ {
+			sample := &series[next]
+			if !sample.Time.Before(start) && (math.IsNaN(values[i]) || sample.Utilization > values[i]) {
+				values[i] = sample.Utilization
+			}
+			last = sample
+		}
+		if math.IsNaN(values[i]) && last != nil && start.Sub(last.Time) <= maxHold {
+			values[i] = last.Utilization
+		}
+	}
+	return values
+}
+
+// Resets returns the reset times of the cycles in series that fall
+// between since and until, in order.
+func Resets(series []Sample, since, until time.Time) []time.Time {
+	var resets []time.Time
+	for _, sample := range series {
+		at := sample.ResetAt
+		if at.IsZero() || at.Before(since) || at.After(until) {
+			continue
+		}
+		if n := len(resets); n > 0 && at.Sub(resets[n-1]).Abs() <= quota.CycleTolerance {
+			continue
+		}
+		resets = append(resets, at)
+	}
+	return resets
+}
@@ -0,0 +1,66 @@
+package history
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestResample(t *testing.T) {
+	series := []Sample{
+		sample(-5, "5h", 0.05),
+		sample(2, "5h", 0.1),
+		sample(8, "5h", 0.3),
+		sample(9, "5h", 0.2),
+		// Nothing recorded between 10m and 60m.
+		sample(60, "5h", 0.5),
+	}
+
+	got := Resample(series, base, base.Add(70*time.Minute), 7)
+	// Buckets of 10 minutes: the highest sample in 0-10m, then the 9m
+	// sample held for 20 minutes, a gap, and the 60m sample.
+	want := []float64{0.3, 0.2, 0.2, math.NaN(), math.NaN(), math.NaN(), 0.5}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d values, got %d", len(want), len(got))
+	}
+	for i := range want {
+		if math.IsNaN(want[i]) != math.IsNaN(got[i]) || (!math.IsNaN(want[i]) && got[i] != want[i]) {
+			t.Errorf("value %d: expected %v, got %v", i, want[i], got[i])
+		}
+	}
+}
+
+func TestResample_HoldsEarlierSample(t *testing.T) {
+	got := Resample([]Sample{sample(-5, "5h", 0.4)}, base, base.Add(10*time.Minute), 2)
+	if got[0] != 0.4 || got[1] != 0.4 {
+		t.Errorf("expected the sample before the range to be held, got %v", got)
+	}
+}
+
+func TestResample_Empty(t *testing.T) {
+	got := Resample(nil, base, base.Add(time.Hour), 3)
+	for i, v := range got {
+		if !math.IsNaN(v) {
+			t.Errorf("value %d: expected NaN, got %v", i, v)
+		}
+	}
+	if got := Resample(nil, base, base, 3); len(got) != 3 {
+		t.Errorf("expected 3 values for an empty range, got %d", len(got))
+	}
+}
+
+func TestResets(t *testing.T) {
+	first := base.Add(time.Hour)
+	second := base.Add(6 * time.Hour)
+	series := []Sample{
+		{Time: base, ResetAt: first},
+		{Time: base.Add(30 * time.Minute), ResetAt: first.Add(time.Second)},
+		{Time: base.Add(2 * time.Hour), ResetAt: second},
+		{Time: base.Add(7 * time.Hour), ResetAt: base.Add(11 * time.Hour)},
+	}
+
+	got := Resets(series, base, base.Add(8*time.Hour))
+	if len(got) != 2 || !got[0].Equal(first) || !got[1].Equal(second) {
+		t.Errorf("expected resets at %v and %v, got %v", first, second, got)
+	}
+}
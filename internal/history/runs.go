@@ -0,0 +1,77 @@
+package history
+
+import (
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/uesteibar/ccstats/internal/quota"
+)
+
+// Run is the quota one command consumed of a window, as measured before
+// and after it ran.
+type Run struct {
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+	Command  []string  `json:"command"`
+	ExitCode int       `json:"exit_code"`
+	Provider string    `json:"provider"`
+	Window   string    `json:"window"`
+	Before   float64   `json:"before"`
+	After    float64   `json:"after"`
+	// Consumed is the utilization the run added. When the window reset
+	// during the run it only counts usage since the reset.
+	Consumed float64 `json:"consumed"`
+	Reset    bool    `json:"reset,omitempty"`
+}
+
+// Consumption returns the utilization gained between two readings of a
+// window, the second taken at, and whether the window reset in between. A
+// window that reset without new usage reports no reset time at all.
+func Consumption(before, after quota.Window, at time.Time) (consumed float64, reset bool) {
+	if quota.NewCycle(before.Metric.ResetAt, after.Metric.ResetAt, at) {
+		return after.Metric.Utilization, true
+	}
+	return max(after.Metric.Utilization-before.Metric.Utilization, 0), false
+}
+
+// RunLog is a file of runs as JSON lines. It is safe for concurrent use
+// within one process.
+type RunLog struct {
+	Path string
+
+	mu sync.Mutex
+}
+
+// RunsPath returns the run log location in the cache directory.
+func RunsPath(cacheDir string) string {
+	if cacheDir == "" {
+		return ""
+	}
+	return filepath.Join(cacheDir, "runs.jsonl")
+}
+
+// Append adds runs to the end of the log.
+func (l *RunLog) Append(runs ...Run) error {
+	if len(runs) == 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return withLock(l.Path, func() error {
+		return appendLines(l.Path, runs)
+	})
+}
+
+// Read returns the runs started at or after since (zero reads all) in the
+// order they were logged.
+func (l *RunLog) Read(since time.Time) ([]Run, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return readLines(l.Path, func(run Run) bool {
+		return since.IsZero() || !run.Start.Before(since)
+	})
+}
@@ -0,0 +1,72 @@
+package history
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/uesteibar/ccstats/internal/api"
+	"github.com/uesteibar/ccstats/internal/quota"
+)
+
+func reading(utilization float64, resetAt time.Time) quota.Window {
+	return quota.Window{
+		Provider: "claude",
+		Key:      "5h",
+		Metric:   api.UsageMetric{Utilization: utilization, ResetAt: resetAt},
+	}
+}
+
+func TestConsumption(t *testing.T) {
+	resetAt := base.Add(time.Hour)
+
+	tests := []struct {
+		name         string
+		before       quota.Window
+		after        quota.Window
+		at           time.Time
+		wantConsumed float64
+		wantReset    bool
+	}{
+		{"same cycle", reading(0.2, resetAt), reading(0.35, resetAt), base, 0.15, false},
+		{"jittered reset time", reading(0.2, resetAt), reading(0.25, resetAt.Add(time.Second)), base, 0.05, false},
+		{"reset during run", reading(0.9, resetAt), reading(0.1, resetAt.Add(5*time.Hour)), resetAt.Add(time.Hour), 0.1, true},
+		{"reset without new usage", reading(0.9, resetAt), reading(0, time.Time{}), resetAt.Add(time.Minute), 0, true},
+		{"reset time missing before it passed", reading(0.2, resetAt), reading(0.2, time.Time{}), base, 0, false},
+		{"rolling window drained", reading(0.4, time.Time{}), reading(0.3, time.Time{}), base, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			consumed, reset := Consumption(tt.before, tt.after, tt.at)
+			if diff := consumed - tt.wantConsumed; diff > 1e-9 || diff < -1e-9 {
+				t.Errorf("expected consumed %v, got %v", tt.wantConsumed, consumed)
+			}
+			if reset != tt.wantReset {
+				t.Errorf("expected reset %v, got %v", tt.wantReset, reset)
+			}
+		})
+	}
+}
+
+func TestRunLog_AppendAndRead(t *testing.T) {
+	log := &RunLog{Path: filepath.Join(t.TempDir(), "runs.jsonl")}
+
+	if runs, err := log.Read(time.Time{}); err != nil || len(runs) != 0 {
+		t.Fatalf("expected no runs for missing file, got %v, %v", runs, err)
+	}
+
+	first := Run{Start: base, End: base.Add(time.Minute), Command: []string{"claude", "-p", "hi"}, Window: "5h", Consumed: 0.02}
+	second := Run{Start: base.Add(time.Hour), End: base.Add(2 * time.Hour), Command: []string{"make"}, ExitCode: 2, Window: "5h"}
+	if err := log.Append(first, second); err != nil {
+		t.Fatalf("failed to append: %v", err)
+	}
+
+	runs, err := log.Read(base.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("failed to read: %v", err)
+	}
+	if len(runs) != 1 || runs[0].ExitCode != 2 || runs[0].Command[0] != "make" {
+		t.Errorf("expected the second run only, got %+v", runs)
+	}
+}
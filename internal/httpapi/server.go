@@ -0,0 +1,221 @@
+// Package httpapi serves usage and history as a read-only JSON API over
+// HTTP for dashboards and editor extensions.
+package httpapi
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/uesteibar/ccstats/internal/history"
+	"github.com/uesteibar/ccstats/internal/snapshot"
+)
+
+// defaultHistoryRange is the history returned when no since is given.
+const defaultHistoryRange = 24 * time.Hour
+
+// UsageDocument is the body of /v1/usage: the snapshot plus any
+// per-provider errors.
+type UsageDocument struct {
+	*snapshot.Snapshot
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+// HistoryDocument is the body of /v1/history.
+type HistoryDocument struct {
+	Since   time.Time        `json:"since"`
+	Samples []history.Sample `json:"samples"`
+}
+
+// errorDocument is the body of every error response.
+type errorDocument struct {
+	Error string `json:"error"`
+}
+
+// Server answers the API endpoints.
+type Server struct {
+	// Usage returns the current usage. Calls are serialized so a caching
+	// fetcher is not raced by concurrent requests.
+	Usage func(ctx context.Context) (*snapshot.Snapshot, snapshot.Errors)
+	// History returns the samples recorded since a time.
+	History func(ctx context.Context, since time.Time) ([]history.Sample, error)
+	// ParseSince parses the since query parameter. Nil accepts RFC 3339
+	// timestamps only.
+	ParseSince func(s string, now time.Time) (time.Time, error)
+	// Token, when set, must be sent as "Authorization: Bearer <token>" on
+	// every endpoint but /healthz.
+	Token string
+	// CORSOrigins lists the origins allowed to call the API from a
+	// browser; "*" allows any.
+	CORSOrigins []string
+
+	usageMu sync.Mutex
+	now     func() time.Time
+}
+
+// Handler returns the HTTP handler for the API.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", s.handleHealth)
+	mux.Handle("GET /v1/usage", s.authorize(http.HandlerFunc(s.handleUsage)))
+	mux.Handle("GET /v1/usage/claude", s.authorize(http.HandlerFunc(s.handleClaude)))
+	mux.Handle("GET /v1/usage/codex", s.authorize(http.HandlerFunc(s.handleCodex)))
+	mux.Handle("GET /v1/history", s.authorize(http.HandlerFunc(s.handleHistory)))
+	return s.cors(mux)
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (s *Server) handleUsage(w http.ResponseWriter, r *http.Request) {
+	snap, errs := s.usage(r.Context())
+	doc := UsageDocument{Snapshot: snap, Errors: errorMap(errs)}
+	if snap.Claude == nil && snap.Codex == nil {
+		writeJSON(w, http.StatusBadGateway, doc)
+		return
+	}
+	writeJSON(w, http.StatusOK, doc)
+}
+
+func (s *Server) handleClaude(w http.ResponseWriter, r *http.Request) {
+	snap, errs := s.usage(r.Context())
+	if snap.Claude == nil {
+		writeProviderError(w, errs.Claude, "no Claude usage available")
+		return
+	}
+	writeJSON(w, http.StatusOK, snap.Claude)
+}
+
+func (s *Server) handleCodex(w http.ResponseWriter, r *http.Request) {
+	snap, errs := s.usage(r.Context())
+	if snap.Codex == nil {
+		writeProviderError(w, errs.Codex, "no Codex usage available")
+		return
+	}
+	writeJSON(w, http.StatusOK, snap.Codex)
+}
+
+func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
+	now := s.clock()
+	since := now.Add(-defaultHistoryRange)
+	if raw := r.URL.Query().Get("since"); raw != "" {
+		parse := s.ParseSince
+		if parse == nil {
+			parse = func(s string, _ time.Time) (time.Time, error) { return time.Parse(time.RFC3339, s) }
+		}
+		parsed, err := parse(raw, now)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		since = parsed
+	}
+
+	samples, err := s.History(r.Context(), since)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if samples == nil {
+		samples = []history.Sample{}
+	}
+	writeJSON(w, http.StatusOK, HistoryDocument{Since: since, Samples: samples})
+}
+
+func (s *Server) usage(ctx context.Context) (*snapshot.Snapshot, snapshot.Errors) {
+	s.usageMu.Lock()
+	defer s.usageMu.Unlock()
+
+	snap, errs := s.Usage(ctx)
+	if snap == nil {
+		snap = &snapshot.Snapshot{}
+	}
+	return snap, errs
+}
+
+// authorize rejects requests without the bearer token when one is set.
+func (s *Server) authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.Token != "" {
+			token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.Token)) != 1 {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="ccstats"`)
+				writeError(w, http.StatusUnauthorized, "missing or invalid bearer token")
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// cors adds CORS headers for allowed origins and answers preflight
+// requests, which browsers send without credentials.
+func (s *Server) cors(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		allowed := origin != "" && (slices.Contains(s.CORSOrigins, "*") || slices.Contains(s.CORSOrigins, origin))
+		if allowed {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Add("Vary", "Origin")
+		}
+
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			if !allowed {
+				writeError(w, http.StatusForbidden, "origin not allowed")
+				return
+			}
+			w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
+			w.Header().Set("Access-Control-Max-Age", "600")
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) clock() time.Time {
+	if s.now != nil {
+		return s.now()
+	}
+	return time.Now()
+}
+
+func errorMap(errs snapshot.Errors) map[string]string {
+	if errs.Claude == nil && errs.Codex == nil {
+		return nil
+	}
+	m := make(map[string]string)
+	if errs.Claude != nil {
+		m["claude"] = errs.Claude.Error()
+	}
+	if errs.Codex != nil {
+		m["codex"] = errs.Codex.Error()
+	}
+	return m
+}
+
+func writeProviderError(w http.ResponseWriter, err error, fallback string) {
+	if err == nil {
+		err = errors.New(fallback)
+	}
+	writeError(w, http.StatusBadGateway, err.Error())
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, errorDocument{Error: message})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
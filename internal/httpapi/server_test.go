@@ -0,0 +1,179 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/uesteibar/ccstats/internal/api"
+	"github.com/uesteibar/ccstats/internal/codex"
+	"github.com/uesteibar/ccstats/internal/history"
+	"github.com/uesteibar/ccstats/internal/snapshot"
+)
+
+var serverTime = time.Date(2025, 11, 20, 12, 0, 0, 0, time.UTC)
+
+func newTestServer(claude *api.UsageResponse, codexErr error) *Server {
+	return &Server{
+		Usage: func(context.Context) (*snapshot.Snapshot, snapshot.Errors) {
+			return &snapshot.Snapshot{FetchedAt: serverTime, Claude: claude}, snapshot.Errors{Codex: codexErr}
+		},
+		History: func(_ context.Context, since time.Time) ([]history.Sample, error) {
+			return []history.Sample{{Time: since.Add(time.Minute), Provider: "claude", Window: "5h", Utilization: 0.3}}, nil
+		},
+		now: func() time.Time { return serverTime },
+	}
+}
+
+func get(t *testing.T, handler http.Handler, path string, header map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestServer_Endpoints(t *testing.T) {
+	claude := &api.UsageResponse{FiveHour: api.UsageMetric{Utilization: 0.42}}
+	handler := newTestServer(claude, codex.ErrAuthNotFound).Handler()
+
+	tests := []struct {
+		path     string
+		status   int
+		contains string
+	}{
+		{path: "/healthz", status: http.StatusOK, contains: `"status":"ok"`},
+		{path: "/v1/usage", status: http.StatusOK, contains: `"errors":{"codex":"codex credentials not found`},
+		{path: "/v1/usage/claude", status: http.StatusOK, contains: `"utilization":0.42`},
+		{path: "/v1/usage/codex", status: http.StatusBadGateway, contains: `"error":"codex credentials not found`},
+		{path: "/v1/history", status: http.StatusOK, contains: `"since":"2025-11-19T12:00:00Z"`},
+		{path: "/v1/nope", status: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rec := get(t, handler, tt.path, nil)
+			if rec.Code != tt.status {
+				t.Errorf("expected status %d, got %d: %s", tt.status, rec.Code, rec.Body)
+			}
+			if !strings.Contains(rec.Body.String(), tt.contains) {
+				t.Errorf("expected body to contain %s, got %s", tt.contains, rec.Body)
+			}
+		})
+	}
+}
+
+func TestServer_NoUsage(t *testing.T) {
+	rec := get(t, newTestServer(nil, errors.New("offline")).Handler(), "/v1/usage", nil)
+	if rec.Code != http.StatusBadGateway {
+		t.Errorf("expected 502 without any usage, got %d", rec.Code)
+	}
+}
+
+func TestServer_HistorySince(t *testing.T) {
+	server := newTestServer(nil, nil)
+	server.ParseSince = func(s string, now time.Time) (time.Time, error) {
+		if s != "7d" {
+			return time.Time{}, errors.New("bad since")
+		}
+		return now.AddDate(0, 0, -7), nil
+	}
+	handler := server.Handler()
+
+	rec := get(t, handler, "/v1/history?since=7d", nil)
+	var doc HistoryDocument
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if !doc.Since.Equal(serverTime.AddDate(0, 0, -7)) || len(doc.Samples) != 1 {
+		t.Errorf("unexpected history %+v", doc)
+	}
+
+	if rec := get(t, handler, "/v1/history?since=soon", nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for invalid since, got %d", rec.Code)
+	}
+}
+
+func TestServer_BearerToken(t *testing.T) {
+	server := newTestServer(&api.UsageResponse{}, nil)
+	server.Token = "s3cret"
+	handler := server.Handler()
+
+	if rec := get(t, handler, "/v1/usage", nil); rec.Code != http.StatusUnauthorized || rec.Header().Get("WWW-Authenticate") == "" {
+		t.Errorf("expected 401 with a challenge, got %d", rec.Code)
+	}
+	if rec := get(t, handler, "/v1/usage", map[string]string{"Authorization": "Bearer wrong"}); rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 for a wrong token, got %d", rec.Code)
+	}
+	if rec := get(t, handler, "/v1/usage", map[string]string{"Authorization": "Bearer s3cret"}); rec.Code != http.StatusOK {
+		t.Errorf("expected 200 with the token, got %d", rec.Code)
+	}
+	if rec := get(t, handler, "/healthz", nil); rec.Code != http.StatusOK {
+		t.Errorf("expected /healthz without a token, got %d", rec.Code)
+	}
+}
+
+func TestServer_CORS(t *testing.T) {
+	server := newTestServer(&api.UsageResponse{}, nil)
+	server.Token = "s3cret"
+	server.CORSOrigins = []string{"http://localhost:3000"}
+	handler := server.Handler()
+
+	preflight := httptest.NewRequest(http.MethodOptions, "/v1/usage", nil)
+	preflight.Header.Set("Origin", "http://localhost:3000")
+	preflight.Header.Set("Access-Control-Request-Method", "GET")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, preflight)
+	if rec.Code != http.StatusNoContent || rec.Header().Get("Access-Control-Allow-Origin") != "http://localhost:3000" {
+		t.Errorf("expected an allowed preflight without a token, got %d %v", rec.Code, rec.Header())
+	}
+
+	rec = get(t, handler, "/v1/usage", map[string]string{"Origin": "http://evil.example", "Authorization": "Bearer s3cret"})
+	if rec.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("expected no CORS header for other origins, got %q", rec.Header().Get("Access-Control-Allow-Origin"))
+	}
+}
+
+func TestServer_SerializesUsage(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	server := &Server{
+		Usage: func(context.Context) (*snapshot.Snapshot, snapshot.Errors) {
+			n := inFlight.Add(1)
+			defer inFlight.Add(-1)
+			if n > maxInFlight.Load() {
+				maxInFlight.Store(n)
+			}
+			time.Sleep(5 * time.Millisecond)
+			return &snapshot.Snapshot{Claude: &api.UsageResponse{}}, snapshot.Errors{}
+		},
+	}
+	ts := httptest.NewServer(server.Handler())
+	defer ts.Close()
+
+	done := make(chan struct{})
+	for range 4 {
+		go func() {
+			defer func() { done <- struct{}{} }()
+			resp, err := http.Get(ts.URL + "/v1/usage/claude")
+			if err == nil {
+				resp.Body.Close()
+			}
+		}()
+	}
+	for range 4 {
+		<-done
+	}
+
+	if maxInFlight.Load() != 1 {
+		t.Errorf("expected usage fetches to be serialized, got %d at once", maxInFlight.Load())
+	}
+}
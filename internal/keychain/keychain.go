@@ -5,15 +5,38 @@ package keychain
 import (
 	"encoding/json"
 	"errors"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 )
 
 // ErrCredentialsNotFound is returned when credentials cannot be found in the Keychain.
 var ErrCredentialsNotFound = errors.New("credentials not found: Please log in to Claude Code first using `claude` command")
 
-// keychainServiceName is the service name used by Claude Code to store credentials.
-const keychainServiceName = "Claude Code-credentials"
+// DefaultService is the Keychain service name Claude Code stores its
+// credentials under.
+const DefaultService = "Claude Code-credentials"
+
+// credentialsFile is where Claude Code stores its credentials in the config
+// directory when no Keychain is available.
+const credentialsFile = ".credentials.json"
+
+// Source locates a Claude Code login. The zero value reads the default
+// Keychain item.
+type Source struct {
+	// Service is the Keychain service name. Empty uses DefaultService,
+	// unless ConfigDir is set.
+	Service string
+	// ConfigDir, when set, is a Claude config directory whose
+	// .credentials.json is read if the Keychain has no credentials. Without
+	// a Service, only this file is read, so the default Keychain item of
+	// another login is never picked up.
+	ConfigDir string
+}
+
+// readKeychain reads a Keychain item; tests replace it.
+var readKeychain = readFromKeychain
 
 // credentialsJSON represents the structure of credentials stored in Keychain.
 // It supports both the current format (claudeAiOauth) and older format (oauthAccount).
@@ -23,25 +46,75 @@ type credentialsJSON struct {
 }
 
 type oauthCredentials struct {
-	AccessToken  string `json:"accessToken"`
-	RefreshToken string `json:"refreshToken,omitempty"`
-	ExpiresAt    int64  `json:"expiresAt,omitempty"`
+	AccessToken      string `json:"accessToken"`
+	RefreshToken     string `json:"refreshToken,omitempty"`
+	ExpiresAt        int64  `json:"expiresAt,omitempty"`
+	SubscriptionType string `json:"subscriptionType,omitempty"`
+	RateLimitTier    string `json:"rateLimitTier,omitempty"`
+}
+
+// Credentials holds the OAuth access token and the subscription metadata
+// Claude Code stores next to it.
+type Credentials struct {
+	AccessToken      string
+	SubscriptionType string
+	RateLimitTier    string
 }
 
 // GetAccessToken retrieves the OAuth access token from the macOS Keychain.
 // It returns the access token string, or an error if credentials are not found.
 func GetAccessToken() (string, error) {
-	rawCredentials, err := readFromKeychain(keychainServiceName)
+	creds, err := GetCredentials()
+	if err != nil {
+		return "", err
+	}
+	return creds.AccessToken, nil
+}
+
+// GetCredentials retrieves the OAuth access token and subscription metadata
+// from the macOS Keychain.
+func GetCredentials() (*Credentials, error) {
+	return Source{}.GetCredentials()
+}
+
+// GetCredentials retrieves the OAuth access token and subscription metadata
+// of the login s points at.
+func (s Source) GetCredentials() (*Credentials, error) {
+	var rawCredentials string
+	var err error
+	switch {
+	case s.Service != "":
+		rawCredentials, err = readKeychain(s.Service)
+		if err != nil && s.ConfigDir != "" {
+			rawCredentials, err = s.readCredentialsFile()
+		}
+	case s.ConfigDir != "":
+		rawCredentials, err = s.readCredentialsFile()
+	default:
+		rawCredentials, err = readKeychain(DefaultService)
+	}
 	if err != nil {
-		return "", ErrCredentialsNotFound
+		return nil, ErrCredentialsNotFound
 	}
 
-	token, err := parseAccessToken(rawCredentials)
+	creds, err := parseCredentials(rawCredentials)
 	if err != nil {
-		return "", ErrCredentialsNotFound
+		return nil, ErrCredentialsNotFound
 	}
 
-	return token, nil
+	return creds, nil
+}
+
+// readCredentialsFile reads the credentials file in the config directory.
+func (s Source) readCredentialsFile() (string, error) {
+	data, err := os.ReadFile(filepath.Join(s.ConfigDir, credentialsFile))
+	return string(data), err
+}
+
+// HasCredentials checks if the login s points at has credentials.
+func (s Source) HasCredentials() bool {
+	_, err := s.GetCredentials()
+	return err == nil
 }
 
 // readFromKeychain retrieves the password for a service from the macOS Keychain
@@ -58,22 +131,34 @@ func readFromKeychain(service string) (string, error) {
 // parseAccessToken extracts the OAuth access token from the credentials JSON.
 // It checks both claudeAiOauth and oauthAccount fields for compatibility.
 func parseAccessToken(rawJSON string) (string, error) {
-	var creds credentialsJSON
-	if err := json.Unmarshal([]byte(rawJSON), &creds); err != nil {
+	creds, err := parseCredentials(rawJSON)
+	if err != nil {
 		return "", err
 	}
+	return creds.AccessToken, nil
+}
 
-	// Check claudeAiOauth first (current format)
-	if creds.ClaudeAiOauth != nil && creds.ClaudeAiOauth.AccessToken != "" {
-		return creds.ClaudeAiOauth.AccessToken, nil
+// parseCredentials extracts the access token and subscription metadata from
+// the credentials JSON, preferring claudeAiOauth over oauthAccount.
+func parseCredentials(rawJSON string) (*Credentials, error) {
+	var creds credentialsJSON
+	if err := json.Unmarshal([]byte(rawJSON), &creds); err != nil {
+		return nil, err
 	}
 
-	// Fall back to oauthAccount (older format)
-	if creds.OauthAccount != nil && creds.OauthAccount.AccessToken != "" {
-		return creds.OauthAccount.AccessToken, nil
+	// Check claudeAiOauth first (current format), then fall back to
+	// oauthAccount (older format)
+	for _, oauth := range []*oauthCredentials{creds.ClaudeAiOauth, creds.OauthAccount} {
+		if oauth != nil && oauth.AccessToken != "" {
+			return &Credentials{
+				AccessToken:      oauth.AccessToken,
+				SubscriptionType: oauth.SubscriptionType,
+				RateLimitTier:    oauth.RateLimitTier,
+			}, nil
+		}
 	}
 
-	return "", errors.New("no access token found in credentials")
+	return nil, errors.New("no access token found in credentials")
 }
 
 // HasCredentials checks if credentials are available in the Keychain.
@@ -1,6 +1,10 @@
 package keychain
 
-import "testing"
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
 
 func TestParseAccessToken_ClaudeAiOauth(t *testing.T) {
 	input := `{
@@ -94,3 +98,87 @@ func TestParseAccessToken_NoTokenFields(t *testing.T) {
 		t.Error("expected error when no token fields present")
 	}
 }
+
+func TestParseCredentials_SubscriptionMetadata(t *testing.T) {
+	input := `{
+		"claudeAiOauth": {
+			"accessToken": "sk-ant-oat01-token123",
+			"subscriptionType": "max",
+			"rateLimitTier": "default_claude_max_20x"
+		}
+	}`
+
+	creds, err := parseCredentials(input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if creds.SubscriptionType != "max" {
+		t.Errorf("expected subscription type %q, got %q", "max", creds.SubscriptionType)
+	}
+	if creds.RateLimitTier != "default_claude_max_20x" {
+		t.Errorf("expected rate limit tier %q, got %q", "default_claude_max_20x", creds.RateLimitTier)
+	}
+}
+
+func TestSource_ReadsConfigDirCredentials(t *testing.T) {
+	dir := t.TempDir()
+	content := `{"claudeAiOauth": {"accessToken": "file-token", "subscriptionType": "max"}}`
+	if err := os.WriteFile(filepath.Join(dir, ".credentials.json"), []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write credentials: %v", err)
+	}
+
+	source := Source{Service: "ccstats-test-missing-service", ConfigDir: dir}
+	creds, err := source.GetCredentials()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if creds.AccessToken != "file-token" || creds.SubscriptionType != "max" {
+		t.Errorf("expected the file credentials, got %+v", creds)
+	}
+}
+
+func TestSource_ConfigDirWithoutServiceSkipsDefaultKeychain(t *testing.T) {
+	dir := t.TempDir()
+	content := `{"claudeAiOauth": {"accessToken": "work-token"}}`
+	if err := os.WriteFile(filepath.Join(dir, ".credentials.json"), []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write credentials: %v", err)
+	}
+
+	defer func(orig func(string) (string, error)) { readKeychain = orig }(readKeychain)
+	readKeychain = func(service string) (string, error) {
+		return `{"claudeAiOauth": {"accessToken": "keychain-token"}}`, nil
+	}
+
+	tests := []struct {
+		name   string
+		source Source
+		want   string
+	}{
+		{name: "config dir only", source: Source{ConfigDir: dir}, want: "work-token"},
+		{name: "service and config dir", source: Source{Service: "Claude Code-credentials-work", ConfigDir: dir}, want: "keychain-token"},
+		{name: "default", source: Source{}, want: "keychain-token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			creds, err := tt.source.GetCredentials()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if creds.AccessToken != tt.want {
+				t.Errorf("expected token %q, got %q", tt.want, creds.AccessToken)
+			}
+		})
+	}
+}
+
+func TestSource_MissingCredentials(t *testing.T) {
+	source := Source{Service: "ccstats-test-missing-service", ConfigDir: t.TempDir()}
+	if _, err := source.GetCredentials(); err != ErrCredentialsNotFound {
+		t.Errorf("expected ErrCredentialsNotFound, got %v", err)
+	}
+	if source.HasCredentials() {
+		t.Error("expected no credentials")
+	}
+}
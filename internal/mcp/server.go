@@ -0,0 +1,220 @@
+// Package mcp implements a minimal Model Context Protocol server over
+// stdio: line-delimited JSON-RPC 2.0 exposing tools.
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// ProtocolVersion is the MCP revision this server implements. Clients
+// asking for another revision are answered with this one, as the
+// specification allows.
+const ProtocolVersion = "2025-06-18"
+
+// maxMessageSize bounds a single request line.
+const maxMessageSize = 4 << 20
+
+// JSON-RPC error codes.
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeInternalError  = -32603
+)
+
+// Tool is a callable tool. Handler receives the call arguments and returns
+// a value that is sent to the client as JSON; an error is reported as a
+// tool error the model can read, not as a protocol error.
+type Tool struct {
+	Name        string
+	Description string
+	// InputSchema is the JSON Schema of the arguments.
+	InputSchema map[string]any
+	Handler     func(ctx context.Context, args json.RawMessage) (any, error)
+}
+
+// Server answers MCP requests for a set of tools.
+type Server struct {
+	Name    string
+	Version string
+	// Instructions is shown to the model as guidance on using the tools.
+	Instructions string
+	Tools        []Tool
+}
+
+type message struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type reply struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *replyError     `json:"error,omitempty"`
+}
+
+type replyError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type toolInfo struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	InputSchema map[string]any `json:"inputSchema"`
+}
+
+type content struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type callResult struct {
+	Content           []content `json:"content"`
+	StructuredContent any       `json:"structuredContent,omitempty"`
+	IsError           bool      `json:"isError,omitempty"`
+}
+
+// Serve reads requests from r and writes replies to w until r is exhausted
+// or ctx is done. Tool calls run concurrently; replies are written whole,
+// one per line.
+func (s *Server) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxMessageSize)
+
+	var mu sync.Mutex
+	enc := json.NewEncoder(w)
+	send := func(rep reply) {
+		mu.Lock()
+		defer mu.Unlock()
+		rep.JSONRPC = "2.0"
+		_ = enc.Encode(rep)
+	}
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for scanner.Scan() {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var msg message
+		if err := json.Unmarshal(line, &msg); err != nil {
+			send(reply{ID: json.RawMessage("null"), Error: &replyError{Code: codeParseError, Message: "parse error"}})
+			continue
+		}
+		// Notifications have no ID and get no reply.
+		if len(msg.ID) == 0 {
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, rpcErr := s.dispatch(ctx, msg)
+			send(reply{ID: msg.ID, Result: result, Error: rpcErr})
+		}()
+	}
+	return scanner.Err()
+}
+
+func (s *Server) dispatch(ctx context.Context, msg message) (any, *replyError) {
+	switch msg.Method {
+	case "initialize":
+		result := map[string]any{
+			"protocolVersion": ProtocolVersion,
+			"capabilities":    map[string]any{"tools": map[string]any{}},
+			"serverInfo":      map[string]string{"name": s.Name, "version": s.Version},
+		}
+		if s.Instructions != "" {
+			result["instructions"] = s.Instructions
+		}
+		return result, nil
+	case "ping":
+		return map[string]any{}, nil
+	case "tools/list":
+		tools := make([]toolInfo, len(s.Tools))
+		for i, tool := range s.Tools {
+			tools[i] = toolInfo{Name: tool.Name, Description: tool.Description, InputSchema: tool.InputSchema}
+		}
+		return map[string]any{"tools": tools}, nil
+	case "tools/call":
+		return s.call(ctx, msg.Params)
+	case "":
+		return nil, &replyError{Code: codeInvalidRequest, Message: "missing method"}
+	default:
+		return nil, &replyError{Code: codeMethodNotFound, Message: fmt.Sprintf("method not found: %s", msg.Method)}
+	}
+}
+
+func (s *Server) call(ctx context.Context, raw json.RawMessage) (any, *replyError) {
+	var params struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	}
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return nil, &replyError{Code: codeInvalidParams, Message: "invalid tool call: " + err.Error()}
+	}
+
+	for _, tool := range s.Tools {
+		if tool.Name != params.Name {
+			continue
+		}
+		args := params.Arguments
+		if len(args) == 0 || string(args) == "null" {
+			args = json.RawMessage("{}")
+		}
+
+		value, err := tool.Handler(ctx, args)
+		if err != nil {
+			return callResult{Content: []content{{Type: "text", Text: err.Error()}}, IsError: true}, nil
+		}
+		text, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+			return nil, &replyError{Code: codeInternalError, Message: err.Error()}
+		}
+		return callResult{Content: []content{{Type: "text", Text: string(text)}}, StructuredContent: structured(value)}, nil
+	}
+	return nil, &replyError{Code: codeInvalidParams, Message: fmt.Sprintf("unknown tool: %s", params.Name)}
+}
+
+// structured returns value as structured content, which the protocol
+// requires to be a JSON object.
+func structured(value any) any {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+	var object map[string]any
+	if err := json.Unmarshal(data, &object); err != nil || object == nil {
+		return nil
+	}
+	return object
+}
+
+// DecodeArgs decodes tool arguments into v, rejecting unknown fields.
+func DecodeArgs(args json.RawMessage, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(args))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("invalid arguments: %w", err)
+	}
+	return nil
+}
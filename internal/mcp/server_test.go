@@ -0,0 +1,128 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func testServer() *Server {
+	return &Server{
+		Name:    "ccstats",
+		Version: "test",
+		Tools: []Tool{
+			{
+				Name:        "echo",
+				Description: "Echoes its input.",
+				InputSchema: map[string]any{"type": "object"},
+				Handler: func(_ context.Context, args json.RawMessage) (any, error) {
+					var in struct {
+						Text string `json:"text"`
+					}
+					if err := DecodeArgs(args, &in); err != nil {
+						return nil, err
+					}
+					return map[string]string{"text": in.Text}, nil
+				},
+			},
+			{
+				Name:        "fail",
+				InputSchema: map[string]any{"type": "object"},
+				Handler: func(context.Context, json.RawMessage) (any, error) {
+					return nil, errors.New("quota data unavailable")
+				},
+			},
+		},
+	}
+}
+
+// roundTrip sends requests, one per line, and returns the replies by ID.
+func roundTrip(t *testing.T, requests ...string) map[string]map[string]any {
+	t.Helper()
+
+	var out strings.Builder
+	if err := testServer().Serve(context.Background(), strings.NewReader(strings.Join(requests, "\n")), &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	replies := make(map[string]map[string]any)
+	scanner := bufio.NewScanner(strings.NewReader(out.String()))
+	for scanner.Scan() {
+		var reply map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &reply); err != nil {
+			t.Fatalf("invalid reply %s: %v", scanner.Text(), err)
+		}
+		id, _ := json.Marshal(reply["id"])
+		replies[string(id)] = reply
+	}
+	return replies
+}
+
+func TestServer_Handshake(t *testing.T) {
+	replies := roundTrip(t,
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"t","version":"1"}}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":"p","method":"ping"}`,
+	)
+
+	if len(replies) != 3 {
+		t.Fatalf("expected 3 replies (none for the notification), got %d", len(replies))
+	}
+	init := replies["1"]["result"].(map[string]any)
+	if init["protocolVersion"] != ProtocolVersion {
+		t.Errorf("unexpected protocol version %v", init["protocolVersion"])
+	}
+	tools := replies["2"]["result"].(map[string]any)["tools"].([]any)
+	if len(tools) != 2 || tools[0].(map[string]any)["name"] != "echo" {
+		t.Errorf("unexpected tools %v", tools)
+	}
+	if _, ok := replies[`"p"`]["result"]; !ok {
+		t.Errorf("expected ping to answer, got %v", replies[`"p"`])
+	}
+}
+
+func TestServer_ToolCalls(t *testing.T) {
+	replies := roundTrip(t,
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"echo","arguments":{"text":"hi"}}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"fail"}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"echo","arguments":{"txt":"hi"}}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"nope"}}`,
+	)
+
+	ok := replies["1"]["result"].(map[string]any)
+	if ok["structuredContent"].(map[string]any)["text"] != "hi" || ok["isError"] != nil {
+		t.Errorf("unexpected echo result %v", ok)
+	}
+
+	failed := replies["2"]["result"].(map[string]any)
+	text := failed["content"].([]any)[0].(map[string]any)["text"]
+	if failed["isError"] != true || text != "quota data unavailable" {
+		t.Errorf("expected a tool error, got %v", failed)
+	}
+
+	if replies["3"]["result"].(map[string]any)["isError"] != true {
+		t.Errorf("expected unknown arguments to be a tool error, got %v", replies["3"])
+	}
+
+	if code := replies["4"]["error"].(map[string]any)["code"]; code != float64(codeInvalidParams) {
+		t.Errorf("expected invalid params for an unknown tool, got %v", code)
+	}
+}
+
+func TestServer_ProtocolErrors(t *testing.T) {
+	replies := roundTrip(t,
+		`not json`,
+		`{"jsonrpc":"2.0","id":1,"method":"resources/list"}`,
+	)
+
+	if code := replies["null"]["error"].(map[string]any)["code"]; code != float64(codeParseError) {
+		t.Errorf("expected a parse error, got %v", code)
+	}
+	if code := replies["1"]["error"].(map[string]any)["code"]; code != float64(codeMethodNotFound) {
+		t.Errorf("expected method not found, got %v", code)
+	}
+}
@@ -0,0 +1,263 @@
+// Package notify detects when usage windows cross thresholds or reset and
+// delivers alerts to pluggable sinks, alerting once per window cycle.
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/uesteibar/ccstats/internal/quota"
+)
+
+// Kind is the type of an event.
+type Kind string
+
+const (
+	KindThreshold Kind = "threshold"
+	KindReset     Kind = "reset"
+)
+
+// Window is the state of a usage window at the time of a check.
+type Window struct {
+	Provider    string
+	Key         string
+	Label       string
+	Utilization float64
+	ResetAt     time.Time
+}
+
+// WindowsFrom converts quota windows to notifier windows.
+func WindowsFrom(windows []quota.Window) []Window {
+	converted := make([]Window, len(windows))
+	for i, w := range windows {
+		converted[i] = Window{
+			Provider:    w.Provider,
+			Key:         w.Key,
+			Label:       w.Label,
+			Utilization: w.Metric.Utilization,
+			ResetAt:     w.Metric.ResetAt,
+		}
+	}
+	return converted
+}
+
+// Event is a threshold crossing or a window reset.
+type Event struct {
+	Kind     Kind   `json:"kind"`
+	Provider string `json:"provider"`
+	Window   string `json:"window"`
+	Label    string `json:"label"`
+	// Threshold is the percentage crossed; zero for resets.
+	Threshold   int       `json:"threshold,omitempty"`
+	Utilization float64   `json:"utilization"`
+	ResetAt     time.Time `json:"reset_at,omitzero"`
+	Time        time.Time `json:"time"`
+	Message     string    `json:"message"`
+}
+
+// Title returns a short heading for the event, e.g. "Claude 5-hour at 90%".
+func (e Event) Title() string {
+	provider := "Claude"
+	if e.Provider == quota.ProviderCodex {
+		provider = "Codex"
+	}
+	if e.Kind == KindReset {
+		return fmt.Sprintf("%s %s window reset", provider, e.Label)
+	}
+	return fmt.Sprintf("%s %s at %d%%", provider, e.Label, percent(e.Utilization))
+}
+
+// Urgent reports whether the window is exhausted.
+func (e Event) Urgent() bool {
+	return e.Kind == KindThreshold && e.Threshold >= 100
+}
+
+// Sink delivers events.
+type Sink interface {
+	Notify(ctx context.Context, event Event) error
+}
+
+// windowState is what the notifier remembers about a window between checks.
+type windowState struct {
+	ResetAt time.Time `json:"reset_at"`
+	// Fired lists the thresholds already alerted in this cycle.
+	Fired []int `json:"fired,omitempty"`
+	// Peak is the highest utilization seen in this cycle.
+	Peak float64 `json:"peak"`
+}
+
+// State is the de-duplication state, keyed by provider and window key.
+type State map[string]*windowState
+
+// LoadState reads state from path. A missing file is an empty state.
+func LoadState(path string) (State, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return State{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read notify state: %w", err)
+	}
+
+	state := State{}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse notify state %s: %w", path, err)
+	}
+	return state, nil
+}
+
+// Save writes state to path atomically, creating parent directories.
+func (s State) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create state dir: %w", err)
+	}
+
+	data, err := json.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("failed to encode notify state: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".notify-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to write notify state: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write notify state: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write notify state: %w", err)
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Detect compares windows with state and returns the events to deliver,
+// updating state so each threshold alerts once per window cycle. When a
+// jump crosses several thresholds at once, only the highest is reported.
+// Resets are reported when resets is set and the finished cycle saw usage.
+func Detect(state State, windows []Window, thresholds []int, resets bool, now time.Time) []Event {
+	sorted := append([]int(nil), thresholds...)
+	sort.Ints(sorted)
+
+	var events []Event
+	for _, w := range windows {
+		// A reset time in the past means the data is stale (e.g. from the
+		// cache); it says nothing about the current cycle.
+		if !w.ResetAt.IsZero() && !w.ResetAt.After(now) {
+			continue
+		}
+
+		key := w.Provider + ":" + w.Key
+		ws, known := state[key]
+		if !known {
+			ws = &windowState{ResetAt: w.ResetAt}
+			state[key] = ws
+		}
+
+		if known && quota.NewCycle(ws.ResetAt, w.ResetAt, now) {
+			if resets && ws.Peak > 0 {
+				events = append(events, newEvent(KindReset, w, 0, now))
+			}
+			*ws = windowState{ResetAt: w.ResetAt}
+		}
+		if !w.ResetAt.IsZero() {
+			ws.ResetAt = w.ResetAt
+		}
+		ws.Peak = max(ws.Peak, w.Utilization)
+
+		crossed := 0
+		for _, threshold := range sorted {
+			if percent(w.Utilization) < threshold || ws.fired(threshold) {
+				continue
+			}
+			ws.Fired = append(ws.Fired, threshold)
+			crossed = threshold
+		}
+		if crossed > 0 {
+			events = append(events, newEvent(KindThreshold, w, crossed, now))
+		}
+	}
+	return events
+}
+
+func (ws *windowState) fired(threshold int) bool {
+	for _, f := range ws.Fired {
+		if f == threshold {
+			return true
+		}
+	}
+	return false
+}
+
+func newEvent(kind Kind, w Window, threshold int, now time.Time) Event {
+	event := Event{
+		Kind:        kind,
+		Provider:    w.Provider,
+		Window:      w.Key,
+		Label:       w.Label,
+		Threshold:   threshold,
+		Utilization: w.Utilization,
+		ResetAt:     w.ResetAt,
+		Time:        now,
+	}
+
+	switch kind {
+	case KindReset:
+		event.Message = fmt.Sprintf("%s: quota is available again.", event.Title())
+	default:
+		event.Message = fmt.Sprintf("%s (crossed %d%%)", event.Title(), threshold)
+		if reset := quota.ResetsIn(w.ResetAt, now); reset != "" {
+			event.Message += ", " + reset
+		}
+		event.Message += "."
+	}
+	return event
+}
+
+func percent(utilization float64) int {
+	return int(utilization * 100)
+}
+
+// Notifier checks windows against thresholds and delivers events to sinks.
+type Notifier struct {
+	Sinks      []Sink
+	Thresholds []int
+	// Resets also alerts when a window with usage resets.
+	Resets bool
+	// StatePath is where de-duplication state is kept between runs.
+	StatePath string
+}
+
+// Check detects events for windows, delivers them to every sink and saves
+// the state. Delivery errors are joined; state is saved regardless so a
+// failing sink does not cause repeated alerts through the others.
+func (n *Notifier) Check(ctx context.Context, windows []Window, now time.Time) ([]Event, error) {
+	state, err := LoadState(n.StatePath)
+	if err != nil {
+		return nil, err
+	}
+
+	events := Detect(state, windows, n.Thresholds, n.Resets, now)
+
+	var errs []error
+	for _, event := range events {
+		for _, sink := range n.Sinks {
+			if err := sink.Notify(ctx, event); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	if err := state.Save(n.StatePath); err != nil {
+		errs = append(errs, err)
+	}
+	return events, errors.Join(errs...)
+}
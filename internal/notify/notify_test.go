@@ -0,0 +1,168 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var checkTime = time.Date(2025, 11, 20, 12, 0, 0, 0, time.UTC)
+
+func claudeWindow(utilization float64, resetAt time.Time) Window {
+	return Window{Provider: "claude", Key: "5h", Label: "5-hour", Utilization: utilization, ResetAt: resetAt}
+}
+
+func TestDetect_AlertsOncePerThreshold(t *testing.T) {
+	state := State{}
+	resetAt := checkTime.Add(2 * time.Hour)
+	thresholds := []int{75, 90, 100}
+
+	if events := Detect(state, []Window{claudeWindow(0.5, resetAt)}, thresholds, true, checkTime); len(events) != 0 {
+		t.Fatalf("expected no events below thresholds, got %v", events)
+	}
+
+	events := Detect(state, []Window{claudeWindow(0.8, resetAt)}, thresholds, true, checkTime.Add(time.Minute))
+	if len(events) != 1 || events[0].Threshold != 75 {
+		t.Fatalf("expected one 75%% event, got %v", events)
+	}
+
+	if events := Detect(state, []Window{claudeWindow(0.85, resetAt)}, thresholds, true, checkTime.Add(2*time.Minute)); len(events) != 0 {
+		t.Errorf("expected 75%% not to alert again, got %v", events)
+	}
+}
+
+func TestDetect_ReportsHighestThresholdOfAJump(t *testing.T) {
+	state := State{}
+	events := Detect(state, []Window{claudeWindow(1.0, checkTime.Add(time.Hour))}, []int{90, 75, 100}, true, checkTime)
+
+	if len(events) != 1 {
+		t.Fatalf("expected one event, got %d", len(events))
+	}
+	if events[0].Threshold != 100 || !events[0].Urgent() {
+		t.Errorf("expected an urgent 100%% event, got %+v", events[0])
+	}
+	if fired := state["claude:5h"].Fired; len(fired) != 3 {
+		t.Errorf("expected every crossed threshold to be recorded, got %v", fired)
+	}
+}
+
+func TestDetect_ResetStartsNewCycle(t *testing.T) {
+	state := State{}
+	thresholds := []int{75}
+	firstReset := checkTime.Add(time.Hour)
+
+	Detect(state, []Window{claudeWindow(0.8, firstReset)}, thresholds, true, checkTime)
+
+	after := firstReset.Add(time.Minute)
+	events := Detect(state, []Window{claudeWindow(0.1, after.Add(5*time.Hour))}, thresholds, true, after)
+	if len(events) != 1 || events[0].Kind != KindReset {
+		t.Fatalf("expected a reset event, got %v", events)
+	}
+
+	events = Detect(state, []Window{claudeWindow(0.8, after.Add(5*time.Hour))}, thresholds, true, after.Add(time.Hour))
+	if len(events) != 1 || events[0].Threshold != 75 {
+		t.Errorf("expected 75%% to alert again in the new cycle, got %v", events)
+	}
+}
+
+func TestDetect_IgnoresResetJitter(t *testing.T) {
+	state := State{}
+	resetAt := checkTime.Add(time.Hour)
+
+	Detect(state, []Window{claudeWindow(0.8, resetAt)}, []int{75}, true, checkTime)
+	events := Detect(state, []Window{claudeWindow(0.8, resetAt.Add(30*time.Second))}, []int{75}, true, checkTime.Add(time.Minute))
+
+	if len(events) != 0 {
+		t.Errorf("expected jitter in the reset time to be ignored, got %v", events)
+	}
+}
+
+func TestDetect_ResetsDisabled(t *testing.T) {
+	state := State{}
+	firstReset := checkTime.Add(time.Hour)
+
+	Detect(state, []Window{claudeWindow(0.5, firstReset)}, []int{75}, false, checkTime)
+	after := firstReset.Add(time.Minute)
+	if events := Detect(state, []Window{claudeWindow(0, after.Add(5*time.Hour))}, []int{75}, false, after); len(events) != 0 {
+		t.Errorf("expected no reset event when disabled, got %v", events)
+	}
+}
+
+func TestDetect_SkipsStaleWindows(t *testing.T) {
+	state := State{}
+	stale := claudeWindow(0.95, checkTime.Add(-time.Minute))
+
+	if events := Detect(state, []Window{stale}, []int{90}, true, checkTime); len(events) != 0 {
+		t.Errorf("expected stale data to be ignored, got %v", events)
+	}
+}
+
+func TestEvent_Message(t *testing.T) {
+	w := Window{Provider: "codex", Key: "codex-5h", Label: "5-hour", Utilization: 0.91, ResetAt: checkTime.Add(90 * time.Minute)}
+	event := newEvent(KindThreshold, w, 90, checkTime)
+
+	if event.Title() != "Codex 5-hour at 91%" {
+		t.Errorf("unexpected title %q", event.Title())
+	}
+	if !strings.HasPrefix(event.Message, "Codex 5-hour at 91% (crossed 90%), ") || !strings.HasSuffix(event.Message, ".") {
+		t.Errorf("unexpected message %q", event.Message)
+	}
+}
+
+func TestState_SaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "state.json")
+
+	state, err := LoadState(path)
+	if err != nil {
+		t.Fatalf("unexpected error for missing state: %v", err)
+	}
+	Detect(state, []Window{claudeWindow(0.8, checkTime.Add(time.Hour))}, []int{75}, true, checkTime)
+
+	if err := state.Save(path); err != nil {
+		t.Fatalf("failed to save state: %v", err)
+	}
+	loaded, err := LoadState(path)
+	if err != nil {
+		t.Fatalf("failed to load state: %v", err)
+	}
+	if ws := loaded["claude:5h"]; ws == nil || len(ws.Fired) != 1 || ws.Fired[0] != 75 {
+		t.Errorf("expected fired thresholds to persist, got %+v", ws)
+	}
+}
+
+type recordingSink struct {
+	events []Event
+	err    error
+}
+
+func (s *recordingSink) Notify(_ context.Context, event Event) error {
+	s.events = append(s.events, event)
+	return s.err
+}
+
+func TestNotifier_Check(t *testing.T) {
+	failing := &recordingSink{err: errors.New("boom")}
+	working := &recordingSink{}
+	notifier := &Notifier{
+		Sinks:      []Sink{failing, working},
+		Thresholds: []int{75},
+		StatePath:  filepath.Join(t.TempDir(), "state.json"),
+	}
+	windows := []Window{claudeWindow(0.8, checkTime.Add(time.Hour))}
+
+	events, err := notifier.Check(context.Background(), windows, checkTime)
+	if err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("expected sink error to be returned, got %v", err)
+	}
+	if len(events) != 1 || len(working.events) != 1 {
+		t.Fatalf("expected the event to reach every sink, got %d events, %d delivered", len(events), len(working.events))
+	}
+
+	events, err = notifier.Check(context.Background(), windows, checkTime.Add(time.Minute))
+	if err != nil || len(events) != 0 {
+		t.Errorf("expected state to prevent a repeat alert, got %v, %v", events, err)
+	}
+}
@@ -0,0 +1,138 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// StdoutSink logs one line per event to W.
+type StdoutSink struct {
+	W io.Writer
+}
+
+// Notify writes the event as a timestamped line.
+func (s StdoutSink) Notify(_ context.Context, event Event) error {
+	_, err := fmt.Fprintf(s.W, "%s %s\n", event.Time.Format("2006-01-02 15:04:05"), event.Message)
+	return err
+}
+
+// DesktopSink shows a desktop notification with notify-send on Linux and
+// osascript on macOS.
+type DesktopSink struct {
+	// GOOS selects the notifier; empty uses runtime.GOOS.
+	GOOS string
+
+	run func(ctx context.Context, name string, args ...string) error
+}
+
+// Notify shows the event as a desktop notification.
+func (s DesktopSink) Notify(ctx context.Context, event Event) error {
+	goos := s.GOOS
+	if goos == "" {
+		goos = runtime.GOOS
+	}
+
+	run := s.run
+	if run == nil {
+		run = runCommand
+	}
+
+	switch goos {
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s", appleScriptString(event.Message), appleScriptString("ccstats"))
+		if event.Urgent() {
+			script += ` sound name "Basso"`
+		}
+		return run(ctx, "osascript", "-e", script)
+	case "linux", "freebsd", "openbsd", "netbsd":
+		urgency := "normal"
+		if event.Urgent() {
+			urgency = "critical"
+		}
+		return run(ctx, "notify-send", "--app-name=ccstats", "--urgency="+urgency, event.Title(), event.Message)
+	default:
+		return fmt.Errorf("desktop notifications are not supported on %s", goos)
+	}
+}
+
+// appleScriptString quotes s as an AppleScript string literal.
+func appleScriptString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
+
+func runCommand(ctx context.Context, name string, args ...string) error {
+	cmd := exec.CommandContext(ctx, name, args...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s failed: %w: %s", name, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// CommandSink runs a shell command for every event, passing the event as
+// JSON on stdin.
+type CommandSink struct {
+	Command string
+}
+
+// Notify runs the command with the event on stdin.
+func (s CommandSink) Notify(ctx context.Context, event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", s.Command)
+	cmd.Stdin = bytes.NewReader(append(payload, '\n'))
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("notify command failed: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// SinkOptions configures the sinks built by NewSinks.
+type SinkOptions struct {
+	// Command is run by the command sink.
+	Command string
+	// Webhook is used as the webhook sink.
+	Webhook WebhookSink
+	// Stdout is written to by the stdout sink.
+	Stdout io.Writer
+}
+
+// NewSinks builds the sinks named in names.
+func NewSinks(names []string, opts SinkOptions) ([]Sink, error) {
+	sinks := make([]Sink, 0, len(names))
+	for _, name := range names {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "desktop":
+			sinks = append(sinks, DesktopSink{})
+		case "command":
+			if opts.Command == "" {
+				return nil, errors.New("the command sink needs notify.command to be set")
+			}
+			sinks = append(sinks, CommandSink{Command: opts.Command})
+		case "webhook":
+			if opts.Webhook.URL == "" {
+				return nil, errors.New("the webhook sink needs notify.webhook.url to be set")
+			}
+			if _, err := WebhookPayload(opts.Webhook.Format, Event{}); err != nil {
+				return nil, err
+			}
+			sinks = append(sinks, opts.Webhook)
+		case "stdout":
+			sinks = append(sinks, StdoutSink{W: opts.Stdout})
+		default:
+			return nil, fmt.Errorf("unknown sink %q (want desktop, command, webhook or stdout)", name)
+		}
+	}
+	return sinks, nil
+}
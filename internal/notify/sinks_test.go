@@ -0,0 +1,136 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testEvent(threshold int) Event {
+	return newEvent(KindThreshold, claudeWindow(float64(threshold)/100, checkTime.Add(time.Hour)), threshold, checkTime)
+}
+
+func TestDesktopSink_Commands(t *testing.T) {
+	tests := []struct {
+		name      string
+		goos      string
+		threshold int
+		want      []string
+	}{
+		{
+			name:      "linux",
+			goos:      "linux",
+			threshold: 90,
+			want:      []string{"notify-send", "--app-name=ccstats", "--urgency=normal", "Claude 5-hour at 90%"},
+		},
+		{
+			name:      "linux exhausted",
+			goos:      "linux",
+			threshold: 100,
+			want:      []string{"notify-send", "--app-name=ccstats", "--urgency=critical", "Claude 5-hour at 100%"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			sink := DesktopSink{GOOS: tt.goos, run: func(_ context.Context, name string, args ...string) error {
+				got = append([]string{name}, args...)
+				return nil
+			}}
+
+			if err := sink.Notify(context.Background(), testEvent(tt.threshold)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(got) != len(tt.want)+1 || !reflect.DeepEqual(got[:len(tt.want)], tt.want) {
+				t.Errorf("expected %v followed by the message, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestDesktopSink_EscapesAppleScript(t *testing.T) {
+	var script string
+	sink := DesktopSink{GOOS: "darwin", run: func(_ context.Context, name string, args ...string) error {
+		script = args[len(args)-1]
+		return nil
+	}}
+
+	event := testEvent(90)
+	event.Message = `say "hi" \ bye`
+	if err := sink.Notify(context.Background(), event); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(script, `"say \"hi\" \\ bye"`) {
+		t.Errorf("expected quotes and backslashes to be escaped, got %s", script)
+	}
+}
+
+func TestDesktopSink_Unsupported(t *testing.T) {
+	if err := (DesktopSink{GOOS: "plan9"}).Notify(context.Background(), testEvent(90)); err == nil {
+		t.Error("expected error for unsupported platform, got nil")
+	}
+}
+
+func TestCommandSink_ReceivesJSON(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "event.json")
+	sink := CommandSink{Command: "cat > " + out}
+
+	if err := sink.Notify(context.Background(), testEvent(90)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("failed to read command output: %v", err)
+	}
+	var event Event
+	if err := json.Unmarshal(data, &event); err != nil {
+		t.Fatalf("expected JSON on stdin: %v", err)
+	}
+	if event.Threshold != 90 || event.Window != "5h" {
+		t.Errorf("unexpected event %+v", event)
+	}
+}
+
+func TestCommandSink_Failure(t *testing.T) {
+	err := CommandSink{Command: "echo nope >&2; exit 3"}.Notify(context.Background(), testEvent(90))
+	if err == nil || !strings.Contains(err.Error(), "nope") {
+		t.Errorf("expected error with command output, got %v", err)
+	}
+}
+
+func TestStdoutSink(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (StdoutSink{W: &buf}).Notify(context.Background(), testEvent(75)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "2025-11-20 12:00:00 Claude 5-hour at 75% (crossed 75%)") {
+		t.Errorf("unexpected line %q", buf.String())
+	}
+}
+
+func TestNewSinks(t *testing.T) {
+	if _, err := NewSinks([]string{"command"}, SinkOptions{}); err == nil {
+		t.Error("expected error for command sink without a command, got nil")
+	}
+	if _, err := NewSinks([]string{"webhook"}, SinkOptions{}); err == nil {
+		t.Error("expected error for webhook sink without a URL, got nil")
+	}
+	if _, err := NewSinks([]string{"webhook"}, SinkOptions{Webhook: WebhookSink{URL: "http://x", Format: "irc"}}); err == nil {
+		t.Error("expected error for unknown webhook format, got nil")
+	}
+	if _, err := NewSinks([]string{"pager"}, SinkOptions{}); err == nil {
+		t.Error("expected error for unknown sink, got nil")
+	}
+	sinks, err := NewSinks([]string{"desktop", "stdout"}, SinkOptions{Stdout: &bytes.Buffer{}})
+	if err != nil || len(sinks) != 2 {
+		t.Errorf("expected two sinks, got %d, %v", len(sinks), err)
+	}
+}
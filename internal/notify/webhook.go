@@ -0,0 +1,255 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// SignatureHeader carries the HMAC-SHA256 of the timestamp and body,
+	// formatted as "sha256=<hex>".
+	SignatureHeader = "X-Ccstats-Signature"
+	// TimestampHeader carries the Unix time the request was signed at.
+	TimestampHeader = "X-Ccstats-Timestamp"
+
+	defaultWebhookTimeout = 10 * time.Second
+	defaultWebhookBackoff = time.Second
+)
+
+// Webhook payload formats.
+const (
+	FormatGeneric = "generic"
+	FormatSlack   = "slack"
+	FormatTeams   = "teams"
+)
+
+// Colors used by the Slack and Teams presets.
+const (
+	colorUrgent  = "#d00000"
+	colorWarning = "#e8a317"
+	colorReset   = "#2eb886"
+)
+
+// WebhookSink POSTs events as JSON to an HTTP endpoint.
+type WebhookSink struct {
+	URL string
+	// Format is generic (the event itself), slack or teams.
+	Format string
+	// Secret signs each request with HMAC-SHA256 when set.
+	Secret string
+	// Retries is how many times a failed delivery is retried.
+	Retries int
+	// Backoff is the delay before the first retry; it doubles each time.
+	Backoff time.Duration
+	// DeadLetterPath is a file failed deliveries are appended to as JSON
+	// lines. Empty discards them.
+	DeadLetterPath string
+	Client         *http.Client
+
+	now func() time.Time
+}
+
+// deadLetter is one line of the dead-letter file.
+type deadLetter struct {
+	Time   time.Time `json:"time"`
+	Format string    `json:"format"`
+	Error  string    `json:"error"`
+	Event  Event     `json:"event"`
+}
+
+// Notify delivers the event, retrying network errors, 429 and 5xx
+// responses. An event that cannot be delivered is written to the
+// dead-letter file.
+func (s WebhookSink) Notify(ctx context.Context, event Event) error {
+	body, err := WebhookPayload(s.Format, event)
+	if err != nil {
+		return err
+	}
+
+	backoff := s.Backoff
+	if backoff <= 0 {
+		backoff = defaultWebhookBackoff
+	}
+
+	for attempt := 0; ; attempt++ {
+		retry, err := s.post(ctx, body)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt >= s.Retries || ctx.Err() != nil {
+			err = fmt.Errorf("webhook delivery failed after %d attempt(s): %w", attempt+1, err)
+			if dlErr := s.deadLetter(event, err); dlErr != nil {
+				return errors.Join(err, dlErr)
+			}
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// post sends one request, reporting whether a failure is worth retrying.
+func (s WebhookSink) post(ctx context.Context, body []byte) (retry bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("invalid webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "ccstats")
+
+	if s.Secret != "" {
+		now := time.Now
+		if s.now != nil {
+			now = s.now
+		}
+		timestamp := strconv.FormatInt(now().Unix(), 10)
+		req.Header.Set(TimestampHeader, timestamp)
+		req.Header.Set(SignatureHeader, Sign(s.Secret, timestamp, body))
+	}
+
+	client := s.Client
+	if client == nil {
+		client = &http.Client{Timeout: defaultWebhookTimeout}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		// The URL often embeds a token (Slack, Teams), so keep it out of
+		// the error.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return true, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		io.Copy(io.Discard, resp.Body)
+		return false, nil
+	}
+
+	detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	err = fmt.Errorf("webhook returned %s: %s", resp.Status, strings.TrimSpace(string(detail)))
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500, err
+}
+
+func (s WebhookSink) deadLetter(event Event, cause error) error {
+	if s.DeadLetterPath == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.DeadLetterPath), 0o700); err != nil {
+		return fmt.Errorf("failed to create dead-letter dir: %w", err)
+	}
+	f, err := os.OpenFile(s.DeadLetterPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open dead-letter file: %w", err)
+	}
+	defer f.Close()
+
+	line, err := json.Marshal(deadLetter{Time: time.Now(), Format: s.Format, Error: cause.Error(), Event: event})
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write dead-letter file: %w", err)
+	}
+	return nil
+}
+
+// Sign returns the signature of a webhook body: the HMAC-SHA256 of
+// "<timestamp>.<body>" keyed with secret, as "sha256=<hex>". Receivers
+// recompute it to verify the request and can reject stale timestamps.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// WebhookPayload encodes event in the given format.
+func WebhookPayload(format string, event Event) ([]byte, error) {
+	switch strings.ToLower(format) {
+	case "", FormatGeneric:
+		return json.Marshal(event)
+	case FormatSlack:
+		return json.Marshal(slackPayload(event))
+	case FormatTeams:
+		return json.Marshal(teamsPayload(event))
+	default:
+		return nil, fmt.Errorf("unknown webhook format %q (want generic, slack or teams)", format)
+	}
+}
+
+type slackMessage struct {
+	Text        string            `json:"text"`
+	Attachments []slackAttachment `json:"attachments"`
+}
+
+type slackAttachment struct {
+	Color    string `json:"color"`
+	Text     string `json:"text"`
+	Fallback string `json:"fallback"`
+}
+
+// slackPayload builds a Slack incoming-webhook message.
+func slackPayload(event Event) slackMessage {
+	return slackMessage{
+		Text: event.Title(),
+		Attachments: []slackAttachment{
+			{Color: eventColor(event), Text: event.Message, Fallback: event.Message},
+		},
+	}
+}
+
+type teamsCard struct {
+	Type       string `json:"@type"`
+	Context    string `json:"@context"`
+	Summary    string `json:"summary"`
+	ThemeColor string `json:"themeColor"`
+	Title      string `json:"title"`
+	Text       string `json:"text"`
+}
+
+// teamsPayload builds a Microsoft Teams incoming-webhook message card.
+func teamsPayload(event Event) teamsCard {
+	return teamsCard{
+		Type:       "MessageCard",
+		Context:    "https://schema.org/extensions",
+		Summary:    event.Title(),
+		ThemeColor: strings.TrimPrefix(eventColor(event), "#"),
+		Title:      event.Title(),
+		Text:       event.Message,
+	}
+}
+
+func eventColor(event Event) string {
+	switch {
+	case event.Kind == KindReset:
+		return colorReset
+	case event.Urgent():
+		return colorUrgent
+	default:
+		return colorWarning
+	}
+}
@@ -0,0 +1,166 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWebhookPayload_Formats(t *testing.T) {
+	event := testEvent(100)
+
+	tests := []struct {
+		format string
+		check  func(t *testing.T, payload map[string]any)
+	}{
+		{
+			format: FormatGeneric,
+			check: func(t *testing.T, payload map[string]any) {
+				if payload["kind"] != "threshold" || payload["threshold"] != float64(100) {
+					t.Errorf("expected the event itself, got %v", payload)
+				}
+			},
+		},
+		{
+			format: FormatSlack,
+			check: func(t *testing.T, payload map[string]any) {
+				if payload["text"] != "Claude 5-hour at 100%" {
+					t.Errorf("unexpected slack text %v", payload["text"])
+				}
+				attachment := payload["attachments"].([]any)[0].(map[string]any)
+				if attachment["color"] != colorUrgent {
+					t.Errorf("expected urgent color, got %v", attachment["color"])
+				}
+			},
+		},
+		{
+			format: FormatTeams,
+			check: func(t *testing.T, payload map[string]any) {
+				if payload["@type"] != "MessageCard" || payload["themeColor"] != "d00000" {
+					t.Errorf("unexpected teams card %v", payload)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			body, err := WebhookPayload(tt.format, event)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var payload map[string]any
+			if err := json.Unmarshal(body, &payload); err != nil {
+				t.Fatalf("invalid JSON: %v", err)
+			}
+			tt.check(t, payload)
+		})
+	}
+
+	if _, err := WebhookPayload("irc", event); err == nil {
+		t.Error("expected error for unknown format, got nil")
+	}
+}
+
+func TestWebhookSink_Signs(t *testing.T) {
+	var timestamp, signature string
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		timestamp = r.Header.Get(TimestampHeader)
+		signature = r.Header.Get(SignatureHeader)
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	sink := WebhookSink{URL: server.URL, Secret: "s3cret", now: func() time.Time { return checkTime }}
+	if err := sink.Notify(context.Background(), testEvent(90)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if timestamp != "1763640000" {
+		t.Errorf("expected the signing time, got %q", timestamp)
+	}
+	if want := Sign("s3cret", timestamp, body); signature != want {
+		t.Errorf("expected signature %s, got %s", want, signature)
+	}
+}
+
+func TestWebhookSink_RetriesServerErrors(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+	}))
+	defer server.Close()
+
+	sink := WebhookSink{URL: server.URL, Retries: 3, Backoff: time.Millisecond}
+	if err := sink.Notify(context.Background(), testEvent(90)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("expected 3 attempts, got %d", calls.Load())
+	}
+}
+
+func TestWebhookSink_DeadLetter(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		http.Error(w, "invalid_payload", http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "dead.jsonl")
+	sink := WebhookSink{URL: server.URL, Format: FormatSlack, Retries: 3, Backoff: time.Millisecond, DeadLetterPath: path}
+
+	err := sink.Notify(context.Background(), testEvent(90))
+	if err == nil || !strings.Contains(err.Error(), "invalid_payload") {
+		t.Fatalf("expected delivery error, got %v", err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("expected client errors not to be retried, got %d attempts", calls.Load())
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("expected dead-letter file: %v", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	if !scanner.Scan() {
+		t.Fatal("expected a dead-letter line")
+	}
+	var letter deadLetter
+	if err := json.Unmarshal(scanner.Bytes(), &letter); err != nil {
+		t.Fatalf("invalid dead-letter line: %v", err)
+	}
+	if letter.Event.Threshold != 90 || letter.Format != FormatSlack {
+		t.Errorf("unexpected dead letter %+v", letter)
+	}
+}
+
+func TestWebhookSink_HidesURLInErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := server.URL + "/services/T000/B000/token"
+	server.Close()
+
+	err := WebhookSink{URL: url}.Notify(context.Background(), testEvent(90))
+	if err == nil {
+		t.Fatal("expected error for closed server, got nil")
+	}
+	if strings.Contains(err.Error(), "token") {
+		t.Errorf("expected the URL to be kept out of the error, got %v", err)
+	}
+}
@@ -0,0 +1,157 @@
+package pricing
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Providers whose usage is priced.
+const (
+	ProviderClaude = "claude"
+	ProviderCodex  = "codex"
+)
+
+// GroupBy selects how costs are aggregated.
+type GroupBy string
+
+const (
+	GroupByDay     GroupBy = "day"
+	GroupByProject GroupBy = "project"
+	GroupByModel   GroupBy = "model"
+)
+
+// ParseGroupBy parses a grouping name.
+func ParseGroupBy(s string) (GroupBy, error) {
+	switch GroupBy(strings.ToLower(strings.TrimSpace(s))) {
+	case GroupByDay:
+		return GroupByDay, nil
+	case GroupByProject:
+		return GroupByProject, nil
+	case GroupByModel:
+		return GroupByModel, nil
+	default:
+		return "", fmt.Errorf("unknown grouping %q (want day, project or model)", s)
+	}
+}
+
+// Usage is one priced unit of token usage from either provider.
+type Usage struct {
+	Time     time.Time
+	Provider string
+	Project  string
+	Model    string
+	Tokens   Tokens
+}
+
+// Row is the aggregated cost of one group.
+type Row struct {
+	Key    string  `json:"key"`
+	Tokens int64   `json:"tokens"`
+	Claude float64 `json:"claude"`
+	Codex  float64 `json:"codex"`
+	// Unpriced counts tokens of models missing from the price table.
+	Unpriced int64 `json:"unpriced_tokens,omitempty"`
+}
+
+// Total returns the combined cost of both providers.
+func (r Row) Total() float64 {
+	return r.Claude + r.Codex
+}
+
+// Aggregator prices usage and sums it into rows.
+type Aggregator struct {
+	table    *Table
+	by       GroupBy
+	location *time.Location
+	rows     map[string]*Row
+	unpriced map[string]int64
+}
+
+// NewAggregator returns an aggregator pricing usage with table and grouping
+// by by. Days are split at midnight in loc.
+func NewAggregator(table *Table, by GroupBy, loc *time.Location) *Aggregator {
+	if loc == nil {
+		loc = time.Local
+	}
+	return &Aggregator{
+		table:    table,
+		by:       by,
+		location: loc,
+		rows:     make(map[string]*Row),
+		unpriced: make(map[string]int64),
+	}
+}
+
+// Add prices and counts usage.
+func (a *Aggregator) Add(u Usage) {
+	var key string
+	switch a.by {
+	case GroupByProject:
+		key = u.Project
+	case GroupByModel:
+		key = u.Model
+	default:
+		key = u.Time.In(a.location).Format("2006-01-02")
+	}
+
+	row, ok := a.rows[key]
+	if !ok {
+		row = &Row{Key: key}
+		a.rows[key] = row
+	}
+	row.Tokens += u.Tokens.Total()
+
+	rates, ok := a.table.Lookup(u.Model)
+	if !ok {
+		row.Unpriced += u.Tokens.Total()
+		a.unpriced[u.Model] += u.Tokens.Total()
+		return
+	}
+
+	cost := rates.Cost(u.Tokens)
+	if u.Provider == ProviderCodex {
+		row.Codex += cost
+	} else {
+		row.Claude += cost
+	}
+}
+
+// Rows returns the aggregated rows. Days are in chronological order; other
+// groupings put the most expensive first.
+func (a *Aggregator) Rows() []Row {
+	rows := make([]Row, 0, len(a.rows))
+	for _, row := range a.rows {
+		rows = append(rows, *row)
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		if a.by == GroupByDay {
+			return rows[i].Key < rows[j].Key
+		}
+		if ci, cj := rows[i].Total(), rows[j].Total(); ci != cj {
+			return ci > cj
+		}
+		return rows[i].Key < rows[j].Key
+	})
+	return rows
+}
+
+// Unpriced returns the models missing from the price table with their token
+// counts.
+func (a *Aggregator) Unpriced() map[string]int64 {
+	return a.unpriced
+}
+
+// Total sums rows.
+func Total(rows []Row) Row {
+	total := Row{Key: "Total"}
+	for _, row := range rows {
+		total.Tokens += row.Tokens
+		total.Claude += row.Claude
+		total.Codex += row.Codex
+		total.Unpriced += row.Unpriced
+	}
+	return total
+}
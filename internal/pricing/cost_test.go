@@ -0,0 +1,62 @@
+package pricing
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestAggregator(t *testing.T) {
+	table := &Table{Models: []Model{
+		{Name: "Sonnet", Match: []string{"claude-sonnet"}, Rates: Rates{Input: 3, Output: 15}},
+		{Name: "GPT-5", Match: []string{"gpt-5"}, Rates: Rates{Input: 1.25, Output: 10}},
+	}}
+	day1 := time.Date(2026, 1, 15, 10, 0, 0, 0, time.UTC)
+	day2 := time.Date(2026, 1, 16, 10, 0, 0, 0, time.UTC)
+	usage := []Usage{
+		{Time: day1, Provider: ProviderClaude, Project: "/api", Model: "claude-sonnet-4-5", Tokens: Tokens{Input: 1_000_000}},
+		{Time: day2, Provider: ProviderCodex, Project: "/api", Model: "gpt-5-codex", Tokens: Tokens{Output: 1_000_000}},
+		{Time: day2, Provider: ProviderClaude, Project: "/web", Model: "mystery-model", Tokens: Tokens{Input: 500}},
+	}
+
+	byDay := NewAggregator(table, GroupByDay, time.UTC)
+	byProject := NewAggregator(table, GroupByProject, time.UTC)
+	for _, u := range usage {
+		byDay.Add(u)
+		byProject.Add(u)
+	}
+
+	days := byDay.Rows()
+	if len(days) != 2 || days[0].Key != "2026-01-15" {
+		t.Fatalf("unexpected day rows: %+v", days)
+	}
+	if days[0].Claude != 3 || days[1].Codex != 10 {
+		t.Errorf("expected $3 of Claude and $10 of Codex, got %+v", days)
+	}
+	if days[1].Unpriced != 500 {
+		t.Errorf("expected 500 unpriced tokens, got %d", days[1].Unpriced)
+	}
+
+	projects := byProject.Rows()
+	if projects[0].Key != "/api" || projects[0].Total() != 13 {
+		t.Errorf("expected /api first at $13, got %+v", projects)
+	}
+
+	if got := byDay.Unpriced()["mystery-model"]; got != 500 {
+		t.Errorf("expected mystery-model to be unpriced, got %d", got)
+	}
+
+	total := Total(days)
+	if math.Abs(total.Total()-13) > 1e-9 || total.Tokens != 2_000_500 {
+		t.Errorf("unexpected total: %+v", total)
+	}
+}
+
+func TestParseGroupBy(t *testing.T) {
+	if by, err := ParseGroupBy("MODEL"); err != nil || by != GroupByModel {
+		t.Errorf("expected model grouping, got %q, %v", by, err)
+	}
+	if _, err := ParseGroupBy("session"); err == nil {
+		t.Error("expected error for unsupported grouping")
+	}
+}
@@ -0,0 +1,185 @@
+// Package pricing estimates what token usage would cost at API rates, using
+// an embedded, versioned price table that a user file can override.
+package pricing
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Version is the newest price table format this build reads.
+const Version = 1
+
+// lastUpdatedLayout is the date format of the last_updated field.
+const lastUpdatedLayout = "2006-01-02"
+
+// perMillion converts per-million-token rates to per-token costs.
+const perMillion = 1_000_000
+
+//go:embed pricing.json
+var embeddedTable []byte
+
+// Rates are prices per million tokens.
+type Rates struct {
+	Input      float64 `json:"input"`
+	Output     float64 `json:"output"`
+	CacheWrite float64 `json:"cache_write,omitempty"`
+	CacheRead  float64 `json:"cache_read,omitempty"`
+}
+
+// Tokens are the token counts to price. Input excludes cached and cache
+// write tokens.
+type Tokens struct {
+	Input      int64
+	Output     int64
+	CacheWrite int64
+	CacheRead  int64
+}
+
+// Total returns the sum of all token counts.
+func (t Tokens) Total() int64 {
+	return t.Input + t.Output + t.CacheWrite + t.CacheRead
+}
+
+// Cost returns the price of tokens at these rates.
+func (r Rates) Cost(t Tokens) float64 {
+	return (float64(t.Input)*r.Input +
+		float64(t.Output)*r.Output +
+		float64(t.CacheWrite)*r.CacheWrite +
+		float64(t.CacheRead)*r.CacheRead) / perMillion
+}
+
+// Model is the price of one model family.
+type Model struct {
+	Name string `json:"name"`
+	// Match lists model ID prefixes, e.g. "claude-sonnet-4" matches
+	// "claude-sonnet-4-5-20250929". The longest matching prefix wins.
+	Match []string `json:"match"`
+	Rates
+}
+
+// Table is a versioned set of model prices.
+type Table struct {
+	Version     int     `json:"version"`
+	LastUpdated string  `json:"last_updated"`
+	Currency    string  `json:"currency"`
+	Models      []Model `json:"models"`
+
+	// Path is the file the table was read from. It is empty for the
+	// built-in table.
+	Path string `json:"-"`
+}
+
+// Origin describes where the table came from: "built-in" or the file path.
+func (t *Table) Origin() string {
+	if t.Path == "" {
+		return "built-in"
+	}
+	return t.Path
+}
+
+// Lookup returns the rates for a model ID.
+func (t *Table) Lookup(model string) (Rates, bool) {
+	model = strings.ToLower(model)
+
+	var best *Model
+	bestLen := 0
+	for i := range t.Models {
+		for _, prefix := range t.Models[i].Match {
+			if strings.HasPrefix(model, strings.ToLower(prefix)) && len(prefix) > bestLen {
+				best, bestLen = &t.Models[i], len(prefix)
+			}
+		}
+	}
+	if best == nil {
+		return Rates{}, false
+	}
+	return best.Rates, true
+}
+
+// Parse decodes and validates a price table.
+func Parse(data []byte) (*Table, error) {
+	var table Table
+	if err := json.Unmarshal(data, &table); err != nil {
+		return nil, err
+	}
+	if err := table.validate(); err != nil {
+		return nil, err
+	}
+	return &table, nil
+}
+
+// Load reads a price table from path, returning the built-in table if path
+// is empty or does not exist.
+func Load(path string) (*Table, error) {
+	if path == "" {
+		return Embedded(), nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Embedded(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pricing: %w", err)
+	}
+
+	table, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pricing %s: %w", path, err)
+	}
+	table.Path = path
+	return table, nil
+}
+
+// Embedded returns the price table compiled into this build.
+func Embedded() *Table {
+	table, err := Parse(embeddedTable)
+	if err != nil {
+		panic(fmt.Sprintf("pricing: invalid embedded table: %v", err))
+	}
+	return table
+}
+
+func (t *Table) validate() error {
+	if t.Version < 1 {
+		return errors.New("missing version")
+	}
+	if t.Version > Version {
+		return fmt.Errorf("unsupported version %d (this build reads up to %d)", t.Version, Version)
+	}
+	if t.LastUpdated != "" {
+		if _, err := time.Parse(lastUpdatedLayout, t.LastUpdated); err != nil {
+			return fmt.Errorf("last_updated must be a date like 2025-11-25, got %q", t.LastUpdated)
+		}
+	}
+	if len(t.Models) == 0 {
+		return errors.New("no models")
+	}
+
+	seen := make(map[string]string)
+	for _, m := range t.Models {
+		if m.Name == "" {
+			return errors.New("model without a name")
+		}
+		if len(m.Match) == 0 {
+			return fmt.Errorf("model %q has no match prefixes", m.Name)
+		}
+		for _, prefix := range m.Match {
+			key := strings.ToLower(prefix)
+			if other, ok := seen[key]; ok {
+				return fmt.Errorf("prefix %q is listed by both %q and %q", prefix, other, m.Name)
+			}
+			seen[key] = m.Name
+		}
+		if m.Input < 0 || m.Output < 0 || m.CacheWrite < 0 || m.CacheRead < 0 {
+			return fmt.Errorf("model %q has a negative rate", m.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package pricing
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEmbedded_Lookup(t *testing.T) {
+	table := Embedded()
+
+	tests := []struct {
+		model string
+		input float64
+	}{
+		{"claude-sonnet-4-5-20250929", 3},
+		{"claude-opus-4-1-20250805", 15},
+		{"claude-opus-4-5-20251101", 5},
+		{"claude-haiku-4-5-20251001", 1},
+		{"gpt-5-codex", 1.25},
+		{"gpt-5.1-codex-mini", 0.25},
+		{"GPT-5", 1.25},
+	}
+	for _, tt := range tests {
+		rates, ok := table.Lookup(tt.model)
+		if !ok {
+			t.Errorf("expected rates for %q", tt.model)
+			continue
+		}
+		if rates.Input != tt.input {
+			t.Errorf("Lookup(%q) input = %v, want %v", tt.model, rates.Input, tt.input)
+		}
+	}
+
+	if _, ok := table.Lookup("llama-3"); ok {
+		t.Error("expected no rates for an unknown model")
+	}
+}
+
+func TestRates_Cost(t *testing.T) {
+	rates := Rates{Input: 3, Output: 15, CacheWrite: 3.75, CacheRead: 0.3}
+	cost := rates.Cost(Tokens{Input: 1_000_000, Output: 100_000, CacheWrite: 200_000, CacheRead: 10_000_000})
+
+	if want := 3 + 1.5 + 0.75 + 3.0; math.Abs(cost-want) > 1e-9 {
+		t.Errorf("expected cost %v, got %v", want, cost)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"missing version", `{"models":[{"name":"a","match":["a"]}]}`, "missing version"},
+		{"future version", `{"version":2,"models":[{"name":"a","match":["a"]}]}`, "unsupported version 2"},
+		{"bad date", `{"version":1,"last_updated":"soon","models":[{"name":"a","match":["a"]}]}`, "last_updated"},
+		{"no models", `{"version":1,"models":[]}`, "no models"},
+		{"no prefixes", `{"version":1,"models":[{"name":"a"}]}`, "no match prefixes"},
+		{"duplicate prefix", `{"version":1,"models":[{"name":"a","match":["x"]},{"name":"b","match":["X"]}]}`, `prefix "X" is listed by both "a" and "b"`},
+		{"negative rate", `{"version":1,"models":[{"name":"a","match":["a"],"input":-1}]}`, "negative rate"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestLoad_Override(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pricing.json")
+	content := `{"version":1,"last_updated":"2026-02-01","currency":"EUR","models":[{"name":"Sonnet","match":["claude-sonnet"],"input":2.5,"output":12}]}`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write pricing: %v", err)
+	}
+
+	table, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if table.Origin() != path || table.Currency != "EUR" {
+		t.Errorf("unexpected table: %+v", table)
+	}
+	if rates, ok := table.Lookup("claude-sonnet-4-5"); !ok || rates.Input != 2.5 {
+		t.Errorf("expected override rates, got %+v", rates)
+	}
+}
+
+func TestLoad_MissingFileUsesEmbedded(t *testing.T) {
+	table, err := Load(filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if table.Origin() != "built-in" {
+		t.Errorf("expected built-in table, got %q", table.Origin())
+	}
+}
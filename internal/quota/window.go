@@ -0,0 +1,202 @@
+// Package quota describes the rate-limit windows of both providers
+// independently of how they are shown.
+package quota
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/uesteibar/ccstats/internal/api"
+	"github.com/uesteibar/ccstats/internal/codex"
+)
+
+const (
+	// ProviderClaude identifies windows reported by the Anthropic usage endpoint.
+	ProviderClaude = "claude"
+	// ProviderCodex identifies windows reported by the Codex app-server.
+	ProviderCodex = "codex"
+)
+
+// CycleTolerance absorbs jitter in the reported reset time of a cycle; a
+// window only counts as reset when its reset time moves further than this.
+const CycleTolerance = 2 * time.Minute
+
+// Window is a single rate-limit window from either provider, flattened so
+// renderers and the background features can treat Claude and Codex limits
+// alike.
+type Window struct {
+	Provider string
+	// Key is a short identifier such as "5h", "7d-sonnet" or "codex-7d".
+	Key   string
+	Label string
+	// Duration is the length of the window, zero when unknown.
+	Duration time.Duration
+	Metric   api.UsageMetric
+}
+
+// Windows returns every known window for the given usage data.
+// Either argument may be nil.
+func Windows(usage *api.UsageResponse, codexUsage *codex.Usage) []Window {
+	var windows []Window
+	windows = append(windows, ClaudeWindows(usage)...)
+	windows = append(windows, CodexWindows(codexUsage)...)
+	return windows
+}
+
+// FilterWindows returns the windows whose keys appear in keys, in the order
+// the keys are given. An empty keys slice returns all windows unchanged.
+func FilterWindows(windows []Window, keys []string) []Window {
+	if len(keys) == 0 {
+		return windows
+	}
+
+	var filtered []Window
+	for _, key := range keys {
+		for _, window := range windows {
+			if window.Key == key {
+				filtered = append(filtered, window)
+			}
+		}
+	}
+	return filtered
+}
+
+// ClaudeWindows returns the Claude windows of usage, which may be nil.
+func ClaudeWindows(usage *api.UsageResponse) []Window {
+	if usage == nil {
+		return nil
+	}
+
+	return []Window{
+		{Provider: ProviderClaude, Key: "5h", Label: "5-hour", Duration: 5 * time.Hour, Metric: usage.FiveHour},
+		{Provider: ProviderClaude, Key: "7d", Label: "7-day", Duration: 7 * 24 * time.Hour, Metric: usage.SevenDay},
+		{Provider: ProviderClaude, Key: "7d-sonnet", Label: "7-day Sonnet", Duration: 7 * 24 * time.Hour, Metric: usage.SevenDaySonnet},
+	}
+}
+
+// CodexWindows returns the Codex windows of usage, which may be nil.
+func CodexWindows(usage *codex.Usage) []Window {
+	if usage == nil {
+		return nil
+	}
+
+	var windows []Window
+	for _, w := range []*codex.UsageWindow{usage.Primary, usage.Secondary} {
+		if w == nil {
+			continue
+		}
+		windows = append(windows, Window{
+			Provider: ProviderCodex,
+			Key:      "codex-" + keyForWindow(w.WindowDurationMins),
+			Label:    labelForWindow(w.WindowDurationMins),
+			Duration: time.Duration(w.WindowDurationMins) * time.Minute,
+			Metric: api.UsageMetric{
+				Utilization: w.Utilization,
+				ResetAt:     w.ResetAt,
+			},
+		})
+	}
+	return windows
+}
+
+func keyForWindow(windowMins int64) string {
+	switch {
+	case windowMins <= 0:
+		return "limit"
+	case windowMins%1440 == 0:
+		return fmt.Sprintf("%dd", windowMins/1440)
+	case windowMins%60 == 0:
+		return fmt.Sprintf("%dh", windowMins/60)
+	default:
+		return fmt.Sprintf("%dm", windowMins)
+	}
+}
+
+// Start returns when the window began: its reset time minus its duration,
+// or now minus its duration when no reset time is known.
+func (w Window) Start(now time.Time) time.Time {
+	if w.Metric.ResetAt.IsZero() {
+		return now.Add(-w.Duration)
+	}
+	return w.Metric.ResetAt.Add(-w.Duration)
+}
+
+func labelForWindow(windowMins int64) string {
+	if windowMins <= 0 {
+		return "Limit"
+	}
+
+	if windowMins%1440 == 0 {
+		days := windowMins / 1440
+		if days == 1 {
+			return "1-day"
+		}
+		return fmt.Sprintf("%d-day", days)
+	}
+
+	if windowMins%60 == 0 {
+		hours := windowMins / 60
+		if hours == 1 {
+			return "1-hour"
+		}
+		return fmt.Sprintf("%d-hour", hours)
+	}
+
+	return fmt.Sprintf("%d-min", windowMins)
+}
+
+// NewCycle reports whether a window whose cycle was due to reset at saved
+// has reset by now, given the reset time of a current reading: that time
+// moved on, or saved has passed and the reading has none because the window
+// was not used since. It is false when saved is unknown.
+func NewCycle(saved, current, now time.Time) bool {
+	if saved.IsZero() {
+		return false
+	}
+	if current.IsZero() {
+		return !now.Before(saved)
+	}
+	return current.Sub(saved) > CycleTolerance
+}
+
+// ResetsIn describes how long until resetAt from now, e.g. "resets in 3d 5h".
+// It returns "" when resetAt is unknown.
+func ResetsIn(resetAt time.Time, now time.Time) string {
+	if resetAt.IsZero() {
+		return ""
+	}
+
+	duration := resetAt.Sub(now)
+
+	if duration <= 0 {
+		return "resets now"
+	}
+
+	totalHours := int(duration.Hours())
+	days := totalHours / 24
+	hours := totalHours % 24
+	minutes := int(duration.Minutes()) % 60
+
+	if days > 0 {
+		if hours > 0 && minutes > 0 {
+			return fmt.Sprintf("resets in %dd %dh %dm", days, hours, minutes)
+		} else if hours > 0 {
+			return fmt.Sprintf("resets in %dd %dh", days, hours)
+		} else if minutes > 0 {
+			return fmt.Sprintf("resets in %dd %dm", days, minutes)
+		}
+		return fmt.Sprintf("resets in %dd", days)
+	}
+
+	if hours > 0 && minutes > 0 {
+		return fmt.Sprintf("resets in %dh %dm", hours, minutes)
+	} else if hours > 0 {
+		return fmt.Sprintf("resets in %dh", hours)
+	} else if minutes > 0 {
+		return fmt.Sprintf("resets in %dm", minutes)
+	}
+
+	// Less than a minute
+	seconds := int(duration.Seconds())
+	return fmt.Sprintf("resets in %ds", seconds)
+}
@@ -0,0 +1,76 @@
+package quota
+
+import (
+	"testing"
+	"time"
+
+	"github.com/uesteibar/ccstats/internal/api"
+	"github.com/uesteibar/ccstats/internal/codex"
+)
+
+func TestWindows(t *testing.T) {
+	usage := &api.UsageResponse{
+		FiveHour: api.UsageMetric{Utilization: 0.1},
+	}
+	codexUsage := &codex.Usage{
+		Primary:   &codex.UsageWindow{WindowDurationMins: 300, Utilization: 0.2, ResetAt: time.Now()},
+		Secondary: &codex.UsageWindow{WindowDurationMins: 10080, Utilization: 0.3},
+	}
+
+	windows := Windows(usage, codexUsage)
+
+	wantKeys := []string{"5h", "7d", "7d-sonnet", "codex-5h", "codex-7d"}
+	if len(windows) != len(wantKeys) {
+		t.Fatalf("expected %d windows, got %d", len(wantKeys), len(windows))
+	}
+	for i, key := range wantKeys {
+		if windows[i].Key != key {
+			t.Errorf("window %d: expected key %q, got %q", i, key, windows[i].Key)
+		}
+	}
+	if windows[3].Provider != ProviderCodex || windows[3].Label != "5-hour" {
+		t.Errorf("unexpected codex window: %+v", windows[3])
+	}
+}
+
+func TestWindows_NilUsage(t *testing.T) {
+	if windows := Windows(nil, nil); len(windows) != 0 {
+		t.Errorf("expected no windows, got %d", len(windows))
+	}
+}
+
+func TestFilterWindows(t *testing.T) {
+	windows := Windows(&api.UsageResponse{}, nil)
+
+	filtered := FilterWindows(windows, []string{"7d", "5h", "missing"})
+	if len(filtered) != 2 || filtered[0].Key != "7d" || filtered[1].Key != "5h" {
+		t.Errorf("unexpected filtered windows: %+v", filtered)
+	}
+
+	if all := FilterWindows(windows, nil); len(all) != len(windows) {
+		t.Errorf("expected all windows for empty keys, got %d", len(all))
+	}
+}
+
+func TestNewCycle(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name           string
+		saved, current time.Time
+		want           bool
+	}{
+		{"unknown saved reset", time.Time{}, now.Add(time.Hour), false},
+		{"same cycle", now.Add(time.Hour), now.Add(time.Hour), false},
+		{"jitter", now.Add(time.Hour), now.Add(time.Hour + time.Minute), false},
+		{"reset moved on", now.Add(-time.Minute), now.Add(5 * time.Hour), true},
+		{"no current reset before saved", now.Add(time.Hour), time.Time{}, false},
+		{"no current reset after saved", now.Add(-time.Minute), time.Time{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewCycle(tt.saved, tt.current, now); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
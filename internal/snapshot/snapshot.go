@@ -0,0 +1,248 @@
+// Package snapshot combines Claude and Codex usage into a single value and
+// caches it on disk so latency-sensitive consumers can fall back to it.
+package snapshot
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/uesteibar/ccstats/internal/api"
+	"github.com/uesteibar/ccstats/internal/codex"
+)
+
+// Snapshot is the usage of both providers at a point in time.
+type Snapshot struct {
+	// FetchedAt is when either provider last answered.
+	FetchedAt time.Time `json:"fetched_at"`
+	// ClaudeFetchedAt and CodexFetchedAt are when each provider's data was
+	// fetched, which is older than FetchedAt when it came from the cache.
+	ClaudeFetchedAt time.Time          `json:"claude_fetched_at,omitzero"`
+	CodexFetchedAt  time.Time          `json:"codex_fetched_at,omitzero"`
+	Claude          *api.UsageResponse `json:"claude,omitempty"`
+	Codex           *codex.Usage       `json:"codex,omitempty"`
+}
+
+// claudeAt returns when the Claude data was fetched. Caches written before
+// per-provider times were recorded only have FetchedAt.
+func (s *Snapshot) claudeAt() time.Time {
+	if s.ClaudeFetchedAt.IsZero() {
+		return s.FetchedAt
+	}
+	return s.ClaudeFetchedAt
+}
+
+// codexAt returns when the Codex data was fetched.
+func (s *Snapshot) codexAt() time.Time {
+	if s.CodexFetchedAt.IsZero() {
+		return s.FetchedAt
+	}
+	return s.CodexFetchedAt
+}
+
+// lateFetchTimeout bounds a provider fetch, which may outlive the ctx of
+// Fetch so that its result still reaches the cache.
+const lateFetchTimeout = 30 * time.Second
+
+// Errors holds the per-provider errors of a fetch. A provider whose fetch
+// failed may still be present in the snapshot with cached data.
+type Errors struct {
+	Claude error
+	Codex  error
+}
+
+// Fetcher retrieves usage from both providers concurrently and keeps the
+// on-disk cache up to date.
+type Fetcher struct {
+	Claude func(ctx context.Context) (*api.UsageResponse, error)
+	Codex  func(ctx context.Context) (*codex.Usage, error)
+	// CachePath is where the last snapshot is stored. Empty disables caching.
+	CachePath string
+	// MaxAge lets Fetch return the cached snapshot without fetching when it
+	// is younger than this. Zero always fetches.
+	MaxAge time.Duration
+
+	now  func() time.Time
+	late sync.WaitGroup
+}
+
+type claudeResult struct {
+	usage *api.UsageResponse
+	err   error
+}
+
+type codexResult struct {
+	usage *codex.Usage
+	err   error
+}
+
+// Fetch returns the current usage, waiting at most until ctx is done.
+// Providers whose cached data is younger than MaxAge are not fetched again.
+// Providers that fail or do not answer in time fall back to the cached
+// snapshot, keeping the time it was fetched; a provider that answers later
+// still updates the cache, see Wait. A nil Claude or Codex func skips that
+// provider.
+func (f *Fetcher) Fetch(ctx context.Context) (*Snapshot, Errors) {
+	now := f.clock()
+
+	snap := &Snapshot{}
+	cached := f.loadCache()
+	claudeDone, codexDone := f.Claude == nil, f.Codex == nil
+	if cached != nil {
+		if !claudeDone && cached.Claude != nil && f.recent(cached.claudeAt(), now) {
+			snap.Claude, snap.ClaudeFetchedAt, claudeDone = cached.Claude, cached.claudeAt(), true
+		}
+		if !codexDone && cached.Codex != nil && f.recent(cached.codexAt(), now) {
+			snap.Codex, snap.CodexFetchedAt, codexDone = cached.Codex, cached.codexAt(), true
+		}
+		if claudeDone && codexDone {
+			return cached, Errors{}
+		}
+	}
+
+	claudeCh := make(chan claudeResult, 1)
+	codexCh := make(chan codexResult, 1)
+
+	// Providers are not cancelled with ctx, which only bounds how long
+	// Fetch waits for them.
+	fetchCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), lateFetchTimeout)
+	var running sync.WaitGroup
+
+	if !claudeDone {
+		running.Add(1)
+		go func() {
+			defer running.Done()
+			usage, err := f.Claude(fetchCtx)
+			claudeCh <- claudeResult{usage, err}
+		}()
+	}
+
+	if !codexDone {
+		running.Add(1)
+		go func() {
+			defer running.Done()
+			usage, err := f.Codex(fetchCtx)
+			codexCh <- codexResult{usage, err}
+		}()
+	}
+
+	go func() {
+		running.Wait()
+		cancel()
+	}()
+
+	var errs Errors
+	var fresh, claudeLate, codexLate bool
+
+	for !claudeDone || !codexDone {
+		select {
+		case res := <-claudeCh:
+			claudeDone = true
+			snap.Claude, errs.Claude = res.usage, res.err
+			if res.err == nil && res.usage != nil {
+				snap.ClaudeFetchedAt, fresh = now, true
+			}
+		case res := <-codexCh:
+			codexDone = true
+			snap.Codex, errs.Codex = res.usage, res.err
+			if res.err == nil && res.usage != nil {
+				snap.CodexFetchedAt, fresh = now, true
+			}
+		case <-ctx.Done():
+			if !claudeDone {
+				errs.Claude, claudeLate = ctx.Err(), true
+			}
+			if !codexDone {
+				errs.Codex, codexLate = ctx.Err(), true
+			}
+			claudeDone, codexDone = true, true
+		}
+	}
+
+	if snap.Claude == nil && cached != nil && cached.Claude != nil {
+		snap.Claude, snap.ClaudeFetchedAt = cached.Claude, cached.claudeAt()
+	}
+	if snap.Codex == nil && cached != nil && cached.Codex != nil {
+		snap.Codex, snap.CodexFetchedAt = cached.Codex, cached.codexAt()
+	}
+
+	if !fresh {
+		if cached != nil {
+			snap.FetchedAt = cached.FetchedAt
+		}
+	} else {
+		snap.FetchedAt = now
+		if f.CachePath != "" {
+			_ = Save(f.CachePath, snap)
+		}
+	}
+
+	if (claudeLate || codexLate) && f.CachePath != "" {
+		f.late.Add(1)
+		go func() {
+			defer f.late.Done()
+			f.saveLate(claudeLate, codexLate, claudeCh, codexCh)
+		}()
+	}
+	return snap, errs
+}
+
+// Wait blocks until the providers that did not answer within their Fetch
+// have answered and their results are cached. Short-lived commands call it
+// before exiting so the next run finds fresh data.
+func (f *Fetcher) Wait() {
+	f.late.Wait()
+}
+
+// saveLate waits for the pending provider results and merges the ones that
+// succeeded into the cache.
+func (f *Fetcher) saveLate(claudePending, codexPending bool, claudeCh <-chan claudeResult, codexCh <-chan codexResult) {
+	var claudeRes claudeResult
+	var codexRes codexResult
+	if claudePending {
+		claudeRes = <-claudeCh
+	}
+	if codexPending {
+		codexRes = <-codexCh
+	}
+
+	snap := f.loadCache()
+	if snap == nil {
+		snap = &Snapshot{}
+	}
+	now := f.clock()
+	fresh := false
+	if claudeRes.err == nil && claudeRes.usage != nil {
+		snap.Claude, snap.ClaudeFetchedAt, fresh = claudeRes.usage, now, true
+	}
+	if codexRes.err == nil && codexRes.usage != nil {
+		snap.Codex, snap.CodexFetchedAt, fresh = codexRes.usage, now, true
+	}
+	if fresh {
+		snap.FetchedAt = now
+		_ = Save(f.CachePath, snap)
+	}
+}
+
+// recent reports whether data fetched at is young enough to skip a fetch.
+func (f *Fetcher) recent(at, now time.Time) bool {
+	return f.MaxAge > 0 && now.Sub(at) < f.MaxAge
+}
+
+func (f *Fetcher) loadCache() *Snapshot {
+	if f.CachePath == "" {
+		return nil
+	}
+	cached, err := Load(f.CachePath)
+	if err != nil {
+		return nil
+	}
+	return cached
+}
+
+func (f *Fetcher) clock() time.Time {
+	if f.now != nil {
+		return f.now()
+	}
+	return time.Now()
+}
@@ -0,0 +1,204 @@
+package snapshot
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/uesteibar/ccstats/internal/api"
+	"github.com/uesteibar/ccstats/internal/codex"
+)
+
+func TestFetch_FreshDataIsCached(t *testing.T) {
+	now := time.Date(2026, 1, 16, 12, 0, 0, 0, time.UTC)
+	cachePath := filepath.Join(t.TempDir(), "usage.json")
+
+	f := &Fetcher{
+		Claude: func(context.Context) (*api.UsageResponse, error) {
+			return &api.UsageResponse{FiveHour: api.UsageMetric{Utilization: 0.4}}, nil
+		},
+		Codex: func(context.Context) (*codex.Usage, error) {
+			return &codex.Usage{Plan: codex.PlanPlus}, nil
+		},
+		CachePath: cachePath,
+		now:       func() time.Time { return now },
+	}
+
+	snap, errs := f.Fetch(context.Background())
+	if errs.Claude != nil || errs.Codex != nil {
+		t.Fatalf("unexpected errors: %+v", errs)
+	}
+	if snap.Claude.FiveHour.Utilization != 0.4 {
+		t.Errorf("expected Claude utilization 0.4, got %f", snap.Claude.FiveHour.Utilization)
+	}
+
+	cached, err := Load(cachePath)
+	if err != nil {
+		t.Fatalf("expected cache to be written: %v", err)
+	}
+	if !cached.FetchedAt.Equal(now) {
+		t.Errorf("expected cached fetched_at %v, got %v", now, cached.FetchedAt)
+	}
+	if cached.Codex == nil || cached.Codex.Plan != codex.PlanPlus {
+		t.Errorf("expected cached Codex plan, got %+v", cached.Codex)
+	}
+}
+
+func TestFetch_TimeoutFallsBackToCache(t *testing.T) {
+	cachePath := filepath.Join(t.TempDir(), "usage.json")
+	cachedAt := time.Date(2026, 1, 16, 11, 0, 0, 0, time.UTC)
+	if err := Save(cachePath, &Snapshot{
+		FetchedAt: cachedAt,
+		Claude:    &api.UsageResponse{FiveHour: api.UsageMetric{Utilization: 0.7}},
+	}); err != nil {
+		t.Fatalf("failed to seed cache: %v", err)
+	}
+
+	block := make(chan struct{})
+	defer close(block)
+
+	f := &Fetcher{
+		Claude: func(context.Context) (*api.UsageResponse, error) {
+			<-block
+			return nil, errors.New("unreachable")
+		},
+		CachePath: cachePath,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	snap, errs := f.Fetch(ctx)
+	if time.Since(start) > time.Second {
+		t.Fatal("Fetch blocked past its budget")
+	}
+	if !errors.Is(errs.Claude, context.DeadlineExceeded) {
+		t.Errorf("expected deadline error, got %v", errs.Claude)
+	}
+	if snap.Claude == nil || snap.Claude.FiveHour.Utilization != 0.7 {
+		t.Fatalf("expected cached Claude usage, got %+v", snap.Claude)
+	}
+	if !snap.FetchedAt.Equal(cachedAt) {
+		t.Errorf("expected stale fetched_at %v, got %v", cachedAt, snap.FetchedAt)
+	}
+}
+
+func TestFetch_FreshCacheSkipsFetch(t *testing.T) {
+	now := time.Date(2026, 1, 16, 12, 0, 0, 0, time.UTC)
+	cachePath := filepath.Join(t.TempDir(), "usage.json")
+	if err := Save(cachePath, &Snapshot{
+		FetchedAt: now.Add(-10 * time.Second),
+		Claude:    &api.UsageResponse{},
+	}); err != nil {
+		t.Fatalf("failed to seed cache: %v", err)
+	}
+
+	called := false
+	f := &Fetcher{
+		Claude: func(context.Context) (*api.UsageResponse, error) {
+			called = true
+			return &api.UsageResponse{}, nil
+		},
+		CachePath: cachePath,
+		MaxAge:    time.Minute,
+		now:       func() time.Time { return now },
+	}
+
+	if _, errs := f.Fetch(context.Background()); errs.Claude != nil {
+		t.Fatalf("unexpected error: %v", errs.Claude)
+	}
+	if called {
+		t.Error("expected cached snapshot to be used without fetching")
+	}
+}
+
+func TestFetch_FallbackDataStaysStale(t *testing.T) {
+	now := time.Date(2026, 1, 16, 12, 0, 0, 0, time.UTC)
+	codexAt := now.Add(-48 * time.Hour)
+	cachePath := filepath.Join(t.TempDir(), "usage.json")
+	if err := Save(cachePath, &Snapshot{
+		FetchedAt: codexAt,
+		Codex:     &codex.Usage{Plan: codex.PlanPlus},
+	}); err != nil {
+		t.Fatalf("failed to seed cache: %v", err)
+	}
+
+	claudeCalls, codexCalls := 0, 0
+	f := &Fetcher{
+		Claude: func(context.Context) (*api.UsageResponse, error) {
+			claudeCalls++
+			return &api.UsageResponse{}, nil
+		},
+		Codex: func(context.Context) (*codex.Usage, error) {
+			codexCalls++
+			return nil, errors.New("codex down")
+		},
+		CachePath: cachePath,
+		MaxAge:    time.Minute,
+		now:       func() time.Time { return now },
+	}
+
+	for i := 1; i <= 2; i++ {
+		snap, errs := f.Fetch(context.Background())
+		if errs.Codex == nil {
+			t.Errorf("fetch %d: expected a Codex error for stale data", i)
+		}
+		if snap.Codex == nil || !snap.CodexFetchedAt.Equal(codexAt) {
+			t.Errorf("fetch %d: expected cached Codex data from %v, got %+v at %v", i, codexAt, snap.Codex, snap.CodexFetchedAt)
+		}
+		if !snap.ClaudeFetchedAt.Equal(now) {
+			t.Errorf("fetch %d: expected Claude fetched at %v, got %v", i, now, snap.ClaudeFetchedAt)
+		}
+	}
+	if claudeCalls != 1 {
+		t.Errorf("expected fresh Claude data to be reused, got %d fetches", claudeCalls)
+	}
+	if codexCalls != 2 {
+		t.Errorf("expected Codex to be fetched again, got %d fetches", codexCalls)
+	}
+}
+
+func TestLoad_MissingFile(t *testing.T) {
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Fatal("expected error for missing cache file")
+	}
+}
+
+func TestFetch_LateAnswerIsCached(t *testing.T) {
+	cachePath := filepath.Join(t.TempDir(), "usage.json")
+	release := make(chan struct{})
+
+	f := &Fetcher{
+		Claude: func(ctx context.Context) (*api.UsageResponse, error) {
+			select {
+			case <-release:
+				return &api.UsageResponse{FiveHour: api.UsageMetric{Utilization: 0.6}}, nil
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		},
+		CachePath: cachePath,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	snap, errs := f.Fetch(ctx)
+	if !errors.Is(errs.Claude, context.DeadlineExceeded) || snap.Claude != nil {
+		t.Fatalf("expected the budget to expire without data, got %+v, %v", snap.Claude, errs.Claude)
+	}
+
+	close(release)
+	f.Wait()
+
+	cached, err := Load(cachePath)
+	if err != nil {
+		t.Fatalf("expected the late answer to be cached: %v", err)
+	}
+	if cached.Claude == nil || cached.Claude.FiveHour.Utilization != 0.6 {
+		t.Errorf("expected cached Claude utilization 0.6, got %+v", cached.Claude)
+	}
+}
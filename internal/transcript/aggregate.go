@@ -0,0 +1,138 @@
+package transcript
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// GroupBy selects how entries are aggregated.
+type GroupBy string
+
+const (
+	GroupByDay     GroupBy = "day"
+	GroupByProject GroupBy = "project"
+	GroupBySession GroupBy = "session"
+	GroupByModel   GroupBy = "model"
+)
+
+// ParseGroupBy parses a grouping name.
+func ParseGroupBy(s string) (GroupBy, error) {
+	switch GroupBy(strings.ToLower(strings.TrimSpace(s))) {
+	case GroupByDay:
+		return GroupByDay, nil
+	case GroupByProject:
+		return GroupByProject, nil
+	case GroupBySession:
+		return GroupBySession, nil
+	case GroupByModel:
+		return GroupByModel, nil
+	default:
+		return "", fmt.Errorf("unknown grouping %q (want day, project, session or model)", s)
+	}
+}
+
+// Row is the aggregated usage of one group.
+type Row struct {
+	Key string `json:"key"`
+	// Project is set when grouping by session.
+	Project  string           `json:"project,omitempty"`
+	Messages int              `json:"messages"`
+	Usage    Usage            `json:"usage"`
+	Models   map[string]Usage `json:"models"`
+	// First and Last are the times of the earliest and latest message.
+	First time.Time `json:"first"`
+	Last  time.Time `json:"last"`
+}
+
+// Aggregator sums entries into rows.
+type Aggregator struct {
+	by       GroupBy
+	location *time.Location
+	rows     map[string]*Row
+}
+
+// NewAggregator returns an aggregator grouping by by. Days are split at
+// midnight in loc.
+func NewAggregator(by GroupBy, loc *time.Location) *Aggregator {
+	if loc == nil {
+		loc = time.Local
+	}
+	return &Aggregator{by: by, location: loc, rows: make(map[string]*Row)}
+}
+
+// Add counts an entry.
+func (a *Aggregator) Add(e Entry) {
+	key := a.key(e)
+	row, ok := a.rows[key]
+	if !ok {
+		row = &Row{Key: key, Models: make(map[string]Usage), First: e.Time, Last: e.Time}
+		if a.by == GroupBySession {
+			row.Project = e.Project
+		}
+		a.rows[key] = row
+	}
+
+	row.Messages++
+	row.Usage = row.Usage.Add(e.Usage)
+	row.Models[e.Model] = row.Models[e.Model].Add(e.Usage)
+	if e.Time.Before(row.First) {
+		row.First = e.Time
+	}
+	if e.Time.After(row.Last) {
+		row.Last = e.Time
+	}
+}
+
+// Rows returns the aggregated rows. Days are in chronological order; other
+// groupings put the largest total first.
+func (a *Aggregator) Rows() []Row {
+	rows := make([]Row, 0, len(a.rows))
+	for _, row := range a.rows {
+		rows = append(rows, *row)
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		if a.by == GroupByDay {
+			return rows[i].Key < rows[j].Key
+		}
+		if ti, tj := rows[i].Usage.Total(), rows[j].Usage.Total(); ti != tj {
+			return ti > tj
+		}
+		return rows[i].Key < rows[j].Key
+	})
+	return rows
+}
+
+func (a *Aggregator) key(e Entry) string {
+	switch a.by {
+	case GroupByProject:
+		return e.Project
+	case GroupBySession:
+		return e.SessionID
+	case GroupByModel:
+		return e.Model
+	default:
+		return e.Time.In(a.location).Format("2006-01-02")
+	}
+}
+
+// Total sums the usage of rows.
+func Total(rows []Row) Row {
+	total := Row{Key: "Total", Models: make(map[string]Usage)}
+	for _, row := range rows {
+		total.Messages += row.Messages
+		total.Usage = total.Usage.Add(row.Usage)
+		for model, usage := range row.Models {
+			total.Models[model] = total.Models[model].Add(usage)
+		}
+		if total.First.IsZero() || row.First.Before(total.First) {
+			total.First = row.First
+		}
+		if row.Last.After(total.Last) {
+			total.Last = row.Last
+		}
+	}
+	return total
+}
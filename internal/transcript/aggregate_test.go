@@ -0,0 +1,100 @@
+package transcript
+
+import (
+	"testing"
+	"time"
+)
+
+func aggregateFixture() []Entry {
+	day1 := time.Date(2026, 1, 15, 23, 30, 0, 0, time.UTC)
+	day2 := time.Date(2026, 1, 16, 8, 0, 0, 0, time.UTC)
+	return []Entry{
+		{Time: day2, SessionID: "s2", Project: "/lib", Model: "claude-opus-4-1", Usage: Usage{Output: 500}},
+		{Time: day1, SessionID: "s1", Project: "/app", Model: "claude-sonnet-4-5", Usage: Usage{Input: 100, CacheRead: 50}},
+		{Time: day2, SessionID: "s1", Project: "/app", Model: "claude-sonnet-4-5", Usage: Usage{Input: 10}},
+	}
+}
+
+func TestAggregator_ByDay(t *testing.T) {
+	agg := NewAggregator(GroupByDay, time.UTC)
+	for _, e := range aggregateFixture() {
+		agg.Add(e)
+	}
+
+	rows := agg.Rows()
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 days, got %d", len(rows))
+	}
+	if rows[0].Key != "2026-01-15" || rows[1].Key != "2026-01-16" {
+		t.Errorf("expected chronological days, got %q and %q", rows[0].Key, rows[1].Key)
+	}
+	if rows[1].Messages != 2 || rows[1].Usage.Total() != 510 {
+		t.Errorf("unexpected second day: %+v", rows[1])
+	}
+	if got := rows[1].Models["claude-opus-4-1"].Output; got != 500 {
+		t.Errorf("expected per-model output 500, got %d", got)
+	}
+}
+
+func TestAggregator_DayUsesLocation(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("timezone data unavailable")
+	}
+
+	agg := NewAggregator(GroupByDay, berlin)
+	agg.Add(aggregateFixture()[1])
+
+	if rows := agg.Rows(); rows[0].Key != "2026-01-16" {
+		t.Errorf("expected 23:30 UTC to fall on the next day in Berlin, got %q", rows[0].Key)
+	}
+}
+
+func TestAggregator_ByProjectSortsByTotal(t *testing.T) {
+	agg := NewAggregator(GroupByProject, time.UTC)
+	for _, e := range aggregateFixture() {
+		agg.Add(e)
+	}
+
+	rows := agg.Rows()
+	if rows[0].Key != "/lib" || rows[1].Key != "/app" {
+		t.Errorf("expected /lib (500) before /app (160), got %q, %q", rows[0].Key, rows[1].Key)
+	}
+}
+
+func TestAggregator_BySessionKeepsProject(t *testing.T) {
+	agg := NewAggregator(GroupBySession, time.UTC)
+	for _, e := range aggregateFixture() {
+		agg.Add(e)
+	}
+
+	for _, row := range agg.Rows() {
+		if row.Key == "s1" && row.Project != "/app" {
+			t.Errorf("expected session s1 in /app, got %q", row.Project)
+		}
+	}
+}
+
+func TestTotal(t *testing.T) {
+	agg := NewAggregator(GroupByModel, time.UTC)
+	for _, e := range aggregateFixture() {
+		agg.Add(e)
+	}
+
+	total := Total(agg.Rows())
+	if total.Messages != 3 || total.Usage.Total() != 660 {
+		t.Errorf("unexpected total: %+v", total)
+	}
+	if !total.First.Equal(aggregateFixture()[1].Time) {
+		t.Errorf("expected earliest time, got %v", total.First)
+	}
+}
+
+func TestParseGroupBy(t *testing.T) {
+	if by, err := ParseGroupBy("Project"); err != nil || by != GroupByProject {
+		t.Errorf("expected project grouping, got %q, %v", by, err)
+	}
+	if _, err := ParseGroupBy("week"); err == nil {
+		t.Error("expected error for unknown grouping")
+	}
+}
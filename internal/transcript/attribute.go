@@ -0,0 +1,81 @@
+package transcript
+
+import (
+	"sort"
+
+	"github.com/uesteibar/ccstats/internal/pricing"
+)
+
+// fallbackModel prices models the price table does not list.
+const fallbackModel = "claude-sonnet-4"
+
+// Tokens returns the usage as priced tokens.
+func (u Usage) Tokens() pricing.Tokens {
+	return pricing.Tokens{
+		Input:      u.Input,
+		Output:     u.Output,
+		CacheWrite: u.CacheCreation,
+		CacheRead:  u.CacheRead,
+	}
+}
+
+// Weighted returns the API price of the usage at rates, so tokens of a
+// pricier model or kind weigh more.
+func (u Usage) Weighted(rates pricing.Rates) float64 {
+	return rates.Cost(u.Tokens())
+}
+
+// weight returns a row's usage at API prices, pricing each model's tokens
+// at that model's rates in table.
+func (r Row) weight(table *pricing.Table) float64 {
+	fallback, _ := table.Lookup(fallbackModel)
+	if len(r.Models) == 0 {
+		return r.Usage.Weighted(fallback)
+	}
+
+	var total float64
+	for model, usage := range r.Models {
+		rates, ok := table.Lookup(model)
+		if !ok {
+			rates = fallback
+		}
+		total += usage.Weighted(rates)
+	}
+	return total
+}
+
+// Share is a row's estimated part of a window's utilization.
+type Share struct {
+	Row
+	// Weight is the row's usage at API prices, in dollars.
+	Weight float64 `json:"weight"`
+	// Share is the row's fraction (0.0-1.0) of all weighted usage.
+	Share float64 `json:"share"`
+	// Utilization is the part of the window's utilization attributed to
+	// the row.
+	Utilization float64 `json:"utilization"`
+}
+
+// Attribute splits a window's utilization (0.0-1.0) across rows in
+// proportion to the API price of their usage at the rates in table, largest
+// share first.
+func Attribute(rows []Row, utilization float64, table *pricing.Table) []Share {
+	shares := make([]Share, len(rows))
+	var total float64
+	for i, row := range rows {
+		shares[i] = Share{Row: row, Weight: row.weight(table)}
+		total += shares[i].Weight
+	}
+
+	for i := range shares {
+		if total > 0 {
+			shares[i].Share = shares[i].Weight / total
+			shares[i].Utilization = shares[i].Share * utilization
+		}
+	}
+
+	sort.SliceStable(shares, func(i, j int) bool {
+		return shares[i].Weight > shares[j].Weight
+	})
+	return shares
+}
@@ -0,0 +1,77 @@
+package transcript
+
+import (
+	"math"
+	"testing"
+
+	"github.com/uesteibar/ccstats/internal/pricing"
+)
+
+func TestUsage_Weighted(t *testing.T) {
+	u := Usage{Input: 100, Output: 10, CacheCreation: 40, CacheRead: 1000}
+	rates := pricing.Rates{Input: 3, Output: 15, CacheWrite: 3.75, CacheRead: 0.3}
+	if got := u.Weighted(rates); math.Abs(got-0.0009) > 1e-12 {
+		t.Errorf("expected $0.0009, got %v", got)
+	}
+	if got := u.Weighted(pricing.Rates{}); got != 0 {
+		t.Errorf("expected unpriced tokens to weigh nothing, got %v", got)
+	}
+}
+
+func TestAttribute_WeighsEachModel(t *testing.T) {
+	table := &pricing.Table{Models: []pricing.Model{
+		{Name: "cheap output", Match: []string{"model-a"}, Rates: pricing.Rates{Input: 1, Output: 1}},
+		{Name: "pricey output", Match: []string{"model-b"}, Rates: pricing.Rates{Input: 1, Output: 9}},
+	}}
+	rows := []Row{
+		{Key: "/a", Usage: Usage{Output: 10}, Models: map[string]Usage{"model-a": {Output: 10}}},
+		{Key: "/b", Usage: Usage{Output: 10}, Models: map[string]Usage{"model-b": {Output: 10}}},
+	}
+
+	shares := Attribute(rows, 1, table)
+	if shares[0].Key != "/b" || math.Abs(shares[0].Share-0.9) > 1e-9 || math.Abs(shares[1].Share-0.1) > 1e-9 {
+		t.Errorf("expected each model's output price to weigh its tokens, got %+v", shares)
+	}
+}
+
+func TestAttribute_PricierModelWeighsMore(t *testing.T) {
+	usage := Usage{Input: 1000, Output: 1000}
+	rows := []Row{
+		{Key: "/haiku", Usage: usage, Models: map[string]Usage{"claude-haiku-4-5": usage}},
+		{Key: "/opus", Usage: usage, Models: map[string]Usage{"claude-opus-4-1": usage}},
+	}
+
+	shares := Attribute(rows, 1, pricing.Embedded())
+	if shares[0].Key != "/opus" {
+		t.Fatalf("expected the pricier model's project first, got %q", shares[0].Key)
+	}
+	// Opus 4.1 costs 15x Haiku 4.5 for both input and output tokens.
+	if math.Abs(shares[0].Share-15.0/16) > 1e-9 {
+		t.Errorf("expected a 15/16 share for Opus, got %+v", shares)
+	}
+}
+
+func TestAttribute(t *testing.T) {
+	rows := []Row{
+		{Key: "/small", Usage: Usage{Input: 100}},
+		{Key: "/large", Usage: Usage{Output: 60}},
+	}
+
+	shares := Attribute(rows, 0.40, pricing.Embedded())
+	if shares[0].Key != "/large" {
+		t.Fatalf("expected the largest share first, got %q", shares[0].Key)
+	}
+	if math.Abs(shares[0].Share-0.75) > 1e-9 || math.Abs(shares[0].Utilization-0.30) > 1e-9 {
+		t.Errorf("expected 75%% share and 30%% utilization, got %+v", shares[0])
+	}
+	if math.Abs(shares[1].Utilization-0.10) > 1e-9 {
+		t.Errorf("expected 10%% utilization, got %v", shares[1].Utilization)
+	}
+}
+
+func TestAttribute_NoUsage(t *testing.T) {
+	shares := Attribute([]Row{{Key: "/idle"}}, 0.5, pricing.Embedded())
+	if shares[0].Share != 0 || shares[0].Utilization != 0 {
+		t.Errorf("expected zero share without usage, got %+v", shares[0])
+	}
+}
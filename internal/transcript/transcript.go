@@ -0,0 +1,208 @@
+// Package transcript reads the per-session JSONL transcripts Claude Code
+// writes under ~/.claude/projects and extracts per-message token usage.
+package transcript
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// maxLineSize bounds a single transcript line. Lines carrying large tool
+// results can be several megabytes.
+const maxLineSize = 64 << 20
+
+// syntheticModel marks messages Claude Code generates locally, which do not
+// consume quota.
+const syntheticModel = "<synthetic>"
+
+var usageKey = []byte(`"usage"`)
+
+// Usage is a set of token counts.
+type Usage struct {
+	Input         int64 `json:"input_tokens"`
+	Output        int64 `json:"output_tokens"`
+	CacheCreation int64 `json:"cache_creation_input_tokens"`
+	CacheRead     int64 `json:"cache_read_input_tokens"`
+}
+
+// Total returns the sum of all token counts.
+func (u Usage) Total() int64 {
+	return u.Input + u.Output + u.CacheCreation + u.CacheRead
+}
+
+// Add returns the sum of u and other.
+func (u Usage) Add(other Usage) Usage {
+	return Usage{
+		Input:         u.Input + other.Input,
+		Output:        u.Output + other.Output,
+		CacheCreation: u.CacheCreation + other.CacheCreation,
+		CacheRead:     u.CacheRead + other.CacheRead,
+	}
+}
+
+// Entry is the token usage of a single assistant message.
+type Entry struct {
+	Time      time.Time
+	SessionID string
+	// Project is the working directory the session ran in.
+	Project string
+	Model   string
+	Usage   Usage
+}
+
+// Filter restricts which entries are returned. Zero times are unbounded.
+type Filter struct {
+	Since time.Time
+	Until time.Time
+}
+
+func (f Filter) match(t time.Time) bool {
+	if !f.Since.IsZero() && t.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !t.Before(f.Until) {
+		return false
+	}
+	return true
+}
+
+// Dir returns the directory holding Claude Code transcripts. It honors
+// $CLAUDE_CONFIG_DIR and defaults to ~/.claude/projects.
+func Dir() string {
+	if dir := os.Getenv("CLAUDE_CONFIG_DIR"); dir != "" {
+		return filepath.Join(dir, "projects")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".claude", "projects")
+}
+
+// line is the subset of a transcript line that carries usage.
+type line struct {
+	SessionID string    `json:"sessionId"`
+	Cwd       string    `json:"cwd"`
+	Timestamp time.Time `json:"timestamp"`
+	RequestID string    `json:"requestId"`
+	Message   *struct {
+		ID    string `json:"id"`
+		Model string `json:"model"`
+		Usage *Usage `json:"usage"`
+	} `json:"message"`
+}
+
+// Scan streams every transcript under dir, calling fn for each assistant
+// message matching filter. Claude Code repeats a message's usage on every
+// line it streams and copies earlier messages into resumed sessions, so each
+// message is reported once.
+func Scan(dir string, filter Filter, fn func(Entry) error) error {
+	seen := make(map[string]bool)
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == dir {
+				return err
+			}
+			return nil
+		}
+		if d.IsDir() || filepath.Ext(path) != ".jsonl" {
+			return nil
+		}
+
+		// Files are append-only, so one last written before the range
+		// starts cannot contain matching entries.
+		if info, err := d.Info(); err == nil && !filter.Since.IsZero() && info.ModTime().Before(filter.Since) {
+			return nil
+		}
+
+		project := projectFromDir(dir, path)
+		return scanFile(path, project, filter, seen, fn)
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("no Claude Code transcripts found in %s: %w", dir, fs.ErrNotExist)
+	}
+	return err
+}
+
+func scanFile(path, project string, filter Filter, seen map[string]bool, fn func(Entry) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		// Sessions can be deleted while we walk; skip them.
+		return nil
+	}
+	defer f.Close()
+
+	return scanReader(f, project, filter, seen, fn)
+}
+
+func scanReader(r io.Reader, project string, filter Filter, seen map[string]bool, fn func(Entry) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	for scanner.Scan() {
+		data := scanner.Bytes()
+		// Cheap pre-check: most lines are user turns or tool output.
+		if !bytes.Contains(data, usageKey) {
+			continue
+		}
+
+		var l line
+		if err := json.Unmarshal(data, &l); err != nil {
+			continue
+		}
+		if l.Message == nil || l.Message.Usage == nil || l.Message.Model == syntheticModel {
+			continue
+		}
+		if !filter.match(l.Timestamp) {
+			continue
+		}
+
+		if l.Message.ID != "" {
+			key := l.Message.ID + ":" + l.RequestID
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+		}
+
+		entry := Entry{
+			Time:      l.Timestamp,
+			SessionID: l.SessionID,
+			Project:   l.Cwd,
+			Model:     l.Message.Model,
+			Usage:     *l.Message.Usage,
+		}
+		if entry.Project == "" {
+			entry.Project = project
+		}
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+
+	// A line over maxLineSize only loses the rest of that file.
+	if err := scanner.Err(); err != nil && !errors.Is(err, bufio.ErrTooLong) {
+		return err
+	}
+	return nil
+}
+
+// projectFromDir names a transcript's project after its directory under
+// root, which Claude Code derives from the working directory.
+func projectFromDir(root, path string) string {
+	rel, err := filepath.Rel(root, filepath.Dir(path))
+	if err != nil || rel == "." {
+		return ""
+	}
+	return strings.SplitN(filepath.ToSlash(rel), "/", 2)[0]
+}
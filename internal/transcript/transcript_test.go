@@ -0,0 +1,101 @@
+package transcript
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const sessionFixture = `{"type":"user","sessionId":"s1","cwd":"/home/me/app","timestamp":"2026-01-15T10:00:00Z","message":{"role":"user","content":"hi"}}
+{"type":"assistant","sessionId":"s1","cwd":"/home/me/app","timestamp":"2026-01-15T10:00:05Z","requestId":"req_1","message":{"id":"msg_1","model":"claude-sonnet-4-5","usage":{"input_tokens":100,"output_tokens":50,"cache_creation_input_tokens":1000,"cache_read_input_tokens":2000}}}
+{"type":"assistant","sessionId":"s1","cwd":"/home/me/app","timestamp":"2026-01-15T10:00:06Z","requestId":"req_1","message":{"id":"msg_1","model":"claude-sonnet-4-5","usage":{"input_tokens":100,"output_tokens":50,"cache_creation_input_tokens":1000,"cache_read_input_tokens":2000}}}
+not json with "usage"
+{"type":"assistant","sessionId":"s1","cwd":"/home/me/app","timestamp":"2026-01-16T09:00:00Z","requestId":"req_2","message":{"id":"msg_2","model":"claude-opus-4-1","usage":{"input_tokens":10,"output_tokens":500}}}
+{"type":"assistant","sessionId":"s1","timestamp":"2026-01-16T09:00:01Z","message":{"id":"msg_3","model":"<synthetic>","usage":{"input_tokens":0,"output_tokens":0}}}
+`
+
+func writeTranscript(t *testing.T, dir, project, name, content string) {
+	t.Helper()
+	path := filepath.Join(dir, project, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write transcript: %v", err)
+	}
+}
+
+func collect(t *testing.T, dir string, filter Filter) []Entry {
+	t.Helper()
+	var entries []Entry
+	err := Scan(dir, filter, func(e Entry) error {
+		entries = append(entries, e)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return entries
+}
+
+func TestScan_DeduplicatesAndSkipsSynthetic(t *testing.T) {
+	dir := t.TempDir()
+	writeTranscript(t, dir, "-home-me-app", "s1.jsonl", sessionFixture)
+	// A resumed session repeats earlier messages.
+	writeTranscript(t, dir, "-home-me-app", "s2.jsonl", strings.SplitAfter(sessionFixture, "\n")[1])
+
+	entries := collect(t, dir, Filter{})
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d: %+v", len(entries), entries)
+	}
+
+	first := entries[0]
+	if first.Project != "/home/me/app" || first.SessionID != "s1" || first.Model != "claude-sonnet-4-5" {
+		t.Errorf("unexpected entry: %+v", first)
+	}
+	if first.Usage.Total() != 3150 {
+		t.Errorf("expected 3150 tokens, got %d", first.Usage.Total())
+	}
+}
+
+func TestScan_Filter(t *testing.T) {
+	dir := t.TempDir()
+	writeTranscript(t, dir, "-home-me-app", "s1.jsonl", sessionFixture)
+
+	entries := collect(t, dir, Filter{
+		Since: time.Date(2026, 1, 16, 0, 0, 0, 0, time.UTC),
+		Until: time.Date(2026, 1, 17, 0, 0, 0, 0, time.UTC),
+	})
+	if len(entries) != 1 || entries[0].Model != "claude-opus-4-1" {
+		t.Errorf("expected only the opus message, got %+v", entries)
+	}
+}
+
+func TestScan_ProjectFromDirWithoutCwd(t *testing.T) {
+	dir := t.TempDir()
+	writeTranscript(t, dir, "-home-me-lib", "s.jsonl",
+		`{"sessionId":"s9","timestamp":"2026-01-16T11:00:00Z","message":{"id":"m","model":"claude-sonnet-4-5","usage":{"input_tokens":1}}}`+"\n")
+
+	entries := collect(t, dir, Filter{})
+	if len(entries) != 1 || entries[0].Project != "-home-me-lib" {
+		t.Errorf("expected project from directory, got %+v", entries)
+	}
+}
+
+func TestScan_MissingDir(t *testing.T) {
+	err := Scan(filepath.Join(t.TempDir(), "missing"), Filter{}, func(Entry) error { return nil })
+	if !errors.Is(err, fs.ErrNotExist) || !strings.Contains(err.Error(), "no Claude Code transcripts") {
+		t.Errorf("expected missing transcripts error, got %v", err)
+	}
+}
+
+func TestDir_ClaudeConfigDir(t *testing.T) {
+	t.Setenv("CLAUDE_CONFIG_DIR", "/tmp/claude")
+	if got := Dir(); got != "/tmp/claude/projects" {
+		t.Errorf("expected projects under CLAUDE_CONFIG_DIR, got %q", got)
+	}
+}
@@ -0,0 +1,162 @@
+// Package wait blocks until a usage window has room again: until its
+// utilization drops below a threshold or it resets.
+package wait
+
+import (
+	"context"
+	"time"
+
+	"github.com/uesteibar/ccstats/internal/quota"
+)
+
+const (
+	// resetGrace is how long after a window's reset time it is checked, so
+	// the fresh cycle has been published.
+	resetGrace = 30 * time.Second
+	// resetRetry bounds the check interval once the reset time has passed
+	// without a new cycle showing up, e.g. behind a daemon's poll interval.
+	resetRetry = time.Minute
+	// tickInterval is how often OnTick is called between checks.
+	tickInterval = time.Second
+)
+
+// Status is the state of a wait after a check or a tick.
+type Status struct {
+	// Window is the latest reading of the window.
+	Window quota.Window
+	// Next is when the window is checked next.
+	Next time.Time
+	Now  time.Time
+	// Err is the error of the latest check, which is retried.
+	Err error
+}
+
+// Waiter waits for one window.
+type Waiter struct {
+	// Read returns the current reading of the window.
+	Read func(ctx context.Context) (quota.Window, error)
+	// Below is the utilization, as a fraction, the window must fall under;
+	// zero waits for the window to reset.
+	Below float64
+	// Interval is the longest time between checks.
+	Interval time.Duration
+	// OnCheck, when set, is called after every check that does not end
+	// the wait.
+	OnCheck func(Status)
+	// OnTick, when set, is called every second between checks.
+	OnTick func(Status)
+
+	now   func() time.Time
+	after func(time.Duration) <-chan time.Time
+}
+
+// Wait checks the window until it has room and returns the reading that
+// satisfied the wait. The first check's error is returned; later errors
+// are retried. It returns ctx's error when ctx is done first.
+func (w *Waiter) Wait(ctx context.Context) (quota.Window, error) {
+	first, err := w.Read(ctx)
+	if err != nil {
+		return quota.Window{}, err
+	}
+	current := first
+
+	for {
+		if w.satisfied(first, current, w.clock()) {
+			return current, nil
+		}
+
+		now := w.clock()
+		status := Status{Window: current, Next: w.nextCheck(current, now), Now: now, Err: err}
+		if w.OnCheck != nil {
+			w.OnCheck(status)
+		}
+		if err := w.sleep(ctx, status); err != nil {
+			return current, err
+		}
+
+		var reading quota.Window
+		reading, err = w.Read(ctx)
+		if ctx.Err() != nil {
+			return current, ctx.Err()
+		}
+		if err == nil {
+			current = reading
+		}
+	}
+}
+
+// satisfied reports whether current, read at now, ends a wait that
+// started at first: it is below the threshold, idle, or in a later cycle.
+// When first has no reset time, a fall in utilization counts as a reset.
+func (w *Waiter) satisfied(first, current quota.Window, now time.Time) bool {
+	metric := current.Metric
+	switch {
+	case w.Below > 0 && metric.Utilization < w.Below:
+		return true
+	case metric.ResetAt.IsZero() && metric.Utilization == 0:
+		// No cycle is running, so the window has all its room.
+		return true
+	case first.Metric.ResetAt.IsZero():
+		return metric.Utilization < first.Metric.Utilization
+	}
+	return quota.NewCycle(first.Metric.ResetAt, metric.ResetAt, now)
+}
+
+// nextCheck returns when to check next: after Interval, or shortly after
+// the window resets if that comes first.
+func (w *Waiter) nextCheck(current quota.Window, now time.Time) time.Time {
+	next := now.Add(w.Interval)
+	resetAt := current.Metric.ResetAt
+	if resetAt.IsZero() {
+		return next
+	}
+
+	checkAt := resetAt.Add(resetGrace)
+	if !checkAt.After(now) {
+		// The reset is due but not reported yet.
+		return now.Add(min(w.Interval, resetRetry))
+	}
+	if checkAt.Before(next) {
+		return checkAt
+	}
+	return next
+}
+
+// sleep waits until status.Next, calling OnTick along the way.
+func (w *Waiter) sleep(ctx context.Context, status Status) error {
+	for {
+		now := w.clock()
+		if !now.Before(status.Next) {
+			return nil
+		}
+
+		d := status.Next.Sub(now)
+		if w.OnTick != nil {
+			d = min(d, tickInterval)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-w.timer(d):
+		}
+
+		if w.OnTick != nil {
+			status.Now = w.clock()
+			w.OnTick(status)
+		}
+	}
+}
+
+func (w *Waiter) clock() time.Time {
+	if w.now != nil {
+		return w.now()
+	}
+	return time.Now()
+}
+
+func (w *Waiter) timer(d time.Duration) <-chan time.Time {
+	if w.after != nil {
+		return w.after(d)
+	}
+	return time.After(d)
+}
@@ -0,0 +1,239 @@
+package wait
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/uesteibar/ccstats/internal/api"
+	"github.com/uesteibar/ccstats/internal/quota"
+)
+
+var start = time.Date(2025, 11, 20, 12, 0, 0, 0, time.UTC)
+
+func fiveHour(utilization float64, resetAt time.Time) quota.Window {
+	return quota.Window{
+		Provider: "claude",
+		Key:      "5h",
+		Label:    "5-hour",
+		Duration: 5 * time.Hour,
+		Metric:   api.UsageMetric{Utilization: utilization, ResetAt: resetAt},
+	}
+}
+
+// fakeWaiter returns a waiter on a fake clock whose readings come from
+// read, called with the fake time of each check.
+func fakeWaiter(read func(now time.Time) (quota.Window, error)) (*Waiter, *[]time.Time) {
+	now := start
+	var checks []time.Time
+	w := &Waiter{
+		Read: func(context.Context) (quota.Window, error) {
+			checks = append(checks, now)
+			return read(now)
+		},
+		Interval: 5 * time.Minute,
+		now:      func() time.Time { return now },
+		after: func(d time.Duration) <-chan time.Time {
+			now = now.Add(d)
+			ch := make(chan time.Time, 1)
+			ch <- now
+			return ch
+		},
+	}
+	return w, &checks
+}
+
+func TestWait_AlreadyBelow(t *testing.T) {
+	w, checks := fakeWaiter(func(time.Time) (quota.Window, error) {
+		return fiveHour(0.5, start.Add(time.Hour)), nil
+	})
+	w.Below = 0.85
+
+	got, err := w.Wait(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Metric.Utilization != 0.5 {
+		t.Errorf("expected utilization 0.5, got %v", got.Metric.Utilization)
+	}
+	if len(*checks) != 1 {
+		t.Errorf("expected 1 check, got %d", len(*checks))
+	}
+}
+
+func TestWait_SleepsUntilReset(t *testing.T) {
+	resetAt := start.Add(12 * time.Minute)
+	w, checks := fakeWaiter(func(now time.Time) (quota.Window, error) {
+		if now.Before(resetAt) {
+			return fiveHour(0.95, resetAt), nil
+		}
+		return fiveHour(0, resetAt.Add(5*time.Hour)), nil
+	})
+
+	got, err := w.Wait(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Metric.Utilization != 0 {
+		t.Errorf("expected utilization 0, got %v", got.Metric.Utilization)
+	}
+
+	want := []time.Time{start, start.Add(5 * time.Minute), start.Add(10 * time.Minute), resetAt.Add(resetGrace)}
+	if len(*checks) != len(want) {
+		t.Fatalf("expected checks at %v, got %v", want, *checks)
+	}
+	for i := range want {
+		if !(*checks)[i].Equal(want[i]) {
+			t.Errorf("expected check %d at %v, got %v", i, want[i], (*checks)[i])
+		}
+	}
+}
+
+func TestWait_BelowWithinCycle(t *testing.T) {
+	resetAt := start.Add(3 * time.Hour)
+	w, checks := fakeWaiter(func(now time.Time) (quota.Window, error) {
+		// A rolling window drains as old usage ages out.
+		utilization := 0.9 - now.Sub(start).Minutes()/100
+		return fiveHour(utilization, resetAt), nil
+	})
+	w.Below = 0.8
+
+	if _, err := w.Wait(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := (*checks)[len(*checks)-1]; !got.Equal(start.Add(15 * time.Minute)) {
+		t.Errorf("expected the wait to end at the 15m check, got %v", got.Sub(start))
+	}
+}
+
+func TestWait_RetriesAfterOverdueReset(t *testing.T) {
+	// The cached reading is from the previous cycle until 3 minutes in.
+	resetAt := start.Add(-time.Minute)
+	w, checks := fakeWaiter(func(now time.Time) (quota.Window, error) {
+		if now.Before(start.Add(3 * time.Minute)) {
+			return fiveHour(1, resetAt), nil
+		}
+		return fiveHour(0.02, resetAt.Add(5*time.Hour)), nil
+	})
+
+	if _, err := w.Wait(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []time.Time{start, start.Add(time.Minute), start.Add(2 * time.Minute), start.Add(3 * time.Minute)}
+	if len(*checks) != len(want) {
+		t.Fatalf("expected checks at %v, got %v", want, *checks)
+	}
+}
+
+func TestWait_ResetClearsResetAt(t *testing.T) {
+	// An unused window reports no reset time once its cycle has ended.
+	resetAt := start.Add(12 * time.Minute)
+	w, checks := fakeWaiter(func(now time.Time) (quota.Window, error) {
+		if now.Before(resetAt) {
+			return fiveHour(0.95, resetAt), nil
+		}
+		return fiveHour(0, time.Time{}), nil
+	})
+
+	got, err := w.Wait(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !got.Metric.ResetAt.IsZero() {
+		t.Errorf("expected the reading without a reset time, got %v", got.Metric.ResetAt)
+	}
+	if last := (*checks)[len(*checks)-1]; !last.Equal(resetAt.Add(resetGrace)) {
+		t.Errorf("expected the wait to end at %v, got %v", resetAt.Add(resetGrace), last)
+	}
+}
+
+func TestWait_IdleWindow(t *testing.T) {
+	w, checks := fakeWaiter(func(time.Time) (quota.Window, error) {
+		return fiveHour(0, time.Time{}), nil
+	})
+
+	if _, err := w.Wait(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(*checks) != 1 {
+		t.Errorf("expected an idle window to end the wait at once, got %d checks", len(*checks))
+	}
+}
+
+func TestWait_FirstErrorFails(t *testing.T) {
+	w, _ := fakeWaiter(func(time.Time) (quota.Window, error) {
+		return quota.Window{}, errors.New("not logged in")
+	})
+
+	if _, err := w.Wait(context.Background()); err == nil || err.Error() != "not logged in" {
+		t.Errorf("expected the read error, got %v", err)
+	}
+}
+
+func TestWait_RetriesLaterErrors(t *testing.T) {
+	resetAt := start.Add(7 * time.Minute)
+	var reported []error
+	w, _ := fakeWaiter(func(now time.Time) (quota.Window, error) {
+		switch {
+		case now.Equal(start):
+			return fiveHour(1, resetAt), nil
+		case now.Before(resetAt):
+			return quota.Window{}, errors.New("network down")
+		default:
+			return fiveHour(0, resetAt.Add(5*time.Hour)), nil
+		}
+	})
+	w.OnCheck = func(s Status) { reported = append(reported, s.Err) }
+
+	got, err := w.Wait(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Metric.Utilization != 0 {
+		t.Errorf("expected the reset reading, got %v", got.Metric.Utilization)
+	}
+	if len(reported) != 2 || reported[0] != nil || reported[1] == nil {
+		t.Errorf("expected the retried error to be reported, got %v", reported)
+	}
+}
+
+func TestWait_ContextDone(t *testing.T) {
+	w, _ := fakeWaiter(func(time.Time) (quota.Window, error) {
+		return fiveHour(1, start.Add(time.Hour)), nil
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	ticks := 0
+	w.OnTick = func(Status) {
+		ticks++
+		if ticks == 3 {
+			cancel()
+		}
+	}
+
+	if _, err := w.Wait(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestWait_TicksEverySecond(t *testing.T) {
+	resetAt := start.Add(10 * time.Second)
+	var ticks []time.Duration
+	w, _ := fakeWaiter(func(now time.Time) (quota.Window, error) {
+		if now.Before(resetAt) {
+			return fiveHour(1, resetAt), nil
+		}
+		return fiveHour(0, resetAt.Add(5*time.Hour)), nil
+	})
+	w.OnTick = func(s Status) { ticks = append(ticks, s.Next.Sub(s.Now)) }
+
+	if _, err := w.Wait(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := int((10*time.Second + resetGrace) / time.Second); len(ticks) != want {
+		t.Fatalf("expected %d ticks, got %d", want, len(ticks))
+	}
+	if ticks[0] != 39*time.Second || ticks[len(ticks)-1] != 0 {
+		t.Errorf("expected a countdown from 39s to 0, got %v to %v", ticks[0], ticks[len(ticks)-1])
+	}
+}
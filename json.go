@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+
+	"github.com/uesteibar/ccstats/internal/snapshot"
+)
+
+// jsonOutput is the document printed by --format json: the usage snapshot
+// plus any per-provider errors.
+type jsonOutput struct {
+	*snapshot.Snapshot
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+// runJSON prints the usage of both providers as JSON. It fails only when
+// no Claude data is available at all.
+func runJSON(w io.Writer) error {
+	snap, errs := fetchSnapshot(context.Background())
+
+	out := jsonOutput{Snapshot: snap}
+	if errs.Claude != nil || errs.Codex != nil {
+		out.Errors = make(map[string]string)
+	}
+	if errs.Claude != nil {
+		out.Errors["claude"] = errs.Claude.Error()
+	}
+	if errs.Codex != nil {
+		out.Errors["codex"] = errs.Codex.Error()
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(out); err != nil {
+		return err
+	}
+
+	if snap.Claude == nil && errs.Claude != nil {
+		return errs.Claude
+	}
+	return nil
+}
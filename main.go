@@ -1,42 +1,99 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"os"
+	"time"
 
-	"github.com/uesteibar/ccstats/internal/api"
 	"github.com/uesteibar/ccstats/internal/codex"
+	"github.com/uesteibar/ccstats/internal/config"
 	"github.com/uesteibar/ccstats/internal/display"
-	"github.com/uesteibar/ccstats/internal/keychain"
+	"github.com/uesteibar/ccstats/internal/quota"
 )
 
 func main() {
 	if err := run(os.Args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		var status exitStatus
+		if errors.As(err, &status) {
+			os.Exit(int(status))
+		}
 		fmt.Fprintln(os.Stderr, "Error:", err)
 		os.Exit(1)
 	}
 }
 
 func run(args []string) error {
-	// Check for auth/status subcommand
-	if len(args) > 0 && (args[0] == "auth" || args[0] == "status") {
-		return runAuthStatus(os.Stdout)
+	cfg, err := config.Load()
+	if err != nil {
+		return err
 	}
 
-	if len(args) > 0 && args[0] == "codex" {
-		if len(args) > 1 && (args[1] == "auth" || args[1] == "status") {
-			return runCodexAuthStatus(os.Stdout)
+	args, profileName, allProfiles, err := globalFlags(args)
+	if err != nil {
+		return err
+	}
+	if allProfiles {
+		return runAllProfiles(os.Stdout, cfg, args)
+	}
+	if err := selectProfile(cfg, profileName); err != nil {
+		return err
+	}
+
+	if len(args) > 0 {
+		switch args[0] {
+		case "auth", "status":
+			return runAuthStatus(os.Stdout)
+		case "prompt":
+			return runPrompt(os.Stdout, cfg, args[1:])
+		case "tokens":
+			return runTokens(os.Stdout, cfg, args[1:])
+		case "breakdown":
+			return runBreakdown(os.Stdout, cfg, args[1:])
+		case "cost":
+			return runCost(os.Stdout, cfg, args[1:])
+		case "notify":
+			return runNotify(os.Stdout, cfg, args[1:])
+		case "daemon":
+			return runDaemon(os.Stdout, cfg, args[1:])
+		case "serve":
+			return runServe(cfg, args[1:])
+		case "exec":
+			return runExec(cfg, args[1:])
+		case "chart":
+			return runChart(os.Stdout, cfg, args[1:])
+		case "wait":
+			return runWait(os.Stdout, cfg, args[1:])
+		case "mcp":
+			return runMCP(cfg, args[1:])
+		case "codex":
+			if len(args) > 1 && (args[1] == "auth" || args[1] == "status") {
+				return runCodexAuthStatus(os.Stdout)
+			}
+			if len(args) > 1 && args[1] == "plans" {
+				return runCodexPlans(os.Stdout, cfg, args[2:])
+			}
+			if len(args) > 1 && args[1] == "sessions" {
+				return runCodexSessions(os.Stdout, cfg, args[2:])
+			}
+			return runCodexUsage(os.Stdout, cfg, args[1:])
 		}
-		return runCodexUsage(os.Stdout)
 	}
 
 	// Default: fetch and display usage
-	return runUsage(os.Stdout)
+	return runUsage(os.Stdout, cfg, args)
 }
 
 // runAuthStatus checks if credentials are available without making API calls.
-func runAuthStatus(w *os.File) error {
-	if keychain.HasCredentials() {
+func runAuthStatus(w io.Writer) error {
+	if active.claudeSource().HasCredentials() {
 		fmt.Fprintln(w, "Authenticated: Valid credentials found in Keychain")
 		return nil
 	}
@@ -46,51 +103,184 @@ func runAuthStatus(w *os.File) error {
 }
 
 // runUsage fetches and displays usage statistics.
-func runUsage(w *os.File) error {
-	token, err := keychain.GetAccessToken()
-	if err != nil {
+func runUsage(w io.Writer, cfg config.Config, args []string) error {
+	fs := flag.NewFlagSet("ccstats", flag.ContinueOnError)
+	format := fs.String("format", "pretty", "output format: pretty, json, line, waybar, i3bar or xbar")
+	interval := fs.Duration("interval", 0, "refresh interval for waybar and i3bar output (0 prints once; i3bar defaults to 1m)")
+	templateText := fs.String("template", "", "render usage with a Go text/template")
+	templateFile := fs.String("template-file", "", "render usage with a Go text/template read from a file")
+	sparklines := fs.Bool("sparklines", cfg.Display.Sparklines, "draw recent history next to the bars")
+	displayOpts := addDisplayFlags(fs, cfg.Display)
+	if err := fs.Parse(args); err != nil {
 		return err
 	}
 
-	client := api.NewClient()
-	usage, err := client.FetchUsage(token)
+	opts, err := displayOpts.options()
 	if err != nil {
 		return err
 	}
 
-	display.DisplayUsage(w, usage)
+	if *templateText != "" || *templateFile != "" {
+		return runTemplate(w, *templateText, *templateFile, opts)
+	}
+
+	switch *format {
+	case "pretty":
+	case "json":
+		return runJSON(w)
+	case "line":
+		style := display.LineStylePlain
+		if opts.Color.Enabled {
+			style = display.LineStyleANSI
+		}
+		return renderLine(w, cfg.Prompt, style, cfg.Prompt.Windows, cfg.Prompt.Budget.Duration)
+	case "waybar":
+		return runWaybar(w, cfg.Prompt, *interval)
+	case "i3bar":
+		return runI3bar(w, cfg.Prompt, *interval)
+	case "xbar":
+		return runXbar(w, cfg.Prompt)
+	default:
+		return fmt.Errorf("unknown format %q (want pretty, json, line, waybar, i3bar or xbar)", *format)
+	}
 
-	codexUsage, err := codex.FetchUsage()
-	if err != nil {
-		if err == codex.ErrAuthNotFound {
+	snap, errs := fetchSnapshot(context.Background())
+	if errs.Claude != nil {
+		return errs.Claude
+	}
+
+	codexUsage := snap.Codex
+	if errs.Codex != nil {
+		codexUsage = nil
+	}
+
+	if codexUsage != nil {
+		warnPlanLimits(cfg)
+	}
+
+	now := time.Now()
+	if *sparklines && !opts.Accessible {
+		opts.Trends = usageTrends(context.Background(), quota.Windows(snap.Claude, codexUsage), now)
+	}
+	display.DisplayAll(w, snap.Claude, codexUsage, now, opts)
+	if codexUsage != nil {
+		warnStalePlanLimits(cfg)
+	}
+
+	if errs.Codex != nil {
+		if errs.Codex == codex.ErrAuthNotFound {
 			fmt.Fprintln(os.Stderr, "Codex not authenticated: run `codex login` to show Codex limits")
 			return nil
 		}
-		return err
+		return errs.Codex
 	}
-
-	display.DisplayCodexUsage(w, codexUsage)
 	return nil
 }
 
 // runCodexAuthStatus checks if Codex credentials are available.
-func runCodexAuthStatus(w *os.File) error {
-	if codex.HasCredentials() {
-		fmt.Fprintln(w, "Codex authenticated: Valid credentials found in ~/.codex/auth.json")
+func runCodexAuthStatus(w io.Writer) error {
+	source := active.codexSource()
+	path := source.AuthFilePath()
+	if path == "" {
+		path = "~/.codex/auth.json"
+	}
+	if source.HasCredentials() {
+		fmt.Fprintf(w, "Codex authenticated: Valid credentials found in %s\n", path)
 		return nil
 	}
-	fmt.Fprintln(w, "Codex not authenticated: No credentials found in ~/.codex/auth.json")
+	fmt.Fprintf(w, "Codex not authenticated: No credentials found in %s\n", path)
 	fmt.Fprintln(w, "Run `codex login` to authenticate")
 	return nil
 }
 
 // runCodexUsage fetches and displays Codex usage limits.
-func runCodexUsage(w *os.File) error {
-	usage, err := codex.FetchUsage()
+func runCodexUsage(w io.Writer, cfg config.Config, args []string) error {
+	fs := flag.NewFlagSet("codex", flag.ContinueOnError)
+	displayOpts := addDisplayFlags(fs, cfg.Display)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	opts, err := displayOpts.options()
+	if err != nil {
+		return err
+	}
+
+	if err := loadPlanLimits(cfg); err != nil {
+		return err
+	}
+
+	usage, err := active.codexSource().FetchUsage()
 	if err != nil {
 		return err
 	}
 
-	display.DisplayCodexUsage(w, usage)
+	display.DisplayCodexUsageWithOptions(w, usage, time.Now(), opts)
+	warnStalePlanLimits(cfg)
+	return nil
+}
+
+// runCodexPlans displays the Codex plan limits table, highlighting the
+// plan detected from local credentials.
+func runCodexPlans(w io.Writer, cfg config.Config, args []string) error {
+	fs := flag.NewFlagSet("codex plans", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print the plan limits data as JSON, e.g. to start an override file")
+	displayOpts := addDisplayFlags(fs, cfg.Display)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	opts, err := displayOpts.options()
+	if err != nil {
+		return err
+	}
+
+	if err := loadPlanLimits(cfg); err != nil {
+		return err
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(codex.CurrentPlanLimits())
+	}
+
+	current := codex.PlanUnknown
+	if usage, err := active.codexSource().DetectPlan(); err == nil {
+		current = usage.Plan
+	}
+
+	display.DisplayCodexPlans(w, codex.CurrentPlanLimits(), current, opts)
+	warnStalePlanLimits(cfg)
 	return nil
 }
+
+// loadPlanLimits replaces the built-in Codex plan limits with the
+// configured override file, if there is one.
+func loadPlanLimits(cfg config.Config) error {
+	planLimits, err := codex.LoadPlanLimits(cfg.Codex.PlanLimitsPath())
+	if err != nil {
+		return err
+	}
+	codex.SetPlanLimits(planLimits)
+	return nil
+}
+
+// warnPlanLimits loads the Codex plan limits for views that only use them
+// for estimates, falling back to the built-in limits with a warning.
+func warnPlanLimits(cfg config.Config) {
+	if err := loadPlanLimits(cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "ccstats: using built-in Codex plan limits: %v\n", err)
+	}
+}
+
+// warnStalePlanLimits prints a hint to stderr when the Codex plan limits are
+// older than the configured maximum age.
+func warnStalePlanLimits(cfg config.Config) {
+	data := codex.CurrentPlanLimits()
+	if !data.Stale(time.Now(), cfg.Codex.PlanLimitsMaxAge.Duration) {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Warning: Codex plan limits were last updated %s and may be out of date.\n", data.LastUpdated)
+	fmt.Fprintf(os.Stderr, "Update ccstats or save current limits to %s (see `ccstats codex plans --json`).\n", cfg.Codex.PlanLimitsPath())
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"runtime/debug"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/uesteibar/ccstats/internal/config"
+	"github.com/uesteibar/ccstats/internal/display"
+	"github.com/uesteibar/ccstats/internal/forecast"
+	"github.com/uesteibar/ccstats/internal/history"
+	"github.com/uesteibar/ccstats/internal/mcp"
+	"github.com/uesteibar/ccstats/internal/quota"
+)
+
+const mcpInstructions = `ccstats reports the subscription usage limits of Claude and Codex.
+Call get_usage before starting long or expensive work, and project_exhaustion
+to decide whether it will fit in the current window. Utilization is a
+percentage of the window's limit; a window resets to 0% at reset_at.`
+
+// mcpWindow is a usage window as reported to MCP clients.
+type mcpWindow struct {
+	Window             string    `json:"window"`
+	Label              string    `json:"label"`
+	UtilizationPercent float64   `json:"utilization_percent"`
+	ResetAt            time.Time `json:"reset_at,omitzero"`
+	ResetsIn           string    `json:"resets_in,omitempty"`
+}
+
+// mcpUsage is the result of the usage tools.
+type mcpUsage struct {
+	Provider string      `json:"provider"`
+	Plan     string      `json:"plan,omitempty"`
+	Windows  []mcpWindow `json:"windows"`
+	Error    string      `json:"error,omitempty"`
+}
+
+// runMCP serves the usage tools to an MCP client over stdin and stdout.
+func runMCP(cfg config.Config, args []string) error {
+	fs := flag.NewFlagSet("mcp", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	server := &mcp.Server{
+		Name:         "ccstats",
+		Version:      buildVersion(),
+		Instructions: mcpInstructions,
+		Tools: []mcp.Tool{
+			{
+				Name:        "get_usage",
+				Description: "Current Claude subscription usage: utilization and reset time of the 5-hour and 7-day windows.",
+				InputSchema: objectSchema(nil),
+				Handler: func(ctx context.Context, args json.RawMessage) (any, error) {
+					return mcpProviderUsage(ctx, args, quota.ProviderClaude)
+				},
+			},
+			{
+				Name:        "get_codex_usage",
+				Description: "Current Codex usage: utilization and reset time of its rate limit windows.",
+				InputSchema: objectSchema(nil),
+				Handler: func(ctx context.Context, args json.RawMessage) (any, error) {
+					return mcpProviderUsage(ctx, args, quota.ProviderCodex)
+				},
+			},
+			{
+				Name:        "project_exhaustion",
+				Description: "Projects when each usage window reaches 100% at the current burn rate and whether that happens before it resets.",
+				InputSchema: objectSchema(map[string]any{
+					"window": map[string]any{"type": "string", "description": "Window to project, e.g. 5h, 7d or codex-5h. Omit for all windows."},
+				}),
+				Handler: mcpProjectExhaustion,
+			},
+			{
+				Name:        "get_history",
+				Description: "Recorded utilization samples over time. History is recorded while the ccstats daemon runs.",
+				InputSchema: objectSchema(map[string]any{
+					"since":  map[string]any{"type": "string", "description": "Start of the range: an age such as 24h or 7d, a date, or an RFC 3339 timestamp. Defaults to 24h."},
+					"window": map[string]any{"type": "string", "description": "Only return samples of this window, e.g. 5h."},
+				}),
+				Handler: mcpHistory,
+			},
+		},
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return server.Serve(ctx, os.Stdin, os.Stdout)
+}
+
+func mcpProviderUsage(ctx context.Context, args json.RawMessage, provider string) (any, error) {
+	if err := mcp.DecodeArgs(args, &struct{}{}); err != nil {
+		return nil, err
+	}
+
+	snap, errs := fetchSnapshot(ctx)
+	now := time.Now()
+	result := mcpUsage{Provider: provider, Windows: []mcpWindow{}}
+
+	var fetchErr error
+	switch provider {
+	case quota.ProviderClaude:
+		if snap == nil || snap.Claude == nil {
+			return nil, providerError("Claude", errs.Claude)
+		}
+		result.Plan = snap.Claude.Plan.String()
+		fetchErr = errs.Claude
+	case quota.ProviderCodex:
+		if snap == nil || snap.Codex == nil {
+			return nil, providerError("Codex", errs.Codex)
+		}
+		result.Plan = snap.Codex.Plan.String()
+		fetchErr = errs.Codex
+	}
+	// Cached data is still returned, with the reason it is not fresh.
+	if fetchErr != nil {
+		result.Error = fetchErr.Error()
+	}
+
+	for _, w := range quota.Windows(snap.Claude, snap.Codex) {
+		if w.Provider != provider {
+			continue
+		}
+		result.Windows = append(result.Windows, mcpWindow{
+			Window:             w.Key,
+			Label:              w.Label,
+			UtilizationPercent: w.Metric.Utilization * 100,
+			ResetAt:            w.Metric.ResetAt,
+			ResetsIn:           strings.TrimPrefix(display.FormatRelativeTimeFrom(w.Metric.ResetAt, now), "resets in "),
+		})
+	}
+	return result, nil
+}
+
+func mcpProjectExhaustion(ctx context.Context, args json.RawMessage) (any, error) {
+	var params struct {
+		Window string `json:"window"`
+	}
+	if err := mcp.DecodeArgs(args, &params); err != nil {
+		return nil, err
+	}
+
+	snap, _ := fetchSnapshot(ctx)
+	if snap == nil {
+		return nil, errors.New("no usage available")
+	}
+	windows := quota.Windows(snap.Claude, snap.Codex)
+	if params.Window != "" {
+		windows = quota.FilterWindows(windows, []string{params.Window})
+		if len(windows) == 0 {
+			return nil, fmt.Errorf("unknown window: %s", params.Window)
+		}
+	}
+
+	now := time.Now()
+	samples, err := readHistory(ctx, now.Add(-time.Hour))
+	if err != nil {
+		return nil, err
+	}
+
+	projections := make([]forecast.Projection, len(windows))
+	for i, w := range windows {
+		projections[i] = forecast.Project(w, samples, now)
+	}
+	return map[string]any{"projections": projections}, nil
+}
+
+func mcpHistory(ctx context.Context, args json.RawMessage) (any, error) {
+	params := struct {
+		Since  string `json:"since"`
+		Window string `json:"window"`
+	}{Since: "24h"}
+	if err := mcp.DecodeArgs(args, &params); err != nil {
+		return nil, err
+	}
+
+	since, err := parseTimeBound(params.Since, time.Now(), false)
+	if err != nil {
+		return nil, err
+	}
+	samples, err := readHistory(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := []history.Sample{}
+	for _, sample := range samples {
+		if params.Window == "" || sample.Window == params.Window {
+			filtered = append(filtered, sample)
+		}
+	}
+	return map[string]any{"since": since, "samples": filtered}, nil
+}
+
+func providerError(name string, err error) error {
+	if err == nil {
+		return fmt.Errorf("no %s usage available", name)
+	}
+	return err
+}
+
+// objectSchema returns the JSON Schema of an object with the given optional
+// properties.
+func objectSchema(properties map[string]any) map[string]any {
+	if properties == nil {
+		properties = map[string]any{}
+	}
+	return map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+}
+
+// buildVersion returns the module version ccstats was built from.
+func buildVersion() string {
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		return info.Main.Version
+	}
+	return "devel"
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/uesteibar/ccstats/internal/config"
+	"github.com/uesteibar/ccstats/internal/notify"
+	"github.com/uesteibar/ccstats/internal/quota"
+	"github.com/uesteibar/ccstats/internal/snapshot"
+)
+
+// notifyStatePath is where the notifier remembers which alerts it sent.
+func notifyStatePath() string {
+	return filepath.Join(cacheDir(), "notify-state.json")
+}
+
+// notifySinkOptions maps the notify config to sink settings.
+func notifySinkOptions(w io.Writer, cfg config.NotifyConfig) notify.SinkOptions {
+	deadLetter := cfg.Webhook.DeadLetterFile
+	if deadLetter == "" {
+		deadLetter = filepath.Join(cacheDir(), "notify-dead-letter.jsonl")
+	}
+
+	return notify.SinkOptions{
+		Command: cfg.Command,
+		Webhook: notify.WebhookSink{
+			URL:            cfg.Webhook.URL,
+			Format:         cfg.Webhook.Format,
+			Secret:         cfg.Webhook.Secret,
+			Retries:        cfg.Webhook.Retries,
+			DeadLetterPath: deadLetter,
+		},
+		Stdout: w,
+	}
+}
+
+// newNotifier builds the notifier described by cfg. sinkNames overrides
+// the configured sinks when not empty.
+func newNotifier(w io.Writer, cfg config.NotifyConfig, sinkNames []string) (*notify.Notifier, error) {
+	names := cfg.Sinks
+	if len(sinkNames) > 0 {
+		names = sinkNames
+	}
+	sinks, err := notify.NewSinks(names, notifySinkOptions(w, cfg))
+	if err != nil {
+		return nil, err
+	}
+
+	return &notify.Notifier{
+		Sinks:      sinks,
+		Thresholds: cfg.Thresholds,
+		Resets:     cfg.Resets,
+		StatePath:  notifyStatePath(),
+	}, nil
+}
+
+// runNotify checks usage against the configured thresholds and delivers
+// alerts, once or every interval.
+func runNotify(w io.Writer, cfg config.Config, args []string) error {
+	fs := flag.NewFlagSet("notify", flag.ContinueOnError)
+	watch := fs.Bool("watch", false, "keep checking every interval until interrupted")
+	interval := fs.Duration("interval", cfg.Notify.Interval.Duration, "how often to check with --watch")
+	sinkNames := fs.String("sinks", "", "comma-separated sinks overriding the config: desktop, command, webhook, stdout")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	notifier, err := newNotifier(w, cfg.Notify, splitList(*sinkNames))
+	if err != nil {
+		return err
+	}
+
+	if !*watch {
+		return checkNotify(notifier, cfg.Notify.Windows)
+	}
+	if *interval <= 0 {
+		return fmt.Errorf("--interval must be positive with --watch")
+	}
+	return everyInterval(*interval, func() error {
+		// A failed check is reported but does not stop watching; the next
+		// tick may succeed.
+		if err := checkNotify(notifier, cfg.Notify.Windows); err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+		}
+		return nil
+	})
+}
+
+// checkNotify fetches fresh usage and runs one notifier check.
+func checkNotify(notifier *notify.Notifier, keys []string) error {
+	ctx := context.Background()
+	snap, errs := fetchSnapshot(ctx)
+	if snap.Claude == nil && snap.Codex == nil {
+		if errs.Claude != nil {
+			return errs.Claude
+		}
+		return errs.Codex
+	}
+
+	return notifySnapshot(ctx, notifier, snap, keys)
+}
+
+// notifySnapshot runs one notifier check against snap.
+func notifySnapshot(ctx context.Context, notifier *notify.Notifier, snap *snapshot.Snapshot, keys []string) error {
+	windows := quota.FilterWindows(quota.Windows(snap.Claude, snap.Codex), keys)
+	_, err := notifier.Check(ctx, notify.WindowsFrom(windows), time.Now())
+	return err
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"flag"
+
+	"github.com/uesteibar/ccstats/internal/config"
+	"github.com/uesteibar/ccstats/internal/display"
+)
+
+// displayFlags are the rendering flags shared by the progress-bar views.
+type displayFlags struct {
+	themes     map[string]config.ThemeConfig
+	color      *string
+	theme      *string
+	gradient   *bool
+	resetTime  *string
+	timezone   *string
+	clock      *string
+	width      *int
+	barStyle   *string
+	ascii      *bool
+	accessible *bool
+}
+
+// addDisplayFlags registers the rendering flags on fs with defaults from cfg.
+func addDisplayFlags(fs *flag.FlagSet, cfg config.DisplayConfig) *displayFlags {
+	return &displayFlags{
+		themes:     cfg.Themes,
+		theme:      fs.String("theme", cfg.Theme, "color theme: default, colorblind, monochrome, high-contrast or a theme from the config"),
+		gradient:   fs.Bool("gradient", cfg.Gradient, "color bars along a gradient (needs a 256-color or truecolor terminal)"),
+		color:      fs.String("color", cfg.Color, "use colors: auto, always or never (auto honors NO_COLOR, CLICOLOR_FORCE and TERM=dumb)"),
+		resetTime:  fs.String("reset-time", cfg.ResetTime, "how to show reset times: relative, absolute or both"),
+		timezone:   fs.String("tz", cfg.Timezone, "timezone for absolute reset times, e.g. Europe/Berlin (default $TZ or local)"),
+		clock:      fs.String("clock", cfg.Clock, "clock for absolute reset times: 12h or 24h (default from locale)"),
+		width:      fs.Int("width", 0, "layout width in columns (default: terminal width, or a fixed layout when piped)"),
+		barStyle:   fs.String("bar-style", cfg.BarStyle, "progress bar characters: blocks, ascii or fractional"),
+		ascii:      fs.Bool("ascii", false, "use only ASCII characters (same as --bar-style ascii)"),
+		accessible: fs.Bool("accessible", cfg.Accessible, "print plain sentences without bars, colors or decorative characters"),
+	}
+}
+
+// options resolves the parsed flags into display options. Colors are
+// resolved once here so every section renders consistently.
+func (f *displayFlags) options() (display.Options, error) {
+	opts := display.DefaultOptions()
+
+	mode, err := display.ParseColorMode(*f.color)
+	if err != nil {
+		return opts, err
+	}
+	opts.Color = display.ResolveColorForStdout(mode)
+
+	theme, err := f.resolveTheme()
+	if err != nil {
+		return opts, err
+	}
+	opts.Color.Theme = &theme
+
+	timeCfg, err := display.ParseTimeConfig(*f.resetTime, *f.timezone, *f.clock)
+	if err != nil {
+		return opts, err
+	}
+	opts.Time = timeCfg
+
+	opts.Width = *f.width
+	if opts.Width <= 0 {
+		opts.Width = display.StdoutWidth()
+	}
+
+	opts.Bar, err = display.ParseBarStyle(*f.barStyle)
+	if err != nil {
+		return opts, err
+	}
+	if *f.ascii {
+		opts.Bar = display.BarASCII
+	}
+
+	opts.Accessible = *f.accessible
+	if opts.Accessible {
+		opts.Color.Enabled = false
+	}
+
+	return opts, nil
+}
+
+// resolveTheme looks up the selected theme among the user-defined and
+// built-in themes.
+func (f *displayFlags) resolveTheme() (display.Theme, error) {
+	custom := make(map[string]display.Theme, len(f.themes))
+	for name, tc := range f.themes {
+		theme, err := display.NewTheme(name, tc.Low, tc.Medium, tc.High, tc.Gradient)
+		if err != nil {
+			return display.Theme{}, err
+		}
+		custom[name] = theme
+	}
+
+	theme, err := display.LookupTheme(*f.theme, custom)
+	if err != nil {
+		return display.Theme{}, err
+	}
+	if *f.gradient {
+		theme.Gradient = true
+	}
+	return theme, nil
+}
+
+// timeFlags are the flags of the table views, which only need the timezone
+// and clock to bucket days and read dates.
+type timeFlags struct {
+	barStyle string
+	timezone *string
+	clock    *string
+}
+
+// addTimeFlags registers the timezone and clock flags on fs with defaults
+// from cfg.
+func addTimeFlags(fs *flag.FlagSet, cfg config.DisplayConfig) *timeFlags {
+	return &timeFlags{
+		barStyle: cfg.BarStyle,
+		timezone: fs.String("tz", cfg.Timezone, "timezone for days and dates, e.g. Europe/Berlin (default $TZ or local)"),
+		clock:    fs.String("clock", cfg.Clock, "clock for times: 12h or 24h (default from locale)"),
+	}
+}
+
+// options resolves the parsed flags into display options for a table drawn
+// in the configured bar style.
+func (f *timeFlags) options() (display.Options, error) {
+	opts := display.DefaultOptions()
+
+	timeCfg, err := display.ParseTimeConfig(string(display.TimeAbsolute), *f.timezone, *f.clock)
+	if err != nil {
+		return opts, err
+	}
+	opts.Time = timeCfg
+
+	opts.Bar, err = display.ParseBarStyle(f.barStyle)
+	return opts, err
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/uesteibar/ccstats/internal/api"
+	"github.com/uesteibar/ccstats/internal/codex"
+	"github.com/uesteibar/ccstats/internal/config"
+	"github.com/uesteibar/ccstats/internal/daemon"
+	"github.com/uesteibar/ccstats/internal/display"
+	"github.com/uesteibar/ccstats/internal/keychain"
+	"github.com/uesteibar/ccstats/internal/snapshot"
+	"github.com/uesteibar/ccstats/internal/transcript"
+)
+
+// profileEnv selects a profile when --profile is not given.
+const profileEnv = "CCSTATS_PROFILE"
+
+// profile is a named set of logins. The zero value is the default logins,
+// which keep the cache directory to themselves.
+type profile struct {
+	Name string
+	config.Profile
+}
+
+// active is the profile selected for this run.
+var active profile
+
+// cacheDir returns the active profile's cache directory.
+func cacheDir() string {
+	return active.cacheDir()
+}
+
+// cacheDir returns where the profile's usage cache, history and daemon
+// files live: the ccstats cache directory, or profiles/<name> in it.
+func (p profile) cacheDir() string {
+	dir := snapshot.CacheDir()
+	if p.Name == "" || dir == "" {
+		return dir
+	}
+	return filepath.Join(dir, "profiles", p.Name)
+}
+
+func (p profile) claudeSource() keychain.Source {
+	return keychain.Source{Service: p.KeychainService, ConfigDir: p.ConfigDir}
+}
+
+func (p profile) codexSource() codex.Source {
+	return codex.Source{Home: p.CodexHome}
+}
+
+// transcriptDir returns where the profile's Claude Code transcripts are.
+func (p profile) transcriptDir() string {
+	if p.ConfigDir != "" {
+		return filepath.Join(p.ConfigDir, "projects")
+	}
+	return transcript.Dir()
+}
+
+// fetcher returns a fetcher for both providers of the profile backed by
+// its usage cache.
+func (p profile) fetcher() *snapshot.Fetcher {
+	var cachePath string
+	if dir := p.cacheDir(); dir != "" {
+		cachePath = filepath.Join(dir, "usage.json")
+	}
+
+	source := p.codexSource()
+	return &snapshot.Fetcher{
+		Claude: claudeFetcher(api.NewClient(), p.claudeSource()),
+		Codex: func(context.Context) (*codex.Usage, error) {
+			return source.FetchUsage()
+		},
+		CachePath: cachePath,
+	}
+}
+
+// fetchSnapshot returns the profile's usage from its daemon when one is
+// running and fetches it directly otherwise.
+func (p profile) fetchSnapshot(ctx context.Context) (*snapshot.Snapshot, snapshot.Errors) {
+	if snap, errs, err := daemon.Usage(ctx, daemon.SocketPath(p.cacheDir(), p.Name)); err == nil {
+		return snap, errs
+	}
+	return p.fetcher().Fetch(ctx)
+}
+
+// globalFlags removes the profile flags leading args: --profile NAME and
+// --all-profiles.
+func globalFlags(args []string) (rest []string, profileName string, allProfiles bool, err error) {
+	for len(args) > 0 {
+		arg := args[0]
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !strings.HasPrefix(arg, "-") {
+			break
+		}
+
+		switch name {
+		case "profile":
+			if !hasValue {
+				if len(args) < 2 {
+					return nil, "", false, errors.New("--profile needs a profile name")
+				}
+				value, args = args[1], args[1:]
+			}
+			profileName = value
+		case "all-profiles":
+			allProfiles = true
+		default:
+			return args, profileName, allProfiles, nil
+		}
+		args = args[1:]
+	}
+	return args, profileName, allProfiles, nil
+}
+
+// selectProfile makes the profile named by --profile, $CCSTATS_PROFILE or
+// the config active.
+func selectProfile(cfg config.Config, name string) error {
+	if name == "" {
+		name = os.Getenv(profileEnv)
+	}
+	if name == "" {
+		name = cfg.Profile
+	}
+	if name == "" {
+		return nil
+	}
+
+	p, err := cfg.LookupProfile(name)
+	if err != nil {
+		return err
+	}
+	active = profile{Name: name, Profile: p}
+	return nil
+}
+
+// profileUsage is the usage fetched for one profile.
+type profileUsage struct {
+	name string
+	snap *snapshot.Snapshot
+	errs snapshot.Errors
+}
+
+// runAllProfiles fetches every configured profile concurrently and shows
+// a section per profile.
+func runAllProfiles(w io.Writer, cfg config.Config, args []string) error {
+	fs := flag.NewFlagSet("ccstats --all-profiles", flag.ContinueOnError)
+	displayOpts := addDisplayFlags(fs, cfg.Display)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	opts, err := displayOpts.options()
+	if err != nil {
+		return err
+	}
+
+	names := cfg.ProfileNames()
+	if len(names) == 0 {
+		return errors.New("no profiles are configured")
+	}
+
+	results := make([]profileUsage, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p := profile{Name: name, Profile: cfg.Profiles[name]}
+			snap, errs := p.fetchSnapshot(context.Background())
+			results[i] = profileUsage{name: name, snap: snap, errs: errs}
+		}()
+	}
+	wg.Wait()
+
+	warnPlanLimits(cfg)
+
+	now := time.Now()
+	showedCodex := false
+	for _, result := range results {
+		display.DisplayProfileHeading(w, result.name, opts)
+
+		claudeUsage, codexUsage := result.snap.Claude, result.snap.Codex
+		if result.errs.Claude != nil {
+			claudeUsage = nil
+		}
+		if result.errs.Codex != nil {
+			codexUsage = nil
+		}
+		display.DisplayAll(w, claudeUsage, codexUsage, now, opts)
+		showedCodex = showedCodex || codexUsage != nil
+
+		if result.errs.Claude != nil {
+			fmt.Fprintf(w, "Claude: %v\n", result.errs.Claude)
+		}
+		if result.errs.Codex != nil && !errors.Is(result.errs.Codex, codex.ErrAuthNotFound) {
+			fmt.Fprintf(w, "Codex: %v\n", result.errs.Codex)
+		}
+	}
+	if showedCodex {
+		warnStalePlanLimits(cfg)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/uesteibar/ccstats/internal/config"
+	"github.com/uesteibar/ccstats/internal/daemon"
+	"github.com/uesteibar/ccstats/internal/display"
+	"github.com/uesteibar/ccstats/internal/quota"
+	"github.com/uesteibar/ccstats/internal/snapshot"
+)
+
+// runPrompt prints a terse one-line summary for shell prompts and tmux status lines.
+func runPrompt(w io.Writer, cfg config.Config, args []string) error {
+	fs := flag.NewFlagSet("prompt", flag.ContinueOnError)
+	styleName := fs.String("style", cfg.Prompt.Style, "escape syntax: plain, ansi, tmux or zsh")
+	windows := fs.String("windows", strings.Join(cfg.Prompt.Windows, ","), "comma-separated window keys, e.g. 5h,7d,codex-5h")
+	budget := fs.Duration("budget", cfg.Prompt.Budget.Duration, "longest time to wait for fresh data before using the cache")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	style, err := display.ParseLineStyle(*styleName)
+	if err != nil {
+		return err
+	}
+
+	return renderLine(w, cfg.Prompt, style, splitList(*windows), *budget)
+}
+
+// renderLine prints the selected windows on a single line.
+func renderLine(w io.Writer, promptCfg config.PromptConfig, style display.LineStyle, keys []string, budget time.Duration) error {
+	snap, wait := quickSnapshot(promptCfg, budget)
+	defer wait()
+
+	windows := quota.FilterWindows(quota.Windows(snap.Claude, snap.Codex), keys)
+	fmt.Fprintln(w, display.FormatLine(windows, style))
+	return nil
+}
+
+// quickSnapshot reads usage from the daemon, or fetches it within budget
+// falling back to cached data. The returned func waits for a fetch that
+// missed the budget to update the cache; call it once the output is written.
+func quickSnapshot(promptCfg config.PromptConfig, budget time.Duration) (*snapshot.Snapshot, func()) {
+	ctx, cancel := context.WithTimeout(context.Background(), budget)
+	defer cancel()
+
+	if snap, _, err := daemon.Usage(ctx, daemonSocketPath()); err == nil {
+		return snap, func() {}
+	}
+
+	fetcher := newFetcher()
+	fetcher.MaxAge = promptCfg.CacheTTL.Duration
+	snap, _ := fetcher.Fetch(ctx)
+	return snap, fetcher.Wait
+}
+
+// splitList splits a comma-separated flag value, dropping empty entries.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/uesteibar/ccstats/internal/config"
+	"github.com/uesteibar/ccstats/internal/daemon"
+	"github.com/uesteibar/ccstats/internal/history"
+	"github.com/uesteibar/ccstats/internal/httpapi"
+	"github.com/uesteibar/ccstats/internal/snapshot"
+)
+
+// serveTokenEnv overrides the configured bearer token.
+const serveTokenEnv = "CCSTATS_SERVE_TOKEN"
+
+// runServe serves usage and history over HTTP until interrupted.
+func runServe(cfg config.Config, args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	addr := fs.String("http", cfg.Serve.Addr, "address to listen on")
+	cacheTTL := fs.Duration("cache-ttl", cfg.Serve.CacheTTL.Duration, "how long fetched usage is served before refetching")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	token := cfg.Serve.Token
+	if env := os.Getenv(serveTokenEnv); env != "" {
+		token = env
+	}
+
+	fetcher := newFetcher()
+	fetcher.MaxAge = *cacheTTL
+
+	apiServer := &httpapi.Server{
+		Usage: func(ctx context.Context) (*snapshot.Snapshot, snapshot.Errors) {
+			if snap, errs, err := daemon.Usage(ctx, daemonSocketPath()); err == nil {
+				return snap, errs
+			}
+			return fetcher.Fetch(ctx)
+		},
+		History: readHistory,
+		ParseSince: func(s string, now time.Time) (time.Time, error) {
+			return parseTimeBound(s, now, false)
+		},
+		Token:       token,
+		CORSOrigins: cfg.Serve.CORSOrigins,
+	}
+
+	listener, err := net.Listen("tcp", *addr)
+	if err != nil {
+		return err
+	}
+	if token == "" && !isLoopback(listener.Addr()) {
+		fmt.Fprintf(os.Stderr, "Warning: serving on %s without a token; anyone who can reach it can read your usage\n", listener.Addr())
+	}
+
+	server := &http.Server{
+		Handler:           apiServer.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	fmt.Fprintf(os.Stderr, "ccstats API listening on http://%s\n", listener.Addr())
+	if err := server.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// readHistory returns history from the running daemon, or from the history
+// file when no daemon runs.
+func readHistory(ctx context.Context, since time.Time) ([]history.Sample, error) {
+	samples, err := daemon.History(ctx, daemonSocketPath(), since)
+	if errors.Is(err, daemon.ErrNotRunning) {
+		return historyStore().Read(since)
+	}
+	return samples, err
+}
+
+func isLoopback(addr net.Addr) bool {
+	tcp, ok := addr.(*net.TCPAddr)
+	return ok && tcp.IP.IsLoopback()
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/uesteibar/ccstats/internal/config"
+	"github.com/uesteibar/ccstats/internal/display"
+	"github.com/uesteibar/ccstats/internal/quota"
+)
+
+// defaultI3barInterval is used when i3bar output is requested without an
+// interval, since the protocol expects a continuous stream.
+const defaultI3barInterval = time.Minute
+
+// runWaybar prints Waybar custom-module JSON, once or every interval.
+func runWaybar(w io.Writer, promptCfg config.PromptConfig, interval time.Duration) error {
+	enc := json.NewEncoder(w)
+	return everyInterval(interval, func() error {
+		snap, wait := quickSnapshot(promptCfg, promptCfg.Budget.Duration)
+		defer wait()
+
+		windows := quota.FilterWindows(quota.Windows(snap.Claude, snap.Codex), promptCfg.Windows)
+		return enc.Encode(display.FormatWaybar(windows, snap.Claude, snap.Codex, time.Now()))
+	})
+}
+
+// runI3bar streams the i3bar protocol until interrupted.
+func runI3bar(w io.Writer, promptCfg config.PromptConfig, interval time.Duration) error {
+	if interval <= 0 {
+		interval = defaultI3barInterval
+	}
+
+	writer := display.NewI3barWriter(w)
+	return everyInterval(interval, func() error {
+		snap, wait := quickSnapshot(promptCfg, promptCfg.Budget.Duration)
+		defer wait()
+
+		windows := quota.FilterWindows(quota.Windows(snap.Claude, snap.Codex), promptCfg.Windows)
+		return writer.Write(display.I3barBlocks(windows))
+	})
+}
+
+// runXbar prints the SwiftBar/xbar plugin output.
+func runXbar(w io.Writer, promptCfg config.PromptConfig) error {
+	snap, wait := quickSnapshot(promptCfg, promptCfg.Budget.Duration)
+	defer wait()
+
+	_, err := io.WriteString(w, display.FormatXbar(snap.Claude, snap.Codex, time.Now()))
+	return err
+}
+
+// everyInterval calls fn once, then again every interval until SIGINT or
+// SIGTERM. A zero interval calls fn only once.
+func everyInterval(interval time.Duration, fn func() error) error {
+	if err := fn(); err != nil || interval <= 0 {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := fn(); err != nil {
+				return err
+			}
+		}
+	}
+}
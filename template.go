@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/uesteibar/ccstats/internal/codex"
+	"github.com/uesteibar/ccstats/internal/display"
+)
+
+// runTemplate renders usage with a user-supplied Go text/template given
+// inline or read from a file.
+func runTemplate(w io.Writer, text string, path string, opts display.Options) error {
+	if text != "" && path != "" {
+		return errors.New("use either --template or --template-file, not both")
+	}
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read template: %w", err)
+		}
+		text = string(data)
+	}
+
+	now := time.Now()
+	tmpl, err := display.NewTemplate(text, now, opts.Color, opts.Time)
+	if err != nil {
+		return err
+	}
+
+	snap, errs := fetchSnapshot(context.Background())
+	if errs.Claude != nil {
+		return errs.Claude
+	}
+	if errs.Codex != nil && errs.Codex != codex.ErrAuthNotFound {
+		return errs.Codex
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, display.NewTemplateData(snap.Claude, snap.Codex, now)); err != nil {
+		return fmt.Errorf("failed to render template: %w", err)
+	}
+	if !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+		buf.WriteByte('\n')
+	}
+
+	_, err = w.Write(buf.Bytes())
+	return err
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"io"
+	"time"
+
+	"github.com/uesteibar/ccstats/internal/config"
+	"github.com/uesteibar/ccstats/internal/display"
+	"github.com/uesteibar/ccstats/internal/transcript"
+)
+
+// runTokens aggregates token usage from local Claude Code transcripts.
+func runTokens(w io.Writer, cfg config.Config, args []string) error {
+	fs := flag.NewFlagSet("tokens", flag.ContinueOnError)
+	by := fs.String("by", "day", "group by day, project, session or model")
+	since := fs.String("since", "", "only count messages from this date, timestamp or age (e.g. 2026-01-01, 7d)")
+	until := fs.String("until", "", "only count messages up to this date, timestamp or age")
+	asJSON := fs.Bool("json", false, "print the rows as JSON")
+	dir := fs.String("dir", active.transcriptDir(), "directory holding Claude Code transcripts")
+	timeOpts := addTimeFlags(fs, cfg.Display)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	opts, err := timeOpts.options()
+	if err != nil {
+		return err
+	}
+
+	groupBy, err := transcript.ParseGroupBy(*by)
+	if err != nil {
+		return err
+	}
+
+	filter, err := parseFilter(*since, *until, time.Now().In(opts.Time.Zone()))
+	if err != nil {
+		return err
+	}
+
+	agg := transcript.NewAggregator(groupBy, opts.Time.Zone())
+	err = transcript.Scan(*dir, filter, func(e transcript.Entry) error {
+		agg.Add(e)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	rows := agg.Rows()
+	if *asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(rows)
+	}
+
+	display.DisplayTokens(w, rows, groupBy, opts)
+	return nil
+}
+
+// parseFilter builds a transcript filter from --since and --until values.
+func parseFilter(since, until string, now time.Time) (transcript.Filter, error) {
+	start, err := parseTimeBound(since, now, false)
+	if err != nil {
+		return transcript.Filter{}, err
+	}
+	end, err := parseTimeBound(until, now, true)
+	if err != nil {
+		return transcript.Filter{}, err
+	}
+	return transcript.Filter{Since: start, Until: end}, nil
+}
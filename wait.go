@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/term"
+
+	"github.com/uesteibar/ccstats/internal/config"
+	"github.com/uesteibar/ccstats/internal/quota"
+	"github.com/uesteibar/ccstats/internal/snapshot"
+	"github.com/uesteibar/ccstats/internal/wait"
+)
+
+// runWait blocks until a window is below a threshold or has reset.
+func runWait(w io.Writer, cfg config.Config, args []string) error {
+	fs := flag.NewFlagSet("wait", flag.ContinueOnError)
+	window := fs.String("window", "5h", "window to wait for, e.g. 5h, 7d or codex-5h")
+	below := fs.Float64("below", 0, "return once utilization is below this percentage (default: wait for the window to reset)")
+	interval := fs.Duration("interval", 5*time.Minute, "longest time between checks")
+	timeout := fs.Duration("timeout", 0, "give up after this long (default: no limit)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *below < 0 || *below > 100 {
+		return fmt.Errorf("--below must be between 0 and 100")
+	}
+	if *interval <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	waiter := &wait.Waiter{
+		Read: func(ctx context.Context) (quota.Window, error) {
+			return readWindow(ctx, *window)
+		},
+		Below:    *below / 100,
+		Interval: *interval,
+	}
+	result, err := waitForWindow(ctx, waiter, *window, *timeout)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "%s window at %.0f%%\n", result.Label, result.Metric.Utilization*100)
+	return nil
+}
+
+// waitForWindow runs waiter, reporting progress on stderr so stdout stays
+// clean for scripts: a live countdown on terminals, one line per check
+// otherwise.
+func waitForWindow(ctx context.Context, waiter *wait.Waiter, key string, timeout time.Duration) (quota.Window, error) {
+	below := waiter.Below * 100
+	countdown := term.IsTerminal(int(os.Stderr.Fd()))
+	if countdown {
+		waiter.OnCheck = func(s wait.Status) { printCountdown(s, below) }
+		waiter.OnTick = func(s wait.Status) { printCountdown(s, below) }
+	} else {
+		waiter.OnCheck = func(s wait.Status) {
+			fmt.Fprintln(os.Stderr, waitStatusLine(s, below))
+		}
+	}
+
+	result, err := waiter.Wait(ctx)
+	if countdown {
+		fmt.Fprint(os.Stderr, "\r\033[K")
+	}
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return result, fmt.Errorf("timed out after %s waiting for the %s window", timeout, key)
+	case errors.Is(err, context.Canceled):
+		return result, fmt.Errorf("interrupted waiting for the %s window", key)
+	}
+	return result, err
+}
+
+// readWindow fetches usage and returns the window with the given key.
+// Cached data is returned when a fetch fails.
+func readWindow(ctx context.Context, key string) (quota.Window, error) {
+	snap, errs := fetchSnapshot(ctx)
+	return windowFrom(snap, errs, key)
+}
+
+// windowFrom returns the window with the given key from snap.
+func windowFrom(snap *snapshot.Snapshot, errs snapshot.Errors, key string) (quota.Window, error) {
+	windows := quota.FilterWindows(quota.Windows(snap.Claude, snap.Codex), []string{key})
+	if len(windows) > 0 {
+		return windows[0], nil
+	}
+
+	// Blame the provider only when it has no data to look the key up in.
+	var err error
+	if windowProvider(key) == quota.ProviderCodex {
+		if snap.Codex == nil {
+			err = errs.Codex
+		}
+	} else if snap.Claude == nil {
+		err = errs.Claude
+	}
+	if err == nil {
+		err = fmt.Errorf("unknown window: %s", key)
+	}
+	return quota.Window{}, err
+}
+
+// waitStatusLine describes a wait in progress.
+func waitStatusLine(s wait.Status, below float64) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s window at %.0f%%", s.Window.Label, s.Window.Metric.Utilization*100)
+	if below > 0 {
+		fmt.Fprintf(&b, ", waiting for below %.0f%%", below)
+	}
+	if resetAt := s.Window.Metric.ResetAt; !resetAt.IsZero() {
+		if d := resetAt.Sub(s.Now); d > 0 {
+			fmt.Fprintf(&b, ", resets in %s", formatAge(d))
+		} else {
+			b.WriteString(", reset due")
+		}
+	}
+	fmt.Fprintf(&b, ", next check in %s", formatAge(s.Next.Sub(s.Now)))
+	if s.Err != nil {
+		fmt.Fprintf(&b, " (last check failed: %v)", s.Err)
+	}
+	return b.String()
+}
+
+func printCountdown(s wait.Status, below float64) {
+	fmt.Fprintf(os.Stderr, "\r\033[K%s", waitStatusLine(s, below))
+}